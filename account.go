@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 )
 
+// AccountTypeOrganization is the Type of organization accounts.
+const AccountTypeOrganization = "organization"
+
 type Account struct {
 	Type      string `json:"type"`
 	Username  string `json:"username"`
@@ -20,6 +24,12 @@ func (a *Account) RawJSON() json.RawMessage {
 	return a.rawJSON
 }
 
+// IsOrganization reports whether the account is an organization rather than
+// a user.
+func (a *Account) IsOrganization() bool {
+	return a.Type == AccountTypeOrganization
+}
+
 var _ json.Unmarshaler = (*Account)(nil)
 
 func (a *Account) UnmarshalJSON(data []byte) error {
@@ -34,7 +44,63 @@ func (r *Client) GetCurrentAccount(ctx context.Context) (*Account, error) {
 	response := &Account{}
 	err := r.fetch(ctx, http.MethodGet, "/account", nil, response)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list collections: %w", err)
+		return nil, fmt.Errorf("failed to get current account: %w", err)
 	}
 	return response, nil
 }
+
+// ownerCache holds the account each recently used token belongs to.
+type ownerCache struct {
+	mu      sync.Mutex
+	byToken map[string]*Account
+}
+
+// CurrentOwner returns the account the client's token belongs to, which
+// owns the models and deployments the client creates. For an organization's
+// token, that's the organization rather than the user who made the token.
+// The account is fetched once per token and cached.
+//
+// CreateModel uses its username when it's called with an empty owner, and
+// CreateDeployment when options.Model has no owner. Other calls don't
+// default the owner, so an empty owner can't act on the wrong account.
+func (r *Client) CurrentOwner(ctx context.Context) (*Account, error) {
+	token, err := r.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &r.state.owners
+	c.mu.Lock()
+	account, ok := c.byToken[token]
+	c.mu.Unlock()
+	if ok {
+		return account, nil
+	}
+
+	account, err = r.GetCurrentAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Tokens are only replaced by rotation, so the old ones can go.
+	if c.byToken == nil || len(c.byToken) >= maxRecentTokens {
+		c.byToken = make(map[string]*Account)
+	}
+	c.byToken[token] = account
+	return account, nil
+}
+
+// resolveOwner returns owner, or the username of the current owner if owner
+// is empty.
+func (r *Client) resolveOwner(ctx context.Context, owner string) (string, error) {
+	if owner != "" {
+		return owner, nil
+	}
+	account, err := r.CurrentOwner(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve owner: %w", err)
+	}
+	return account.Username, nil
+}
@@ -0,0 +1,160 @@
+package replicate
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type auditActorContextKey struct{}
+
+// AuditRecord describes a call that creates, changes, or deletes a resource.
+type AuditRecord struct {
+	// Time is when the call started.
+	Time time.Time
+
+	// Actor identifies who made the call. It's the value set with
+	// WithAuditActor, or else a fingerprint of the API token.
+	Actor string
+
+	// Operation is the name of the client method, such as "CancelPrediction".
+	Operation string
+
+	// Method and URL identify the request. Query parameters are included.
+	Method string
+	URL    string
+
+	// Input is the JSON request body, with the API token and data URL
+	// payloads redacted. It's nil for requests without a JSON body, like
+	// file uploads.
+	Input json.RawMessage
+
+	// StatusCode is the status code of the last response, or 0 if none was
+	// received.
+	StatusCode int
+
+	// RequestID is the ID the API assigned to the last request, if any.
+	RequestID string
+
+	// Attempts is the number of requests sent, including retries.
+	Attempts int
+
+	// Duration is the total time spent on the call.
+	Duration time.Duration
+
+	// Err is the error returned by the call, if any.
+	Err error
+}
+
+// AuditSink receives a record of every call that creates, changes, or
+// deletes a resource, such as creating a prediction or deleting a model.
+// Records are delivered synchronously, after the call finishes, so
+// implementations must be safe for concurrent use and should return quickly.
+type AuditSink interface {
+	Audit(ctx context.Context, record AuditRecord)
+}
+
+// AuditSinkFunc adapts a function to an AuditSink.
+type AuditSinkFunc func(ctx context.Context, record AuditRecord)
+
+// Audit calls f(ctx, record).
+func (f AuditSinkFunc) Audit(ctx context.Context, record AuditRecord) {
+	f(ctx, record)
+}
+
+// WithAuditSink sets the sink that receives audit records for the client's
+// mutating calls.
+func WithAuditSink(sink AuditSink) ClientOption {
+	return func(o *clientOptions) error {
+		o.auditSink = sink
+		return nil
+	}
+}
+
+// WithAuditActor returns a context that attributes the calls made with it to
+// actor in audit records, such as the end user or service a call is made on
+// behalf of.
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorContextKey{}, actor)
+}
+
+// isMutating reports whether a request can create, change, or delete a
+// resource.
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, "QUERY":
+		return false
+	default:
+		return true
+	}
+}
+
+func (r *Client) audit(op operation, request *http.Request, response *http.Response, attempts int, start time.Time, err error) {
+	sink := r.options.auditSink
+	if sink == nil || !isMutating(request.Method) {
+		return
+	}
+
+	ctx := request.Context()
+	record := AuditRecord{
+		Time:      start,
+		Actor:     r.auditActor(request),
+		Operation: op.name,
+		Method:    request.Method,
+		URL:       r.redact(request.URL.String()),
+		Input:     r.auditInput(request),
+		Attempts:  attempts,
+		Duration:  time.Since(start),
+		Err:       err,
+	}
+	if response != nil {
+		record.StatusCode = response.StatusCode
+		record.RequestID = response.Header.Get(requestIDHeader)
+	}
+
+	sink.Audit(ctx, record)
+}
+
+// auditActor returns the actor from the request's context, or else the
+// fingerprint of the token the request was made with.
+func (r *Client) auditActor(request *http.Request) string {
+	if actor, ok := request.Context().Value(auditActorContextKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	_, token, _ := strings.Cut(request.Header.Get("Authorization"), " ")
+	return tokenFingerprint(token)
+}
+
+// tokenFingerprint identifies a token by its last four characters.
+func tokenFingerprint(token string) string {
+	if len(token) <= 8 {
+		return "token:" + strings.Repeat("*", len(token))
+	}
+	return "token:..." + token[len(token)-4:]
+}
+
+func (r *Client) auditInput(request *http.Request) json.RawMessage {
+	if request.GetBody == nil || !strings.HasPrefix(request.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+
+	sanitized := []byte(r.redact(string(data)))
+	if !json.Valid(sanitized) {
+		return nil
+	}
+	return sanitized
+}
@@ -0,0 +1,68 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestAuditSink(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Request-ID", "req-123")
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting})
+	}))
+	defer mockServer.Close()
+
+	var mu sync.Mutex
+	var records []replicate.AuditRecord
+	client, err := replicate.NewClient(
+		replicate.WithToken("r8_secret-token-1234"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithAuditSink(replicate.AuditSinkFunc(func(_ context.Context, record replicate.AuditRecord) {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, record)
+		})),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	input := replicate.PredictionInput{"image": "data:image/png;base64,iVBORw0KGgo=", "prompt": "a cat"}
+	_, err = client.CreatePrediction(replicate.WithAuditActor(ctx, "alice"), "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil, false)
+	require.NoError(t, err)
+
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+
+	_, err = client.CancelPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+
+	require.Len(t, records, 2, "reads are not audited")
+
+	create := records[0]
+	assert.Equal(t, "CreatePrediction", create.Operation)
+	assert.Equal(t, "alice", create.Actor)
+	assert.Equal(t, http.MethodPost, create.Method)
+	assert.Equal(t, http.StatusOK, create.StatusCode)
+	assert.Equal(t, "req-123", create.RequestID)
+	assert.Equal(t, 1, create.Attempts)
+	assert.False(t, create.Time.IsZero())
+	assert.NoError(t, create.Err)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(create.Input, &body))
+	assert.Equal(t, map[string]any{"image": "data:image/png;base64,[REDACTED]", "prompt": "a cat"}, body["input"])
+
+	cancel := records[1]
+	assert.Equal(t, "CancelPrediction", cancel.Operation)
+	assert.Equal(t, "token:...1234", cancel.Actor)
+	assert.Equal(t, mockServer.URL+"/predictions/ufawqhfynnddngldkgtslldrkq/cancel", cancel.URL)
+}
@@ -0,0 +1,395 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBatchConcurrency is the number of items bulk helpers process at
+// once.
+const defaultBatchConcurrency = 8
+
+// BatchItemResult is the outcome of one item of a bulk operation.
+type BatchItemResult[T any] struct {
+	// Index is the position of the item in the input.
+	Index int
+
+	// Value is the result of the item. It is the zero value if Err is set.
+	Value T
+
+	// Err is the error for the item, or nil if it succeeded.
+	Err error
+}
+
+// BatchResult holds the per-item outcomes of a bulk operation.
+//
+// Bulk helpers don't stop at the first error. Every item is attempted, and
+// its result or error is reported at the item's index.
+type BatchResult[T any] struct {
+	Items []BatchItemResult[T]
+}
+
+// Succeeded returns the items that succeeded, in input order.
+func (b *BatchResult[T]) Succeeded() []BatchItemResult[T] {
+	var items []BatchItemResult[T]
+	for _, item := range b.Items {
+		if item.Err == nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Failed returns the items that failed, in input order.
+func (b *BatchResult[T]) Failed() []BatchItemResult[T] {
+	var items []BatchItemResult[T]
+	for _, item := range b.Items {
+		if item.Err != nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Values returns the value of every item, in input order. Failed items have
+// the zero value.
+func (b *BatchResult[T]) Values() []T {
+	values := make([]T, len(b.Items))
+	for i, item := range b.Items {
+		values[i] = item.Value
+	}
+	return values
+}
+
+// Err returns an error joining the errors of failed items, or nil if every
+// item succeeded.
+func (b *BatchResult[T]) Err() error {
+	var errs []error
+	for _, item := range b.Items {
+		if item.Err != nil {
+			errs = append(errs, fmt.Errorf("item %d: %w", item.Index, item.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runBatch calls fn for each of n items with at most concurrency calls in
+// flight and collects the outcomes. Items that haven't started when ctx is
+// done fail with the context's error.
+func runBatch[T any](ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) (T, error)) *BatchResult[T] {
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	result := &BatchResult[T]{Items: make([]BatchItemResult[T], n)}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		result.Items[i].Index = i
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			result.Items[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			value, err := fn(ctx, i)
+			result.Items[i].Value = value
+			result.Items[i].Err = err
+		}(i)
+	}
+
+	wg.Wait()
+	return result
+}
+
+// CancelPredictions cancels predictions by ID.
+//
+// A failure to cancel one prediction doesn't stop the others from being
+// canceled. Check the result for per-prediction errors.
+func (r *Client) CancelPredictions(ctx context.Context, ids []string) *BatchResult[*Prediction] {
+	return runBatch(ctx, len(ids), defaultBatchConcurrency, func(ctx context.Context, i int) (*Prediction, error) {
+		return r.CancelPrediction(ctx, ids[i])
+	})
+}
+
+// DownloadFiles downloads output files in parallel and returns their
+// contents. URLs can be HTTP(S) URLs or data URIs.
+//
+// A failed download doesn't stop the others. Check the result for per-file
+// errors.
+func (r *Client) DownloadFiles(ctx context.Context, urls []string) *BatchResult[[]byte] {
+	return runBatch(ctx, len(urls), defaultBatchConcurrency, func(ctx context.Context, i int) ([]byte, error) {
+		var file *FileOutput
+		var err error
+		if strings.HasPrefix(urls[i], "data:") {
+			file, err = readDataURI(urls[i])
+		} else {
+			file, err = readHTTP(ctx, urls[i], r)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to download file: %w", err)
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		return data, nil
+	})
+}
+
+// BatchItem is a prediction to run with RunBatch.
+type BatchItem struct {
+	// Model is the model, version, or deployment to run.
+	Model ModelRef
+
+	// Input is the input of the prediction.
+	Input PredictionInput
+}
+
+// BatchOptions configure RunBatch.
+type BatchOptions struct {
+	// Concurrency is the number of predictions run at once. The default
+	// is 8.
+	Concurrency int
+
+	// RateLimit is the number of predictions created per second, across
+	// all items and retries. Zero means no limit.
+	RateLimit float64
+
+	// Retries is how many times an item is run again after a transient
+	// failure: the prediction failing, the API rate limiting requests or
+	// being unavailable, or a network error. Other errors, such as invalid
+	// input, aren't retried. The default is not to retry.
+	Retries int
+
+	// Backoff gives the delay before each retry of an item. The default is
+	// the client's default backoff.
+	Backoff Backoff
+
+	// PollingInterval is how often running predictions are polled. The
+	// default is the same as for Wait.
+	PollingInterval time.Duration
+
+	// StartTimeout, if positive, is how long a prediction may stay in the
+	// starting status, for example while its model cold boots. A prediction
+	// still starting after that is canceled and replaced with a new one,
+	// which doesn't count as a retry. An item that runs out of replacements
+	// fails with an error matching ErrPredictionStuck.
+	StartTimeout time.Duration
+
+	// MaxReplacements is how many times an item's stuck prediction is
+	// replaced. The default is 1.
+	MaxReplacements int
+
+	// ReplacementModel, if set, is run instead of the item's model when a
+	// stuck prediction is replaced, such as a fallback deployment on other
+	// hardware.
+	ReplacementModel *ModelRef
+
+	// Checkpoint, if set, saves the progress of the batch, so that a run
+	// interrupted by a crash or deploy resumes where it left off when it's
+	// run again with the same CheckpointID and items. Each item is saved
+	// as a Job with the ID "<CheckpointID>/<index>" and Checkpoint set
+	// once it finishes. The records are saved handled, so a Supervisor
+	// sharing the store doesn't resume them. Items that succeeded in an
+	// earlier run with the same model and input aren't run again; their
+	// results are loaded from the store. Items that failed, didn't finish,
+	// or changed are run again.
+	Checkpoint JobStore
+
+	// CheckpointID identifies the batch in Checkpoint. It's required with
+	// Checkpoint.
+	CheckpointID string
+
+	// OnItemDone, if set, is called as each item finishes, with its final
+	// result. Calls are serialized, so the function needn't be safe for
+	// concurrent use, but it should return quickly.
+	OnItemDone func(BatchItemResult[*Prediction])
+}
+
+// RunBatch runs a prediction for each item and waits for them to finish,
+// with bounded concurrency and an optional rate limit on creating
+// predictions.
+//
+// A failed item doesn't stop the others. Each item's result holds its
+// final prediction, or the error it failed with after any retries; failed
+// predictions are reported as a *ModelError. If ctx is done, items that
+// haven't finished fail with its error, and the results of finished items
+// are kept.
+func (r *Client) RunBatch(ctx context.Context, items []BatchItem, opts BatchOptions) *BatchResult[*Prediction] {
+	ctx, done := r.background(ctx)
+	defer done()
+
+	backoff := opts.Backoff
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+	interval := opts.PollingInterval
+	if interval <= 0 {
+		interval = defaultPollingInterval
+	}
+	limiter := newBatchLimiter(r, opts.RateLimit)
+	runOptions := &fallbackOptions{interval: interval, startTimeout: opts.StartTimeout}
+
+	var mu sync.Mutex
+	return runBatch(ctx, len(items), opts.Concurrency, func(ctx context.Context, i int) (*Prediction, error) {
+		prediction, resumed, err := opts.loadCheckpoint(ctx, i, items[i])
+		if !resumed && err == nil {
+			var runs int
+			prediction, runs, err = r.runBatchItem(ctx, items[i], &opts, limiter, runOptions, backoff)
+			if saveErr := opts.saveCheckpoint(ctx, r.clock().Now(), i, items[i], prediction, runs, err); saveErr != nil {
+				prediction, err = nil, errors.Join(err, saveErr)
+			}
+		}
+
+		if opts.OnItemDone != nil {
+			mu.Lock()
+			opts.OnItemDone(BatchItemResult[*Prediction]{Index: i, Value: prediction, Err: err})
+			mu.Unlock()
+		}
+		return prediction, err
+	})
+}
+
+// runBatchItem runs an item of RunBatch, retrying it and replacing stuck
+// predictions as opts allow. It returns the number of predictions run.
+func (r *Client) runBatchItem(ctx context.Context, item BatchItem, opts *BatchOptions, limiter *batchLimiter, runOptions *fallbackOptions, backoff Backoff) (*Prediction, int, error) {
+	var prediction *Prediction
+	var err error
+	model := item.Model
+	replacements := 0
+	runs := 0
+	for attempt := 0; ; {
+		if err = limiter.wait(ctx); err != nil {
+			break
+		}
+		prediction, err = r.runModelRef(ctx, model, item.Input, runOptions)
+		runs++
+		if opts.canReplace(err, replacements) {
+			replacements++
+			model = opts.replacementModel(model)
+			continue
+		}
+		if err == nil || attempt >= opts.Retries || !isTransientRunError(err) {
+			break
+		}
+		if sleepErr := r.sleep(ctx, backoff.NextDelay(attempt)); sleepErr != nil {
+			break
+		}
+		attempt++
+	}
+	return prediction, runs, err
+}
+
+// canReplace reports whether an item whose run failed with err, and whose
+// stuck predictions have been replaced replacements times, is run again.
+func (o *BatchOptions) canReplace(err error, replacements int) bool {
+	maxReplacements := o.MaxReplacements
+	if maxReplacements <= 0 {
+		maxReplacements = 1
+	}
+	return errors.Is(err, ErrPredictionStuck) && replacements < maxReplacements
+}
+
+// replacementModel returns the model to run in place of a stuck prediction
+// of model.
+func (o *BatchOptions) replacementModel(model ModelRef) ModelRef {
+	if o.ReplacementModel != nil {
+		return *o.ReplacementModel
+	}
+	return model
+}
+
+// isTransientRunError reports whether a model run that failed with err may
+// succeed if run again.
+func isTransientRunError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var modelErr *ModelError
+	if errors.As(err, &modelErr) {
+		return modelErr.Prediction.Status == Failed
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == http.StatusTooManyRequests || apiErr.Status >= http.StatusInternalServerError
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// batchLimiter spaces out the predictions created by RunBatch.
+type batchLimiter struct {
+	client   *Client
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newBatchLimiter(client *Client, perSecond float64) *batchLimiter {
+	l := &batchLimiter{client: client}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+// wait blocks until the next prediction may be created.
+func (l *batchLimiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := l.client.clock().Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	return l.client.sleep(ctx, slot.Sub(now))
+}
+
+// MapPredictions runs model once for each input, with at most concurrency
+// predictions at once, and returns their outputs in input order. A
+// concurrency of zero or less uses the default of 8.
+//
+// A failed item doesn't stop the others. Check the result for per-item
+// errors; failed predictions are reported as a *ModelError. Use RunBatch
+// for retries and rate limiting.
+func (r *Client) MapPredictions(ctx context.Context, model ModelRef, inputs []PredictionInput, concurrency int) *BatchResult[PredictionOutput] {
+	ctx, done := r.background(ctx)
+	defer done()
+
+	options := &fallbackOptions{interval: defaultPollingInterval}
+	return runBatch(ctx, len(inputs), concurrency, func(ctx context.Context, i int) (PredictionOutput, error) {
+		prediction, err := r.runModelRef(ctx, model, inputs[i], options)
+		if err != nil {
+			return nil, err
+		}
+		return prediction.Output, nil
+	})
+}
@@ -0,0 +1,338 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestCancelPredictions(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/predictions/"), "/cancel")
+		if id == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail": "Not found."}`))
+			return
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Canceled})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	result := client.CancelPredictions(context.Background(), []string{"a", "missing", "c"})
+	require.Len(t, result.Items, 3)
+
+	assert.Equal(t, "a", result.Items[0].Value.ID)
+	assert.ErrorIs(t, result.Items[1].Err, replicate.ErrNotFound)
+	assert.Nil(t, result.Items[1].Value)
+	assert.Equal(t, "c", result.Items[2].Value.ID)
+
+	assert.Len(t, result.Succeeded(), 2)
+	failed := result.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, 1, failed[0].Index)
+	assert.ErrorContains(t, result.Err(), "item 1:")
+}
+
+func TestDownloadFiles(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("contents of " + r.URL.Path))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	result := client.DownloadFiles(context.Background(), []string{
+		mockServer.URL + "/out-0.png",
+		mockServer.URL + "/gone.png",
+		"data:text/plain;base64,aGVsbG8=",
+	})
+
+	values := result.Values()
+	assert.Equal(t, "contents of /out-0.png", string(values[0]))
+	assert.Nil(t, values[1])
+	assert.Equal(t, "hello", string(values[2]))
+	assert.Error(t, result.Items[1].Err)
+	assert.Error(t, result.Err())
+}
+
+func TestRunBatch(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	inflight, maxInflight := 0, 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/models/owner/model/predictions":
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			name := body.Input["name"].(string)
+			if name == "invalid" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				w.Write([]byte(`{"title": "Invalid input", "status": 422}`))
+				return
+			}
+
+			mu.Lock()
+			attempts[name]++
+			id := fmt.Sprintf("%s-%d", name, attempts[name])
+			inflight++
+			maxInflight = max(maxInflight, inflight)
+			mu.Unlock()
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Starting})
+		case r.Method == http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			mu.Lock()
+			inflight--
+			mu.Unlock()
+
+			prediction := &replicate.Prediction{ID: id, Status: replicate.Succeeded, Output: id}
+			if id == "flaky-1" {
+				prediction.Status = replicate.Failed
+				prediction.Error = "CUDA out of memory"
+			}
+			json.NewEncoder(w).Encode(prediction)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+
+	model := replicate.ModelRef{Identifier: "owner/model"}
+	items := []replicate.BatchItem{
+		{Model: model, Input: replicate.PredictionInput{"name": "flaky"}},
+		{Model: model, Input: replicate.PredictionInput{"name": "invalid"}},
+	}
+	for i := 0; i < 6; i++ {
+		items = append(items, replicate.BatchItem{Model: model, Input: replicate.PredictionInput{"name": fmt.Sprint("item", i)}})
+	}
+
+	var done []int
+	result := client.RunBatch(context.Background(), items, replicate.BatchOptions{
+		Concurrency:     2,
+		RateLimit:       1000,
+		Retries:         1,
+		Backoff:         &replicate.ConstantBackoff{},
+		PollingInterval: time.Millisecond,
+		OnItemDone: func(item replicate.BatchItemResult[*replicate.Prediction]) {
+			done = append(done, item.Index)
+		},
+	})
+	require.Len(t, result.Items, len(items))
+
+	assert.Equal(t, "flaky-2", result.Items[0].Value.Output)
+	assert.ErrorIs(t, result.Items[1].Err, replicate.ErrValidation)
+	for _, item := range result.Items[2:] {
+		assert.NoError(t, item.Err)
+	}
+	assert.Len(t, result.Failed(), 1)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7}, done)
+	assert.LessOrEqual(t, maxInflight, 2)
+	assert.Equal(t, 2, attempts["flaky"])
+}
+
+func TestRunBatchReplacesStuckPredictions(t *testing.T) {
+	var canceled atomic.Value
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/models/owner/model/predictions":
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "cold", Status: replicate.Starting})
+		case r.Method == http.MethodPost && r.URL.Path == "/deployments/acme/warm/predictions":
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "warm", Status: replicate.Starting})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/cancel"):
+			canceled.Store(r.URL.Path)
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "cold", Status: replicate.Canceled})
+		case r.URL.Path == "/predictions/cold":
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "cold", Status: replicate.Starting})
+		case r.URL.Path == "/predictions/warm":
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "warm", Status: replicate.Succeeded, Output: "ok"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	clock := replicatetest.NewFakeClock(time.Now())
+	clock.AutoAdvance(true)
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithClock(clock),
+	)
+	require.NoError(t, err)
+
+	items := []replicate.BatchItem{{Model: replicate.ModelRef{Identifier: "owner/model"}}}
+	result := client.RunBatch(context.Background(), items, replicate.BatchOptions{
+		StartTimeout:     5 * time.Second,
+		ReplacementModel: &replicate.ModelRef{Deployment: "acme/warm"},
+	})
+	require.NoError(t, result.Err())
+	assert.Equal(t, "ok", result.Items[0].Value.Output)
+	assert.Equal(t, "/predictions/cold/cancel", canceled.Load())
+
+	result = client.RunBatch(context.Background(), items, replicate.BatchOptions{
+		StartTimeout:    5 * time.Second,
+		MaxReplacements: 2,
+	})
+	assert.ErrorIs(t, result.Items[0].Err, replicate.ErrPredictionStuck)
+}
+
+func TestRunBatchCheckpoint(t *testing.T) {
+	var mu sync.Mutex
+	created := map[string]int{}
+	healthy := false
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPost:
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			name := body.Input["name"].(string)
+			created[name]++
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: name, Status: replicate.Starting})
+		case http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			prediction := &replicate.Prediction{ID: id, Status: replicate.Succeeded, Output: "out-" + id}
+			if id == "b" && !healthy {
+				prediction.Status = replicate.Failed
+				prediction.Error = "CUDA out of memory"
+			}
+			json.NewEncoder(w).Encode(prediction)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	var items []replicate.BatchItem
+	for _, name := range []string{"a", "b", "c"} {
+		items = append(items, replicate.BatchItem{Model: replicate.ModelRef{Identifier: "owner/model"}, Input: replicate.PredictionInput{"name": name}})
+	}
+	store := replicate.NewMemoryJobStore()
+	opts := replicate.BatchOptions{PollingInterval: time.Millisecond, Checkpoint: store, CheckpointID: "nightly"}
+
+	result := client.RunBatch(context.Background(), items, opts)
+	assert.Len(t, result.Failed(), 1)
+	job, err := store.LoadJob(context.Background(), "nightly/1")
+	require.NoError(t, err)
+	assert.True(t, job.Checkpoint)
+	assert.Equal(t, replicate.Failed, job.Status)
+	assert.NotEmpty(t, job.LastError)
+	job, err = store.LoadJob(context.Background(), "nightly/2")
+	require.NoError(t, err)
+	assert.True(t, job.Handled)
+	assert.Equal(t, "out-c", job.Output)
+
+	// Checkpoint records aren't jobs for a Supervisor sharing the store.
+	incomplete, err := store.ListIncompleteJobs(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, incomplete)
+
+	mu.Lock()
+	healthy = true
+	mu.Unlock()
+
+	result = client.RunBatch(context.Background(), items, opts)
+	require.NoError(t, result.Err())
+	assert.Equal(t, []replicate.PredictionOutput{"out-a", "out-b", "out-c"}, []replicate.PredictionOutput{
+		result.Items[0].Value.Output, result.Items[1].Value.Output, result.Items[2].Value.Output,
+	})
+	mu.Lock()
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 1}, created)
+	mu.Unlock()
+
+	// Items whose input changed since they were saved run again.
+	items[2].Input = replicate.PredictionInput{"name": "d"}
+	result = client.RunBatch(context.Background(), items, opts)
+	require.NoError(t, result.Err())
+	assert.Equal(t, "out-d", result.Items[2].Value.Output)
+	mu.Lock()
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 1, "d": 1}, created)
+	mu.Unlock()
+
+	opts.CheckpointID = ""
+	result = client.RunBatch(context.Background(), items, opts)
+	assert.ErrorContains(t, result.Err(), "checkpoint ID must be set")
+}
+
+func TestMapPredictions(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			assert.Equal(t, "/deployments/acme/sdxl/predictions", r.URL.Path)
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: body.Input["row"].(string), Status: replicate.Starting})
+		case http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			prediction := &replicate.Prediction{ID: id, Status: replicate.Succeeded, Output: "image-" + id}
+			if id == "2" {
+				prediction.Status = replicate.Failed
+				prediction.Error = "NSFW content detected"
+			}
+			json.NewEncoder(w).Encode(prediction)
+		}
+	}))
+	defer mockServer.Close()
+
+	clock := replicatetest.NewFakeClock(time.Now())
+	clock.AutoAdvance(true)
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithClock(clock),
+	)
+	require.NoError(t, err)
+
+	var inputs []replicate.PredictionInput
+	for i := 0; i < 5; i++ {
+		inputs = append(inputs, replicate.PredictionInput{"row": fmt.Sprint(i)})
+	}
+	result := client.MapPredictions(context.Background(), replicate.ModelRef{Deployment: "acme/sdxl"}, inputs, 3)
+
+	assert.Equal(t, []replicate.PredictionOutput{"image-0", "image-1", nil, "image-3", "image-4"}, result.Values())
+	var modelErr *replicate.ModelError
+	require.ErrorAs(t, result.Items[2].Err, &modelErr)
+	assert.Equal(t, "NSFW content detected", modelErr.Prediction.Error)
+}
@@ -0,0 +1,327 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+const defaultBudgetWindow = 24 * time.Hour
+
+// ErrBudgetExceeded is returned when creating a prediction while the spend
+// tracked by the budget guard is over its limit.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetGuard limits the estimated spend of the predictions a client
+// creates over a sliding window.
+type BudgetGuard struct {
+	// Limit is the spend allowed in each window, in dollars.
+	Limit float64
+
+	// Window is the length of the sliding window. The default is 24 hours.
+	Window time.Duration
+
+	// Rates are the prices spend is estimated with by EstimateCost. The
+	// default is the client's rates, set with WithRateTable.
+	Rates *RateTable
+
+	// Reserve is the estimated cost, in dollars, held against the limit
+	// for each prediction from when it's created until the client sees it
+	// complete, or until it leaves the window. It keeps many concurrent
+	// creates from all passing the check and overshooting the limit. Set
+	// it to the typical cost of a prediction. Zero reserves nothing.
+	Reserve float64
+
+	// Wait makes creating a prediction wait for the spend to fall below
+	// the limit, as older predictions leave the window, rather than fail
+	// with ErrBudgetExceeded.
+	Wait bool
+
+	// Thresholds are fractions of Limit, such as 0.5 and 0.9, at which
+	// OnThreshold is called as spend rises past them.
+	Thresholds []float64
+
+	// OnThreshold is called with a threshold and the budget's status when
+	// spend rises past the threshold. It's called again if spend falls back
+	// below the threshold and rises past it again. It's called
+	// synchronously and should return quickly.
+	OnThreshold func(threshold float64, status BudgetStatus)
+}
+
+// BudgetStatus is the spend tracked by a budget guard.
+type BudgetStatus struct {
+	// Spent is the estimated spend in the current window, in dollars.
+	Spent float64
+
+	// Reserved is the cost held for running predictions with
+	// BudgetGuard.Reserve, in dollars. It isn't included in Spent.
+	Reserved float64
+
+	// Limit and Window are those of the guard.
+	Limit  float64
+	Window time.Duration
+}
+
+// WithBudgetGuard limits the estimated spend of the predictions the client
+// creates. The cost of each prediction the client sees complete is
+// estimated with EstimateCost and counted for the guard's window. While the
+// spend, plus the cost reserved for running predictions, is at or over the
+// limit, creating a prediction fails with ErrBudgetExceeded, or waits if
+// guard.Wait is set.
+//
+// Spend is only known when predictions complete. Unless guard.Reserve is
+// set, predictions running when the limit is reached, such as many created
+// at once, can take spend over it, and even with it they can if they cost
+// more than reserved. Predictions whose cost can't be estimated aren't
+// counted. Spend is shared with clients derived with With.
+func WithBudgetGuard(guard BudgetGuard) ClientOption {
+	return func(o *clientOptions) error {
+		if guard.Limit <= 0 {
+			return errors.New("budget limit must be positive")
+		}
+		if guard.Reserve < 0 {
+			return errors.New("budget reserve must not be negative")
+		}
+		if guard.Window <= 0 {
+			guard.Window = defaultBudgetWindow
+		}
+		if guard.Rates != nil {
+			rates := guard.Rates.Clone()
+			guard.Rates = &rates
+		}
+		guard.Thresholds = slices.Clone(guard.Thresholds)
+		o.budgetGuard = &guard
+		return nil
+	}
+}
+
+// BudgetStatus returns the spend tracked by the client's budget guard. It
+// returns the zero value if the client has no budget guard.
+func (r *Client) BudgetStatus() BudgetStatus {
+	guard := r.options.budgetGuard
+	if guard == nil {
+		return BudgetStatus{}
+	}
+	s := &r.state.spend
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	spent, reserved, _ := s.statusLocked(r.clock().Now(), guard)
+	return BudgetStatus{Spent: spent, Reserved: reserved, Limit: guard.Limit, Window: guard.Window}
+}
+
+// spendTracker holds the estimated cost of recently completed predictions,
+// and the cost reserved for running ones.
+type spendTracker struct {
+	mu sync.Mutex
+
+	// entries are the costs in the window, oldest first.
+	entries []spendEntry
+
+	// reservations are the costs held for running predictions, oldest
+	// first. Reservations for predictions that haven't been created yet
+	// have no ID.
+	reservations []*spendReservation
+
+	// released is closed and replaced when a reservation is released, to
+	// wake callers waiting for the budget.
+	released chan struct{}
+
+	// crossed holds the thresholds spend has risen past.
+	crossed map[float64]bool
+}
+
+type spendEntry struct {
+	at   time.Time
+	cost float64
+}
+
+type spendReservation struct {
+	spendEntry
+	id string
+}
+
+// statusLocked prunes the entries and reservations that have left the
+// window, rearms the thresholds spend has fallen below, and returns the
+// spend and the reserved cost in the window, and the time their sum will
+// fall below the guard's limit.
+func (s *spendTracker) statusLocked(now time.Time, guard *BudgetGuard) (float64, float64, time.Time) {
+	start := now.Add(-guard.Window)
+	i := 0
+	for i < len(s.entries) && !s.entries[i].at.After(start) {
+		i++
+	}
+	s.entries = s.entries[i:]
+	s.reservations = slices.DeleteFunc(s.reservations, func(res *spendReservation) bool {
+		return !res.at.After(start)
+	})
+
+	var spent, reserved float64
+	for _, e := range s.entries {
+		spent += e.cost
+	}
+	for _, res := range s.reservations {
+		reserved += res.cost
+	}
+	for threshold := range s.crossed {
+		if spent < threshold*guard.Limit {
+			delete(s.crossed, threshold)
+		}
+	}
+
+	// Both leave the window in the order they were added.
+	held := slices.Clone(s.entries)
+	for _, res := range s.reservations {
+		held = append(held, res.spendEntry)
+	}
+	slices.SortStableFunc(held, func(a, b spendEntry) int {
+		return a.at.Compare(b.at)
+	})
+	below := now
+	over := spent + reserved
+	for _, e := range held {
+		if over < guard.Limit {
+			break
+		}
+		over -= e.cost
+		below = e.at.Add(guard.Window)
+	}
+	return spent, reserved, below
+}
+
+// releaseLocked removes a reservation and wakes the callers waiting for the
+// budget.
+func (s *spendTracker) releaseLocked(res *spendReservation) {
+	i := slices.Index(s.reservations, res)
+	if i < 0 {
+		return
+	}
+	s.reservations = slices.Delete(s.reservations, i, i+1)
+	if s.released != nil {
+		close(s.released)
+		s.released = nil
+	}
+}
+
+// reserveBudget returns an error wrapping ErrBudgetExceeded if the spend
+// and reserved cost are over the budget guard's limit, or waits for them to
+// fall below the limit. It then reserves the guard's Reserve for the
+// prediction about to be created, which must be passed to settleBudget.
+func (r *Client) reserveBudget(ctx context.Context) (*spendReservation, error) {
+	guard := r.options.budgetGuard
+	if guard == nil {
+		return nil, nil
+	}
+
+	s := &r.state.spend
+	for {
+		now := r.clock().Now()
+		s.mu.Lock()
+		spent, reserved, below := s.statusLocked(now, guard)
+		if spent+reserved < guard.Limit {
+			var res *spendReservation
+			if guard.Reserve > 0 {
+				res = &spendReservation{spendEntry: spendEntry{at: now, cost: guard.Reserve}}
+				s.reservations = append(s.reservations, res)
+			}
+			s.mu.Unlock()
+			return res, nil
+		}
+		if s.released == nil {
+			s.released = make(chan struct{})
+		}
+		released := s.released
+		s.mu.Unlock()
+
+		if !guard.Wait {
+			return nil, fmt.Errorf("%w: spent $%.2f of $%.2f in the last %s, with $%.2f reserved", ErrBudgetExceeded, spent, guard.Limit, guard.Window, reserved)
+		}
+		r.log(ctx, slog.LevelInfo, "waiting for budget", slog.Float64("spent", spent), slog.Float64("reserved", reserved), slog.Duration("wait", below.Sub(now)))
+		timer := r.clock().NewTimer(below.Sub(now))
+		select {
+		case <-timer.C():
+		case <-released:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, contextError(ctx)
+		}
+	}
+}
+
+// settleBudget ties a reservation to the prediction it was made for, to be
+// released when the prediction completes, or releases it if the prediction
+// wasn't created, was already completed, or already holds a reservation.
+func (r *Client) settleBudget(res *spendReservation, prediction *Prediction, err error) {
+	if res == nil {
+		return
+	}
+	s := &r.state.spend
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil || prediction.ID == "" || prediction.Status.Terminated() || slices.ContainsFunc(s.reservations, func(other *spendReservation) bool {
+		return other.id == prediction.ID
+	}) {
+		s.releaseLocked(res)
+		return
+	}
+	res.id = prediction.ID
+}
+
+// recordSpend releases the reservation of a completed prediction and counts
+// its estimated cost towards the budget guard's limit.
+func (r *Client) recordSpend(prediction *Prediction) {
+	guard := r.options.budgetGuard
+	if guard == nil {
+		return
+	}
+	s := &r.state.spend
+	s.mu.Lock()
+	if i := slices.IndexFunc(s.reservations, func(res *spendReservation) bool {
+		return res.id == prediction.ID
+	}); i >= 0 {
+		s.releaseLocked(s.reservations[i])
+	}
+	s.mu.Unlock()
+
+	rates := guard.Rates
+	if rates == nil {
+		table := r.rateTable()
+		rates = &table
+	}
+	cost, err := EstimateCost(prediction, *rates)
+	if err != nil {
+		r.log(context.Background(), slog.LevelDebug, "not counting prediction towards budget", slog.String("prediction_id", prediction.ID), r.errorAttr(err))
+		return
+	}
+
+	now := r.clock().Now()
+	s.mu.Lock()
+	// Rearm the thresholds spend fell below since the last prediction.
+	s.statusLocked(now, guard)
+	s.entries = append(s.entries, spendEntry{at: now, cost: cost})
+	spent, reserved, _ := s.statusLocked(now, guard)
+
+	var crossed []float64
+	if s.crossed == nil {
+		s.crossed = make(map[float64]bool)
+	}
+	for _, threshold := range guard.Thresholds {
+		if spent >= threshold*guard.Limit && !s.crossed[threshold] {
+			s.crossed[threshold] = true
+			crossed = append(crossed, threshold)
+		}
+	}
+	s.mu.Unlock()
+
+	if guard.OnThreshold != nil {
+		status := BudgetStatus{Spent: spent, Reserved: reserved, Limit: guard.Limit, Window: guard.Window}
+		for _, threshold := range crossed {
+			guard.OnThreshold(threshold, status)
+		}
+	}
+}
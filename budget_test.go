@@ -0,0 +1,157 @@
+package replicate_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestBudgetGuard(t *testing.T) {
+	var created atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := created.Add(1)
+		fmt.Fprintf(w, `{"id": "p%d", "model": "acme/images", "status": "succeeded", "output": ["https://example.com/out.png"]}`, n)
+	}))
+	defer mockServer.Close()
+
+	clock := replicatetest.NewFakeClock(time.Now())
+	rates := replicate.RateTable{Outputs: map[string]float64{"acme/images": 1}}
+	var crossed []float64
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithClock(clock),
+		replicate.WithBudgetGuard(replicate.BudgetGuard{
+			Limit:      2,
+			Window:     time.Hour,
+			Rates:      &rates,
+			Thresholds: []float64{0.5, 1},
+			OnThreshold: func(threshold float64, status replicate.BudgetStatus) {
+				crossed = append(crossed, threshold)
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	create := func(client *replicate.Client) error {
+		_, err := client.CreatePrediction(ctx, "acme/images", replicate.PredictionInput{}, nil, false)
+		return err
+	}
+
+	require.NoError(t, create(client))
+	assert.Equal(t, []float64{0.5}, crossed)
+	clock.Advance(30 * time.Minute)
+	require.NoError(t, create(client))
+	assert.Equal(t, []float64{0.5, 1}, crossed)
+	assert.Equal(t, replicate.BudgetStatus{Spent: 2, Limit: 2, Window: time.Hour}, client.BudgetStatus())
+
+	err = create(client)
+	assert.ErrorIs(t, err, replicate.ErrBudgetExceeded)
+	assert.Equal(t, int32(2), created.Load())
+
+	// The first prediction leaves the window after an hour.
+	clock.Advance(30 * time.Minute)
+	assert.Equal(t, 1.0, client.BudgetStatus().Spent)
+	require.NoError(t, create(client))
+	assert.Equal(t, []float64{0.5, 1, 1}, crossed)
+
+	// With Wait, creation waits for the next prediction to leave the window.
+	waiting, err := client.With(replicate.WithBudgetGuard(replicate.BudgetGuard{
+		Limit:  2,
+		Window: time.Hour,
+		Rates:  &rates,
+		Wait:   true,
+	}))
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- create(waiting) }()
+	require.NoError(t, clock.BlockUntil(ctx, 1))
+	assert.Equal(t, int32(3), created.Load())
+	clock.Advance(30 * time.Minute)
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(4), created.Load())
+}
+
+func TestBudgetGuardReserve(t *testing.T) {
+	var created atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/models/acme/images/predictions":
+			n := created.Add(1)
+			fmt.Fprintf(w, `{"id": "p%d", "model": "acme/images", "status": "starting"}`, n)
+		case "/predictions/p1":
+			w.Write([]byte(`{"id": "p1", "model": "acme/images", "status": "succeeded", "output": ["https://example.com/out.png"]}`))
+		case "/predictions/p2":
+			w.Write([]byte(`{"id": "p2", "model": "acme/unpriced", "status": "succeeded"}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	clock := replicatetest.NewFakeClock(time.Now())
+	rates := replicate.RateTable{Outputs: map[string]float64{"acme/images": 1}}
+	guard := replicate.BudgetGuard{
+		Limit:   2,
+		Window:  time.Hour,
+		Rates:   &rates,
+		Reserve: 1,
+	}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithClock(clock),
+		replicate.WithBudgetGuard(guard),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	create := func(client *replicate.Client) error {
+		_, err := client.CreatePrediction(ctx, "acme/images", replicate.PredictionInput{}, nil, false)
+		return err
+	}
+
+	// Running predictions hold their reservation against the limit.
+	require.NoError(t, create(client))
+	require.NoError(t, create(client))
+	assert.Equal(t, replicate.BudgetStatus{Reserved: 2, Limit: 2, Window: time.Hour}, client.BudgetStatus())
+	err = create(client)
+	assert.ErrorIs(t, err, replicate.ErrBudgetExceeded)
+	assert.Equal(t, int32(2), created.Load())
+
+	// Completing a prediction settles its reservation with its cost.
+	_, err = client.GetPrediction(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, replicate.BudgetStatus{Spent: 1, Reserved: 1, Limit: 2, Window: time.Hour}, client.BudgetStatus())
+
+	// With Wait, creation waits for a reservation to be released.
+	guard.Wait = true
+	waiting, err := client.With(replicate.WithBudgetGuard(guard))
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- create(waiting) }()
+	require.NoError(t, clock.BlockUntil(ctx, 1))
+	assert.Equal(t, int32(2), created.Load())
+
+	_, err = client.GetPrediction(ctx, "p2")
+	require.NoError(t, err)
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(3), created.Load())
+	assert.Equal(t, replicate.BudgetStatus{Spent: 1, Reserved: 1, Limit: 2, Window: time.Hour}, waiting.BudgetStatus())
+
+	// Reservations for predictions the client never sees complete leave the
+	// window.
+	clock.Advance(time.Hour)
+	assert.Equal(t, replicate.BudgetStatus{Limit: 2, Window: time.Hour}, client.BudgetStatus())
+}
@@ -0,0 +1,94 @@
+package replicate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// checkpointJobID returns the ID of the job that records the progress of
+// item i of a checkpointed batch.
+func (o *BatchOptions) checkpointJobID(i int) string {
+	return fmt.Sprintf("%s/%d", o.CheckpointID, i)
+}
+
+// loadCheckpoint returns the prediction of item i if a previous run of the
+// batch completed it with the same model and input.
+func (o *BatchOptions) loadCheckpoint(ctx context.Context, i int, item BatchItem) (*Prediction, bool, error) {
+	if o.Checkpoint == nil {
+		return nil, false, nil
+	}
+	if o.CheckpointID == "" {
+		return nil, false, errors.New("checkpoint ID must be set to use a checkpoint store")
+	}
+
+	job, err := o.Checkpoint.LoadJob(ctx, o.checkpointJobID(i))
+	if errors.Is(err, ErrJobNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	if !job.Checkpoint || job.Status != Succeeded || job.LastError != "" || !sameItem(job, item) {
+		return nil, false, nil
+	}
+	return &Prediction{ID: job.PredictionID, Status: job.Status, Input: job.Input, Output: job.Output}, true, nil
+}
+
+// saveCheckpoint records the outcome of item i. Every record is saved
+// handled, so ListIncompleteJobs doesn't return it; items that failed keep
+// their error and run again when the batch is resumed.
+func (o *BatchOptions) saveCheckpoint(ctx context.Context, now time.Time, i int, item BatchItem, prediction *Prediction, attempts int, runErr error) error {
+	if o.Checkpoint == nil || errors.Is(runErr, context.Canceled) || errors.Is(runErr, context.DeadlineExceeded) {
+		return nil
+	}
+
+	job := &Job{
+		ID:         o.checkpointJobID(i),
+		Identifier: item.Model.String(),
+		Input:      item.Input,
+		Handled:    true,
+		Checkpoint: true,
+		Attempts:   attempts,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if prediction != nil {
+		job.PredictionID = prediction.ID
+		job.Status = prediction.Status
+		job.Output = prediction.Output
+	}
+	if runErr != nil {
+		job.LastError = runErr.Error()
+		var modelErr *ModelError
+		if errors.As(runErr, &modelErr) {
+			job.PredictionID = modelErr.Prediction.ID
+			job.Status = modelErr.Prediction.Status
+		}
+	}
+
+	if err := o.Checkpoint.SaveJob(ctx, job); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// sameItem reports whether a checkpoint record was saved for item. Inputs
+// are compared as JSON, since stores may not keep their Go types.
+func sameItem(job *Job, item BatchItem) bool {
+	if job.Identifier != item.Model.String() {
+		return false
+	}
+	saved, err := json.Marshal(job.Input)
+	if err != nil {
+		return false
+	}
+	current, err := json.Marshal(item.Input)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(saved, current)
+}
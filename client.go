@@ -7,10 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -33,22 +35,114 @@ var (
 )
 
 // Client is a client for the Replicate API.
+//
+// A Client is safe for concurrent use by multiple goroutines, and should be
+// reused rather than created for each call. Its options are fixed when it's
+// created: options copy the headers, query parameters, webhooks, rate
+// tables, budget guards, and TLS configs passed to them, so changing those
+// afterwards has no effect. Options that take an *http.Client, a logger, a
+// writer, an interface such as Metrics, TokenProvider, or ResultCache, or a
+// function such as a hook or sink keep a reference to it instead. Those
+// must be safe for concurrent use, and changing them affects the client.
+// Use With to derive a client with different options.
 type Client struct {
 	options *clientOptions
 	c       *http.Client
+	state   *clientState
 }
 
-type retryPolicy struct {
-	maxRetries int
-	backoff    Backoff
+// clientState holds the mutable state of a client.
+type clientState struct {
+	mu        sync.Mutex
+	rateLimit *RateLimitState
+
+	// inflight limits concurrent requests when WithMaxConcurrentRequests is set.
+	inflight chan struct{}
+
+	// endpoints tracks endpoint health when failover base URLs are set.
+	endpoints *endpointSet
+
+	counters clientCounters
+	latency  latencyTracker
+	usage    usageLedger
+	streams  streamCounters
+	dedupe   predictionDedupe
+	events   eventBus
+
+	// modelSlots enforces the caps set with WithModelConcurrency.
+	modelSlots modelSlotSet
+
+	// spend tracks the estimated cost of predictions for WithBudgetGuard.
+	spend spendTracker
+
+	// hardware caches the available hardware for WithHardwareValidation.
+	hardware hardwareCache
+
+	// owners caches the account of each token for CurrentOwner.
+	owners ownerCache
+
+	// lifecycle tracks background work for Close.
+	lifecycle lifecycle
+
+	// tokens are the tokens used recently, for redaction.
+	tokens []string
 }
 
 type clientOptions struct {
-	auth        string
-	baseURL     string
-	httpClient  *http.Client
-	retryPolicy *retryPolicy
-	userAgent   *string
+	tokenProvider    TokenProvider
+	baseURL          string
+	httpClient       *http.Client
+	transport        transportOptions
+	retryPolicy      RetryPolicy
+	maxRetryDuration time.Duration
+	userAgent        *string
+	appInfo          []string
+	headers          http.Header
+	queryParams      url.Values
+	redirectPolicy   RedirectPolicy
+
+	disableCompression          bool
+	requestCompression          bool
+	requestCompressionThreshold int
+	webhook                     *Webhook
+	throttleBelow               *int
+	maxConcurrent               int
+	dedupeWindow                time.Duration
+	resultCache                 ResultCache
+	resultCacheTTL              time.Duration
+	cancelOnClose               bool
+	modelConcurrency            map[string]int
+	budgetGuard                 *BudgetGuard
+	rateTable                   *RateTable
+	hardwareTTL                 time.Duration
+	hedgeDelay                  time.Duration
+	errorBodyLimit              int
+	timeouts                    timeouts
+
+	streamFallbackHook    StreamFallbackHook
+	disableStreamFallback bool
+	onRetry               RetryHook
+
+	failoverBaseURLs    []string
+	healthCheckInterval time.Duration
+
+	metrics Metrics
+	logger  *slog.Logger
+	debug   *debugWriter
+
+	middleware []Middleware
+	hooks      callHooks
+	auditSink  AuditSink
+
+	slowCallThreshold time.Duration
+	onSlowCall        CallHook
+
+	usageExporter UsageExporter
+
+	streamStallTimeout time.Duration
+	sampler            *payloadSampler
+
+	clock Clock
 }
 
 // ClientOption is a function that modifies an options struct.
@@ -60,17 +154,39 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		options: &clientOptions{
 			userAgent: &defaultUserAgent,
 			baseURL:   defaultBaseURL,
-			retryPolicy: &retryPolicy{
-				maxRetries: defaultMaxRetries,
-				backoff:    defaultBackoff,
+			retryPolicy: &BackoffRetryPolicy{
+				MaxRetries: defaultMaxRetries,
+				Backoff:    defaultBackoff,
 			},
-			httpClient: http.DefaultClient,
 		},
+		state: &clientState{},
+	}
+	c.options.errorBodyLimit = defaultErrorBodyLimit
+	c.options.healthCheckInterval = defaultHealthCheckInterval
+
+	if err := applyOptions(c.options, opts); err != nil {
+		return nil, err
+	}
+	if err := c.configureHTTPClient(); err != nil {
+		return nil, err
+	}
+
+	if c.options.maxConcurrent > 0 {
+		c.state.inflight = make(chan struct{}, c.options.maxConcurrent)
+	}
+
+	if len(c.options.failoverBaseURLs) > 0 {
+		baseURLs := append([]string{c.options.baseURL}, c.options.failoverBaseURLs...)
+		c.state.endpoints = newEndpointSet(baseURLs, c.options.healthCheckInterval)
 	}
 
+	return c, nil
+}
+
+func applyOptions(o *clientOptions, opts []ClientOption) error {
 	var errs []error
 	for _, option := range opts {
-		err := option(c.options)
+		err := option(o)
 		if err != nil {
 			errs = append(errs, err)
 		}
@@ -78,24 +194,40 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 	if len(errs) > 0 {
 		err := errors.Join(errs...)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		return nil, errors.New("failed to apply options")
+		return errors.New("failed to apply options")
 	}
 
-	if c.options.auth == "" {
-		return nil, ErrNoAuth
+	if token, ok := o.tokenProvider.(staticToken); o.tokenProvider == nil || (ok && token == "") {
+		return ErrNoAuth
 	}
+	return nil
+}
 
-	c.c = c.options.httpClient
+// configureHTTPClient sets up the HTTP client from the options. The
+// configured client replaces the options' HTTP client and transport
+// options, so clients derived with With share its transport.
+func (c *Client) configureHTTPClient() error {
+	if c.options.httpClient == nil {
+		c.options.httpClient = &http.Client{Transport: sharedTransport()}
+	}
+	httpClient, err := configureTransport(c.options.httpClient, c.options.transport)
+	if err != nil {
+		return err
+	}
+	c.options.httpClient = httpClient
+	c.options.transport = transportOptions{}
 
-	return c, nil
+	httpClient = withRedirectPolicy(httpClient, c.options.redirectPolicy)
+	c.c = withMiddleware(httpClient, c.options.middleware)
+	return nil
 }
 
 // WithToken sets the auth token used by the client.
 func WithToken(token string) ClientOption {
 	return func(o *clientOptions) error {
-		o.auth = token
+		o.tokenProvider = StaticToken(token)
 		return nil
 	}
 }
@@ -111,7 +243,7 @@ func WithTokenFromEnv() ClientOption {
 		if token == "" {
 			return ErrEnvVarEmpty
 		}
-		o.auth = token
+		o.tokenProvider = StaticToken(token)
 		return nil
 	}
 }
@@ -124,15 +256,75 @@ func WithUserAgent(userAgent string) ClientOption {
 	}
 }
 
-// WithBaseURL sets the base URL for the client.
+// WithBaseURL sets the base URL for the client, such as an internal gateway,
+// a regional proxy, or a mock server. It must be an absolute http or https
+// URL without a query or fragment.
 func WithBaseURL(baseURL string) ClientOption {
 	return func(o *clientOptions) error {
+		if err := validateBaseURL(baseURL); err != nil {
+			return err
+		}
 		o.baseURL = baseURL
 		return nil
 	}
 }
 
+// WithAppInfo identifies the application using the client by appending
+// "name/version" to the User-Agent header, after the client's own
+// identifier, so traffic can be attributed to a service in Replicate's and
+// gateways' logs. It can be used more than once, and the version may be
+// empty.
+func WithAppInfo(name, version string) ClientOption {
+	return func(o *clientOptions) error {
+		if !isUserAgentToken(name) {
+			return fmt.Errorf("invalid app name %q", name)
+		}
+		if version == "" {
+			o.appInfo = append(o.appInfo, name)
+			return nil
+		}
+		if !isUserAgentToken(version) {
+			return fmt.Errorf("invalid app version %q", version)
+		}
+		o.appInfo = append(o.appInfo, name+"/"+version)
+		return nil
+	}
+}
+
+// isUserAgentToken reports whether s can be used as a product name or
+// version in a User-Agent header.
+func isUserAgentToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// setUserAgent sets the User-Agent header of a request, if the client has
+// one.
+func (r *Client) setUserAgent(request *http.Request) {
+	if r.options.userAgent == nil && len(r.options.appInfo) == 0 {
+		return
+	}
+
+	var parts []string
+	if r.options.userAgent != nil && *r.options.userAgent != "" {
+		parts = append(parts, *r.options.userAgent)
+	}
+	parts = append(parts, r.options.appInfo...)
+	request.Header.Set("User-Agent", strings.Join(parts, " "))
+}
+
 // WithHTTPClient sets the HTTP client used by the client.
+//
+// By default, clients share a transport tuned for the API, with a larger
+// pool of idle connections than http.DefaultTransport. Transport options
+// like WithMaxIdleConnsPerHost apply to a copy of httpClient's transport.
 func WithHTTPClient(httpClient *http.Client) ClientOption {
 	return func(o *clientOptions) error {
 		o.httpClient = httpClient
@@ -141,92 +333,310 @@ func WithHTTPClient(httpClient *http.Client) ClientOption {
 }
 
 // WithRetryPolicy sets the retry policy used by the client.
+//
+// Requests are retried at most maxRetries times, waiting between attempts
+// for the delay given by backoff or by the response's Retry-After header,
+// up to one minute.
 func WithRetryPolicy(maxRetries int, backoff Backoff) ClientOption {
 	return func(o *clientOptions) error {
-		o.retryPolicy = &retryPolicy{
-			maxRetries: maxRetries,
-			backoff:    backoff,
+		o.retryPolicy = &BackoffRetryPolicy{
+			MaxRetries: maxRetries,
+			Backoff:    backoff,
 		}
 		return nil
 	}
 }
 
+// WithCustomRetryPolicy sets a RetryPolicy that decides whether and when
+// failed requests are retried.
+func WithCustomRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(o *clientOptions) error {
+		if policy == nil {
+			return errors.New("retry policy must not be nil")
+		}
+		o.retryPolicy = policy
+		return nil
+	}
+}
+
+// WithMaxRetryDuration limits the total time spent retrying a request.
+// A retry is not attempted if its delay would exceed the budget.
+func WithMaxRetryDuration(d time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		o.maxRetryDuration = d
+		return nil
+	}
+}
+
+// WithDefaultWebhook sets the webhook used for predictions and trainings
+// created by the client when no webhook is passed to the call.
+func WithDefaultWebhook(webhook Webhook) ClientOption {
+	return func(o *clientOptions) error {
+		if webhook.URL == "" {
+			return errors.New("default webhook URL must not be empty")
+		}
+		if err := webhook.validate(); err != nil {
+			return err
+		}
+		webhook.Events = append([]WebhookEventType(nil), webhook.Events...)
+		o.webhook = &webhook
+		return nil
+	}
+}
+
+// WithMaxConcurrentRequests limits the number of HTTP requests the client
+// has in flight at once. Requests over the limit wait for a slot, or until
+// their context is done.
+func WithMaxConcurrentRequests(n int) ClientOption {
+	return func(o *clientOptions) error {
+		if n <= 0 {
+			return fmt.Errorf("max concurrent requests must be positive, got %d", n)
+		}
+		o.maxConcurrent = n
+		return nil
+	}
+}
+
+// WithErrorBodyLimit sets how many bytes of an error response body are kept
+// in APIError.Body. The default is 4 KiB. The fields of APIError are parsed
+// from up to 1 MiB of the body, whatever the limit.
+func WithErrorBodyLimit(n int) ClientOption {
+	return func(o *clientOptions) error {
+		if n < 0 {
+			return fmt.Errorf("error body limit must not be negative, got %d", n)
+		}
+		o.errorBodyLimit = n
+		return nil
+	}
+}
+
 func (r *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
-	url := constructURL(r.options.baseURL, path)
+	baseURL, ok := requestBaseURL(ctx)
+	if ok {
+		if err := validateBaseURL(baseURL); err != nil {
+			return nil, err
+		}
+	} else {
+		baseURL = r.options.baseURL
+	}
+
+	token, err := r.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	url := constructURL(baseURL, path)
 	request, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.options.auth))
-	if r.options.userAgent != nil {
-		request.Header.Set("User-Agent", *r.options.userAgent)
-	}
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	r.setUserAgent(request)
+	r.setAcceptEncoding(request)
+	setRequestContextHeaders(ctx, request)
+	r.setClientHeaders(request)
+	r.setQueryParams(ctx, request)
 
 	return request, nil
 }
 
-func (r *Client) do(request *http.Request, out interface{}) error {
-	maxRetries := r.options.retryPolicy.maxRetries
-	backoff := r.options.retryPolicy.backoff
+func (r *Client) do(request *http.Request, out interface{}) (retErr error) {
+	if r.state.lifecycle.isClosed() {
+		return ErrClientClosed
+	}
+
+	policy := r.retryPolicy()
+	maxElapsed := r.options.maxRetryDuration
 
-	var apiError *APIError
+	ctx := request.Context()
+	start := time.Now()
+	waitStart := r.clock().Now()
+	op := r.operationFor(r.baseURLFor(ctx), request.Method, request.URL)
+	timeout := r.timeoutFor(ctx, op)
+	sample := r.startSample(op, start)
+
+	var response *http.Response
 	attempts := 0
-	for ok := true; ok; ok = attempts < maxRetries {
-		response, err := r.c.Do(request)
-		if err != nil || response == nil {
-			return fmt.Errorf("failed to make request: %w", err)
+	defer func() {
+		recordCallMetadata(ctx, response, attempts, start)
+		retErr = classifyError(retErr)
+		r.audit(op, request, response, attempts, start, retErr)
+		r.finishSample(ctx, sample, retErr)
+
+		event := CallEvent{
+			Operation: op.name,
+			Request:   request,
+			Attempt:   attempts,
+			Response:  response,
+			Duration:  time.Since(start),
+			Err:       retErr,
+		}
+		r.recordLatency(event)
+		if retErr != nil {
+			runHooks(r.options.hooks.onError, event)
+		}
+	}()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := rewindBody(request); err != nil {
+				return err
+			}
 		}
-		defer response.Body.Close()
 
-		responseBytes, err := io.ReadAll(response.Body)
+		if err := r.throttle(ctx); err != nil {
+			return err
+		}
+
+		release, err := r.acquire(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to read response body: %w", err)
+			return err
+		}
+		attemptRequest, cancelAttempt := withAttemptTimeout(request, timeout)
+		done := func() {
+			cancelAttempt()
+			release()
 		}
 
-		if response.StatusCode < 200 || response.StatusCode >= 400 {
-			apiError = unmarshalAPIError(response, responseBytes)
-			if !r.shouldRetry(response, request.Method) {
-				return apiError
-			}
+		var lastErr error
+
+		attemptRequest, endpoint := r.routeToEndpoint(attemptRequest)
+
+		attempts++
+		runHooks(r.options.hooks.onRequest, CallEvent{Operation: op.name, Request: attemptRequest, Attempt: attempts})
+		attemptStart := time.Now()
+		r.dumpRequest(attemptRequest)
+		r.captureRequest(sample, attemptRequest)
+		response, err = r.send(r.compressRequest(attemptRequest))
+		attemptDuration := time.Since(attemptStart)
+		if response != nil {
+			runHooks(r.options.hooks.onResponse, CallEvent{
+				Operation: op.name,
+				Request:   attemptRequest,
+				Attempt:   attempts,
+				Response:  response,
+				Duration:  attemptDuration,
+			})
+		}
+		r.dumpResponse(attemptRequest, response, err, attemptDuration)
+		r.captureResponse(sample, response)
+		r.recordAttempt(op, response, err, attemptDuration)
+		r.logAttempt(attemptRequest, op, attempts, response, err, attemptDuration)
+		r.recordEndpointResult(endpoint, response, err)
+		if err != nil || response == nil {
+			done()
+			lastErr = fmt.Errorf("failed to make request: %w", err)
+		} else {
+			r.recordRateLimit(parseRateLimit(response.Header, time.Now()))
+
+			if response.StatusCode >= 200 && response.StatusCode < 300 {
+				if decoder, ok := out.(bodyDecoder); ok {
+					err := decodeResponseBody(response, decoder)
+					response.Body.Close()
+					done()
+					return err
+				}
 
-			delay := backoff.NextDelay(attempts)
+				responseBytes, err := io.ReadAll(response.Body)
+				response.Body.Close()
+				done()
+				if err != nil {
+					return fmt.Errorf("failed to read response body: %w", err)
+				}
 
-			retryAfter := response.Header.Get("Retry-After")
-			if retryAfter != "" {
-				if parsedDelay, parseErr := time.Parse(time.RFC1123, retryAfter); parseErr == nil {
-					delay = time.Until(parsedDelay)
-				} else if seconds, convErr := strconv.Atoi(retryAfter); convErr == nil {
-					delay = time.Duration(seconds) * time.Second
+				if out != nil {
+					if err := decodeResponse(response, responseBytes, out); err != nil {
+						return err
+					}
+					if prediction, ok := out.(*Prediction); ok {
+						r.recordUsage(request.Context(), prediction)
+						r.updateDeduplicated(prediction)
+						r.observePrediction(request, prediction)
+					}
 				}
+
+				return nil
 			}
 
-			if delay > 0 {
-				time.Sleep(delay)
+			// The whole body is parsed, so the details of a long error
+			// aren't lost, and only the body kept is limited.
+			responseBytes, truncated, err := readErrorBody(response.Body, max(r.options.errorBodyLimit, maxErrorParseLength))
+			response.Body.Close()
+			done()
+			if err != nil {
+				return fmt.Errorf("failed to read response body: %w", err)
 			}
 
-			attempts++
-		} else {
-			if out != nil {
-				if err := json.Unmarshal(responseBytes, &out); err != nil {
-					return fmt.Errorf("failed to unmarshal response: %w", err)
-				}
+			if response.StatusCode == http.StatusUnauthorized {
+				r.invalidateToken()
 			}
 
-			return nil
+			apiError := unmarshalAPIError(response, responseBytes)
+			apiError.BodyTruncated = truncated
+			apiError.truncateBody(r.options.errorBodyLimit)
+			lastErr = apiError
+		}
+
+		if !canRetry(request, response, err) {
+			return lastErr
+		}
+		delay, ok := policy.ShouldRetry(response, err, attempt)
+		if !ok {
+			return lastErr
+		}
+		if maxElapsed > 0 && r.clock().Now().Sub(waitStart)+delay > maxElapsed {
+			return lastErr
 		}
+		r.recordRetry(op, attempts, delay, lastErr)
+		r.logRetry(ctx, op, attempts, delay, lastErr)
+		if err := r.sleep(ctx, delay); err != nil {
+			return lastErr
+		}
+	}
+}
+
+// retryPolicy returns the client's retry policy, with a BackoffRetryPolicy
+// set to read Retry-After dates against the client's clock.
+func (r *Client) retryPolicy() RetryPolicy {
+	p, ok := r.options.retryPolicy.(*BackoffRetryPolicy)
+	if !ok || r.options.clock == nil {
+		return r.options.retryPolicy
+	}
+	clocked := *p
+	clocked.now = r.options.clock.Now
+	return &clocked
+}
+
+// acquire waits for a request slot when the number of concurrent requests is
+// limited. The returned function releases the slot.
+func (r *Client) acquire(ctx context.Context) (func(), error) {
+	if r.state.inflight == nil {
+		return func() {}, nil
 	}
 
-	if apiError != nil {
-		return apiError
+	select {
+	case r.state.inflight <- struct{}{}:
+		return func() { <-r.state.inflight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
+}
 
-	if attempts > 0 {
-		return fmt.Errorf("request failed after %d attempts", maxRetries)
+// tryAcquire takes a request slot if one is free, without waiting. The
+// returned function releases the slot.
+func (r *Client) tryAcquire() (func(), bool) {
+	if r.state.inflight == nil {
+		return func() {}, true
 	}
 
-	return fmt.Errorf("request failed")
+	select {
+	case r.state.inflight <- struct{}{}:
+		return func() { <-r.state.inflight }, true
+	default:
+		return nil, false
+	}
 }
 
 // fetch makes an HTTP request to Replicate's API.
@@ -248,19 +658,39 @@ func (r *Client) fetch(ctx context.Context, method, path string, body interface{
 	return r.do(request, out)
 }
 
-// shouldRetry returns true if the request should be retried.
-//
-// - GET requests should be retried if the response status code is 429 or 5xx.
-// - Other requests should be retried if the response status code is 429.
-func (r *Client) shouldRetry(response *http.Response, method string) bool {
-	if method == http.MethodGet {
-		return response.StatusCode == 429 || (response.StatusCode >= 500 && response.StatusCode < 600)
+// validateBaseURL checks that baseURL can be used as the base URL of API
+// requests.
+func validateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: missing host", baseURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid base URL %q: must not have a query or fragment", baseURL)
 	}
+	return nil
+}
 
-	return response.StatusCode == 429
+// baseURLFor returns the base URL of requests made with ctx.
+func (r *Client) baseURLFor(ctx context.Context) string {
+	if baseURL, ok := requestBaseURL(ctx); ok {
+		return baseURL
+	}
+	return r.options.baseURL
 }
 
 func constructURL(baseURL, route string) string {
+	// Pagination cursors are absolute URLs.
+	if strings.HasPrefix(route, "https://") || strings.HasPrefix(route, "http://") {
+		return route
+	}
+
 	route = strings.TrimPrefix(route, "/")
 
 	if !strings.HasSuffix(baseURL, "/") {
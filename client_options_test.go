@@ -0,0 +1,228 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	var inflight, peak int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithMaxConcurrentRequests(2),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+}
+
+func TestMaxConcurrentRequestsMustBePositive(t *testing.T) {
+	_, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithMaxConcurrentRequests(0),
+	)
+	assert.Error(t, err)
+}
+
+func TestHedgedReads(t *testing.T) {
+	var requests int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			// The first request stalls until the client gives up on it.
+			<-r.Context().Done()
+			return
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithHedgedReads(50*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	prediction, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+// slowTransport answers every request after a delay, and records how many
+// requests were in flight at once.
+type slowTransport struct {
+	delay                    time.Duration
+	requests, inflight, peak atomic.Int32
+}
+
+func (s *slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.requests.Add(1)
+	n := s.inflight.Add(1)
+	defer s.inflight.Add(-1)
+	for {
+		p := s.peak.Load()
+		if n <= p || s.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case <-time.After(s.delay):
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id": "ufawqhfynnddngldkgtslldrkq"}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestHedgedReadsMaxConcurrentRequests(t *testing.T) {
+	transport := &slowTransport{delay: 50 * time.Millisecond}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithHTTPClient(&http.Client{Transport: transport}),
+		replicate.WithHedgedReads(10*time.Millisecond),
+		replicate.WithMaxConcurrentRequests(2),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A request is hedged when a slot is free.
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), transport.requests.Load())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, transport.peak.Load(), int32(2))
+}
+
+func TestBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"api.replicate.com/v1", "ftp://example.com", "https://", "https://example.com/v1?region=us", "://bad"} {
+		_, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(baseURL),
+		)
+		assert.ErrorContains(t, err, "invalid base URL", baseURL)
+	}
+
+	var paths sync.Map
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths.Store(name, r.URL.Path)
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+		}))
+	}
+	primary := newServer("primary")
+	defer primary.Close()
+	regional := newServer("regional")
+	defer regional.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(primary.URL+"/v1"),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	path, _ := paths.Load("primary")
+	assert.Equal(t, "/v1/predictions/ufawqhfynnddngldkgtslldrkq", path)
+
+	_, err = client.GetPrediction(replicate.WithRequestBaseURL(ctx, regional.URL+"/gateway/v1/"), "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	path, _ = paths.Load("regional")
+	assert.Equal(t, "/gateway/v1/predictions/ufawqhfynnddngldkgtslldrkq", path)
+
+	_, err = client.GetPrediction(replicate.WithRequestBaseURL(ctx, "not a url"), "ufawqhfynnddngldkgtslldrkq")
+	assert.ErrorContains(t, err, "invalid base URL")
+}
+
+func TestWithAppInfo(t *testing.T) {
+	var userAgent atomic.Value
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent.Store(r.Header.Get("User-Agent"))
+		json.NewEncoder(w).Encode(&replicate.Account{Type: "user", Username: "alice"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithAppInfo("image-service", "1.4.2"),
+		replicate.WithAppInfo("acme-gateway", ""),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetCurrentAccount(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "replicate/go image-service/1.4.2 acme-gateway", userAgent.Load())
+
+	_, err = replicate.NewClient(replicate.WithToken("test-token"), replicate.WithAppInfo("image service", "1.0"))
+	assert.ErrorContains(t, err, `invalid app name "image service"`)
+	_, err = replicate.NewClient(replicate.WithToken("test-token"), replicate.WithAppInfo("svc", "1.0/beta"))
+	assert.ErrorContains(t, err, `invalid app version "1.0/beta"`)
+}
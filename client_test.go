@@ -2039,6 +2039,73 @@ func TestGetCurrentAccount(t *testing.T) {
 	assert.Equal(t, "https://github.com/replicate", account.GithubURL)
 }
 
+func TestCurrentOwner(t *testing.T) {
+	var accountRequests int
+	var paths []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/account" {
+			accountRequests++
+			w.Write([]byte(`{"type": "organization", "username": "acme", "name": "Acme, Inc."}`))
+			return
+		}
+
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/models":
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "acme", body["owner"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"owner": "acme", "name": "hotdog"}`))
+		case "/deployments":
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "acme/hotdog", body["model"])
+			w.Write([]byte(`{"owner": "acme", "name": "hotdog"}`))
+		default:
+			w.Write([]byte(`{"owner": "acme", "name": "hotdog"}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	account, err := client.CurrentOwner(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", account.Username)
+	assert.True(t, account.IsOrganization())
+
+	_, err = client.CreateModel(ctx, "", "hotdog", replicate.CreateModelOptions{Visibility: "private", Hardware: "cpu"})
+	require.NoError(t, err)
+	_, err = client.CreateDeployment(ctx, replicate.CreateDeploymentOptions{Name: "hotdog", Model: "hotdog", Version: "v1", Hardware: "cpu"})
+	require.NoError(t, err)
+	// Calls on existing models and deployments don't default the owner.
+	_, err = client.GetDeployment(ctx, "", "hotdog")
+	require.NoError(t, err)
+	require.NoError(t, client.DeleteDeployment(ctx, "", "hotdog"))
+	require.NoError(t, client.DeleteModel(ctx, "", "hotdog"))
+
+	assert.Equal(t, 1, accountRequests, "owner should be cached")
+	assert.Equal(t, []string{
+		"POST /models",
+		"POST /deployments",
+		"GET /deployments//hotdog",
+		"DELETE /deployments//hotdog",
+		"DELETE /models//hotdog",
+	}, paths)
+
+	derived, err := client.With(replicate.WithToken("other-token"))
+	require.NoError(t, err)
+	_, err = derived.CurrentOwner(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, accountRequests, "owner should be cached per token")
+}
+
 func TestGetDefaultWebhookSecret(t *testing.T) {
 	// This is a test secret and should not be used in production
 	testSecret := replicate.WebhookSigningSecret{
@@ -0,0 +1,97 @@
+package replicate
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is a source of time for the client's waits: polling in Wait,
+// delays between retries, auto-throttling, and stream reconnects and stall
+// detection. The default uses the time package.
+//
+// Substitute a simulated clock, such as replicatetest.FakeClock, to make
+// tests of long waits run instantly and deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer returns a timer that sends on its channel after d.
+	NewTimer(d time.Duration) Timer
+
+	// NewTicker returns a ticker that sends on its channel every d.
+	NewTicker(d time.Duration) Ticker
+
+	// AfterFunc calls f in its own goroutine after d. The returned timer's
+	// channel is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock. Its methods behave like those of
+// time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a ticker created by a Clock. Its methods behave like those of
+// time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock sets the clock the client uses to wait. It's intended for
+// tests.
+func WithClock(clock Clock) ClientOption {
+	return func(o *clientOptions) error {
+		o.clock = clock
+		return nil
+	}
+}
+
+// realClock is a Clock backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+func (r *Client) clock() Clock {
+	if r.options.clock == nil {
+		return realClock{}
+	}
+	return r.options.clock
+}
+
+// sleep waits for d on the client's clock, returning early with the
+// context's error if it's done.
+func (r *Client) sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := r.clock().NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
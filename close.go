@@ -0,0 +1,194 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrClientClosed is matched by errors from calls made after Close, and by
+// background work that Close canceled.
+var ErrClientClosed = errors.New("client closed")
+
+const remoteCancelTimeout = 10 * time.Second
+
+// WithCancelPredictionsOnClose makes Close cancel the predictions that
+// background waiters and streams are still following when its context is
+// done, so they don't keep running and billing after the process exits.
+func WithCancelPredictionsOnClose() ClientOption {
+	return func(o *clientOptions) error {
+		o.cancelOnClose = true
+		return nil
+	}
+}
+
+// lifecycle tracks the background work started by a client and its derived
+// clients, so Close can wait for it or cancel it.
+type lifecycle struct {
+	mu       sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	active   int
+	idle     chan struct{}
+	closers  []io.Closer
+	closed   bool
+	shutdown sync.Once
+}
+
+// contextLocked returns a context that's canceled when Close gives up
+// waiting for background work.
+func (l *lifecycle) contextLocked() context.Context {
+	if l.ctx == nil {
+		l.ctx, l.cancel = context.WithCancelCause(context.Background())
+	}
+	return l.ctx
+}
+
+func (l *lifecycle) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active--
+	if l.active == 0 && l.idle != nil {
+		close(l.idle)
+		l.idle = nil
+	}
+}
+
+// wait blocks until no background work is running, or ctx is done.
+func (l *lifecycle) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.active == 0 {
+			l.mu.Unlock()
+			return nil
+		}
+		if l.idle == nil {
+			l.idle = make(chan struct{})
+		}
+		idle := l.idle
+		l.mu.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *lifecycle) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// background registers a unit of background work. The returned context is
+// canceled when ctx is, or when Close stops waiting for background work.
+// done must be called when the work finishes.
+func (r *Client) background(ctx context.Context) (context.Context, func()) {
+	l := &r.state.lifecycle
+	l.mu.Lock()
+	shutdown := l.contextLocked()
+	l.active++
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(shutdown, func() { cancel(ErrClientClosed) })
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			stop()
+			cancel(nil)
+			l.release()
+		})
+	}
+}
+
+// contextError returns the error for a context that's done, which matches
+// ErrClientClosed if Close canceled it.
+func contextError(ctx context.Context) error {
+	err := classifyError(ctx.Err())
+	if errors.Is(context.Cause(ctx), ErrClientClosed) {
+		return fmt.Errorf("%w: %w", ErrClientClosed, err)
+	}
+	return err
+}
+
+// cancelOnClose cancels the prediction if Close canceled ctx and the client
+// was created with WithCancelPredictionsOnClose.
+func (r *Client) cancelOnClose(ctx context.Context, id string) {
+	if !r.options.cancelOnClose || id == "" || !errors.Is(context.Cause(ctx), ErrClientClosed) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), remoteCancelTimeout)
+	defer cancel()
+	if _, err := r.CancelPrediction(ctx, id); err != nil {
+		r.log(ctx, slog.LevelWarn, "failed to cancel replicate prediction on close",
+			slog.String("prediction_id", id), slog.String("error", redactSecrets(err.Error())))
+	}
+}
+
+// CloseOnShutdown registers c to be closed by Close once background work has
+// stopped, for example a WebhookBridge whose waiters should be released.
+func (r *Client) CloseOnShutdown(c io.Closer) {
+	l := &r.state.lifecycle
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closers = append(l.closers, c)
+}
+
+// Close shuts the client down gracefully.
+//
+// Close waits for background work to finish: waiters started by WaitAsync,
+// streams, futures from Submit, batches, and supervised jobs. If ctx is done
+// first, the remaining work is canceled and fails with ErrClientClosed, and
+// with WithCancelPredictionsOnClose the predictions it was following are
+// canceled. Closers registered with CloseOnShutdown are then closed.
+//
+// Give ctx a deadline a few seconds shorter than the shutdown grace period,
+// for example Kubernetes' terminationGracePeriodSeconds, so that canceling
+// work has time to finish. Calls made after Close fail with ErrClientClosed.
+// Clients derived with With share background work with their parent, so
+// closing either one closes both.
+func (r *Client) Close(ctx context.Context) error {
+	l := &r.state.lifecycle
+
+	err := l.wait(ctx)
+	if err != nil {
+		l.mu.Lock()
+		l.contextLocked()
+		cancel := l.cancel
+		l.mu.Unlock()
+
+		cancel(ErrClientClosed)
+		_ = l.wait(context.Background())
+		err = fmt.Errorf("background work didn't finish before close: %w", err)
+	}
+
+	var errs []error
+	l.shutdown.Do(func() {
+		l.mu.Lock()
+		l.closed = true
+		l.contextLocked()
+		cancel := l.cancel
+		closers := l.closers
+		l.closers = nil
+		l.mu.Unlock()
+
+		cancel(ErrClientClosed)
+		for _, c := range closers {
+			if cerr := c.Close(); cerr != nil {
+				errs = append(errs, fmt.Errorf("failed to close %T: %w", c, cerr))
+			}
+		}
+	})
+
+	return errors.Join(append([]error{err}, errs...)...)
+}
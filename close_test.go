@@ -0,0 +1,95 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestCloseDrainsBackgroundWork(t *testing.T) {
+	var polls atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := replicate.Processing
+		if polls.Add(1) >= 3 {
+			status = replicate.Succeeded
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: status})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	prediction := &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting}
+	predChan, errChan := client.WaitAsync(context.Background(), prediction, replicate.WithPollingInterval(10*time.Millisecond))
+	go func() {
+		for range predChan { //nolint:all
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, client.Close(ctx))
+
+	require.NoError(t, <-errChan)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+
+	_, err = client.GetPrediction(context.Background(), prediction.ID)
+	assert.ErrorIs(t, err, replicate.ErrClientClosed)
+}
+
+func TestCloseCancelsBackgroundWork(t *testing.T) {
+	var canceled atomic.Value
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			canceled.Store(r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Processing})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithCancelPredictionsOnClose(),
+	)
+	require.NoError(t, err)
+
+	bridge := replicate.NewWebhookBridge(replicate.WebhookSigningSecret{Key: "whsec_C2FVsBQIhrscChlQIMV+b5sSYspob7oD"})
+	client.CloseOnShutdown(bridge)
+	events := bridge.Await("ufawqhfynnddngldkgtslldrkq")
+
+	prediction := &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting}
+	predChan, errChan := client.WaitAsync(context.Background(), prediction, replicate.WithPollingInterval(10*time.Millisecond))
+	go func() {
+		for range predChan { //nolint:all
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = client.Close(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	err = <-errChan
+	assert.ErrorIs(t, err, replicate.ErrClientClosed)
+	assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq/cancel", canceled.Load())
+
+	_, ok := <-events
+	assert.False(t, ok)
+
+	assert.NoError(t, client.Close(context.Background()))
+}
@@ -30,9 +30,8 @@ func (c *Collection) UnmarshalJSON(data []byte) error {
 }
 
 // ListCollections returns a list of all collections.
-func (r *Client) ListCollections(ctx context.Context) (*Page[Collection], error) {
-	response := &Page[Collection]{}
-	err := r.fetch(ctx, http.MethodGet, "/collections", nil, response)
+func (r *Client) ListCollections(ctx context.Context, opts ...ListOption) (*Page[Collection], error) {
+	response, err := listPage[Collection](ctx, r, "/collections", opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list collections: %w", err)
 	}
@@ -0,0 +1,128 @@
+package replicate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithoutCompression makes the client ask for uncompressed responses, such
+// as when a proxy mishandles compressed ones.
+//
+// By default, the client sends Accept-Encoding: gzip and decompresses
+// responses itself, whatever HTTP client it's given, which cuts the size of
+// large responses such as pages of predictions with long logs.
+func WithoutCompression() ClientOption {
+	return func(o *clientOptions) error {
+		o.disableCompression = true
+		return nil
+	}
+}
+
+// WithRequestCompression gzips JSON request bodies larger than threshold
+// bytes, such as prediction inputs with large data URLs, and sends them with
+// Content-Encoding: gzip. Only use it with an API or gateway that accepts
+// compressed request bodies.
+//
+// Bodies are compressed as they're sent, so logs, debug dumps, and audit
+// records still show the JSON.
+func WithRequestCompression(threshold int) ClientOption {
+	return func(o *clientOptions) error {
+		if threshold < 0 {
+			return fmt.Errorf("request compression threshold must not be negative, got %d", threshold)
+		}
+		o.requestCompressionThreshold = threshold
+		o.requestCompression = true
+		return nil
+	}
+}
+
+// compressRequest returns a copy of request with its body gzipped, if
+// request compression is enabled and the body is JSON over the threshold.
+// Otherwise it returns request.
+func (r *Client) compressRequest(request *http.Request) *http.Request {
+	if !r.options.requestCompression || request.GetBody == nil ||
+		request.ContentLength <= int64(r.options.requestCompressionThreshold) ||
+		request.Header.Get("Content-Encoding") != "" ||
+		!strings.HasPrefix(request.Header.Get("Content-Type"), "application/json") {
+		return request
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return request
+	}
+	defer body.Close()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, body); err != nil {
+		return request
+	}
+	if err := zw.Close(); err != nil {
+		return request
+	}
+	compressed := buf.Bytes()
+
+	c := request.Clone(request.Context())
+	c.Body = io.NopCloser(bytes.NewReader(compressed))
+	c.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	c.ContentLength = int64(len(compressed))
+	c.Header.Set("Content-Encoding", "gzip")
+	return c
+}
+
+// setAcceptEncoding asks for a compressed response, unless compression is
+// disabled. Setting the header stops http.Transport from decompressing the
+// response itself, so it's always done by decompressResponse.
+func (r *Client) setAcceptEncoding(request *http.Request) {
+	if r.options.disableCompression {
+		request.Header.Set("Accept-Encoding", "identity")
+		return
+	}
+	request.Header.Set("Accept-Encoding", "gzip")
+}
+
+// decompressResponse replaces the body of a gzip-encoded response with its
+// decompressed content.
+func decompressResponse(response *http.Response) {
+	if response == nil || !strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+
+	response.Body = &gzipBody{body: response.Body}
+	response.Header.Del("Content-Encoding")
+	response.Header.Del("Content-Length")
+	response.ContentLength = -1
+	response.Uncompressed = true
+}
+
+// gzipBody decompresses a response body. The gzip reader is created on the
+// first read, since creating it reads the gzip header.
+type gzipBody struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+	err  error
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.zr == nil {
+		b.zr, b.err = gzip.NewReader(b.body)
+		if b.err != nil {
+			return 0, b.err
+		}
+	}
+	return b.zr.Read(p)
+}
+
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,111 @@
+package replicate_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestCompression(t *testing.T) {
+	logs := strings.Repeat("step 1/100\n", 1000)
+	var acceptEncoding atomic.Value
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding.Store(r.Header.Get("Accept-Encoding"))
+		prediction := &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Succeeded, Logs: &logs}
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			json.NewEncoder(w).Encode(prediction)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		defer zw.Close()
+		json.NewEncoder(zw).Encode(prediction)
+	}))
+	defer mockServer.Close()
+
+	// A transport that doesn't decompress responses itself.
+	transport := &http.Transport{DisableCompression: true}
+	defer transport.CloseIdleConnections()
+
+	for _, disabled := range []bool{false, true} {
+		opts := []replicate.ClientOption{
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(mockServer.URL),
+			replicate.WithHTTPClient(&http.Client{Transport: transport}),
+		}
+		if disabled {
+			opts = append(opts, replicate.WithoutCompression())
+		}
+		client, err := replicate.NewClient(opts...)
+		require.NoError(t, err)
+
+		prediction, err := client.GetPrediction(context.Background(), "ufawqhfynnddngldkgtslldrkq")
+		require.NoError(t, err)
+		require.NotNil(t, prediction.Logs)
+		assert.Equal(t, logs, *prediction.Logs)
+
+		if disabled {
+			assert.Equal(t, "identity", acceptEncoding.Load())
+		} else {
+			assert.Equal(t, "gzip", acceptEncoding.Load())
+		}
+	}
+}
+
+func TestRequestCompression(t *testing.T) {
+	var encodings []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = zr
+		}
+		var data map[string]any
+		require.NoError(t, json.NewDecoder(body).Decode(&data))
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(&replicate.Prediction{
+			ID:     "ufawqhfynnddngldkgtslldrkq",
+			Status: replicate.Starting,
+			Input:  data["input"].(map[string]any),
+		})
+	}))
+	defer mockServer.Close()
+
+	var debug bytes.Buffer
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRequestCompression(1024),
+		replicate.WithDebug(&debug),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	image := "data:image/png;base64," + strings.Repeat("A", 4096)
+	for _, input := range []replicate.PredictionInput{{"prompt": "small"}, {"image": image}} {
+		prediction, err := client.CreatePrediction(ctx, "owner/model", input, nil, false)
+		require.NoError(t, err)
+		assert.Equal(t, input, prediction.Input)
+	}
+
+	assert.Equal(t, []string{"", "gzip"}, encodings)
+	assert.Contains(t, debug.String(), `{"input":{"image":"data:image/png;base64,[REDACTED]`)
+
+	_, err = replicate.NewClient(replicate.WithToken("test-token"), replicate.WithRequestCompression(-1))
+	assert.ErrorContains(t, err, "must not be negative")
+}
@@ -0,0 +1,129 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+// TestConcurrentUse exercises the request paths from many goroutines at
+// once. Run it with -race.
+func TestConcurrentUse(t *testing.T) {
+	var created atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "600")
+		w.Header().Set("X-RateLimit-Remaining", "500")
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/predictions":
+			id := fmt.Sprintf("prediction-%d", created.Add(1))
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Starting})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/predictions/"):
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			predictTime := 1.0
+			json.NewEncoder(w).Encode(&replicate.Prediction{
+				ID:      id,
+				Status:  replicate.Succeeded,
+				Model:   "owner/model",
+				Output:  "done",
+				Metrics: &replicate.PredictionMetrics{PredictTime: &predictTime},
+			})
+		case r.URL.Path == "/account":
+			json.NewEncoder(w).Encode(&replicate.Account{Type: "user", Username: "alice"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithAutoThrottle(10),
+		replicate.WithMaxConcurrentRequests(4),
+	)
+	require.NoError(t, err)
+
+	const workers = 16
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			prediction, err := client.CreatePrediction(ctx, "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", replicate.PredictionInput{"i": i}, nil, false)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, client.Wait(ctx, prediction, replicate.WithPollingInterval(time.Millisecond)))
+
+			derived, err := client.With(replicate.WithHeaders(http.Header{"X-Worker": {fmt.Sprint(i)}}))
+			if assert.NoError(t, err) {
+				assert.NoError(t, derived.Verify(ctx))
+			}
+
+			client.LastRateLimit()
+			client.Stats()
+			client.Latency()
+			client.Usage()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(workers), created.Load())
+	assert.Equal(t, 500, client.LastRateLimit().Remaining)
+	assert.Len(t, client.Usage().ByModel, 1)
+}
+
+// TestOptionsCopyArguments checks that changing the values passed to options
+// after creating a client doesn't change the client.
+func TestOptionsCopyArguments(t *testing.T) {
+	type request struct {
+		header string
+		query  string
+		events []any
+	}
+	var requests []request
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		events, _ := body["webhook_events_filter"].([]any)
+		requests = append(requests, request{r.Header.Get("X-Team"), r.URL.Query().Get("team"), events})
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "p1", Status: replicate.Starting})
+	}))
+	defer mockServer.Close()
+
+	headers := http.Header{"X-Team": {"red"}}
+	query := url.Values{"team": {"red"}}
+	webhook := replicate.Webhook{URL: "https://example.com/webhook", Events: []replicate.WebhookEventType{replicate.WebhookEventCompleted}}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithHeaders(headers),
+		replicate.WithQueryParams(query),
+		replicate.WithDefaultWebhook(webhook),
+	)
+	require.NoError(t, err)
+
+	headers["X-Team"][0] = "blue"
+	query.Set("team", "blue")
+	webhook.Events[0] = replicate.WebhookEventStart
+
+	_, err = client.CreatePrediction(context.Background(), "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", replicate.PredictionInput{}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, []request{{"red", "red", []any{"completed"}}}, requests)
+}
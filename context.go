@@ -0,0 +1,212 @@
+package replicate
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// idempotencyKeyHeader is sent with requests made with a context returned
+	// by WithIdempotencyKey.
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// correlationIDHeader is sent with requests made with a context returned
+	// by WithCorrelationID.
+	correlationIDHeader = "X-Correlation-ID"
+)
+
+type (
+	idempotencyKeyContextKey struct{}
+	correlationIDContextKey  struct{}
+	callMetadataContextKey   struct{}
+	requestHeadersContextKey struct{}
+	requestQueryContextKey   struct{}
+	requestBaseURLContextKey struct{}
+	priorityContextKey       struct{}
+	tenantContextKey         struct{}
+)
+
+// WithIdempotencyKey returns a context that sends key as the Idempotency-Key
+// header on requests made with it.
+//
+// Requests that create resources, like CreatePrediction, are only retried
+// after server errors or network failures when they carry an idempotency key,
+// since retrying them could otherwise create duplicates.
+//
+// The key identifies a single operation: every mutating request made with the
+// returned context carries it, so the API may treat a second CreatePrediction
+// sharing the context as a duplicate of the first. Derive a new context with
+// its own key for each resource to create.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// WithCorrelationID returns a context that sends id in the X-Correlation-ID
+// header on requests made with it, so they can be matched with the caller's
+// own logs.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, id)
+}
+
+// WithRequestHeaders returns a context that adds h to the requests made with
+// it, such as a traceparent header or the caller's own correlation headers.
+//
+// Headers from enclosing contexts are kept, unless h sets the same key.
+// Headers the client sets itself, like Authorization, take precedence.
+func WithRequestHeaders(ctx context.Context, h http.Header) context.Context {
+	merged := requestHeaders(ctx).Clone()
+	if merged == nil {
+		merged = make(http.Header, len(h))
+	}
+	for key, values := range h {
+		merged[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+	return context.WithValue(ctx, requestHeadersContextKey{}, merged)
+}
+
+func requestHeaders(ctx context.Context) http.Header {
+	h, _ := ctx.Value(requestHeadersContextKey{}).(http.Header)
+	return h
+}
+
+// WithRequestQuery returns a context that adds q to the query of the API
+// requests made with it, such as routing parameters required by a gateway.
+//
+// Parameters from enclosing contexts are kept, unless q sets the same key.
+// Parameters the client sets itself, like pagination cursors, take
+// precedence.
+func WithRequestQuery(ctx context.Context, q url.Values) context.Context {
+	merged := mergeValues(requestQuery(ctx), q)
+	return context.WithValue(ctx, requestQueryContextKey{}, merged)
+}
+
+func requestQuery(ctx context.Context) url.Values {
+	q, _ := ctx.Value(requestQueryContextKey{}).(url.Values)
+	return q
+}
+
+// WithRequestBaseURL returns a context that sends the requests made with it
+// to baseURL instead of the client's base URL, such as a regional proxy for
+// some calls. Calls fail if baseURL isn't an absolute http or https URL.
+//
+// Requests with their own base URL aren't subject to failover.
+func WithRequestBaseURL(ctx context.Context, baseURL string) context.Context {
+	return context.WithValue(ctx, requestBaseURLContextKey{}, baseURL)
+}
+
+func requestBaseURL(ctx context.Context) (string, bool) {
+	baseURL, ok := ctx.Value(requestBaseURLContextKey{}).(string)
+	return baseURL, ok
+}
+
+// WithPriority returns a context that gives the work a Scheduler runs with
+// it priority p. Work without a priority has PriorityNormal.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityContextKey{}, p)
+}
+
+func requestPriority(ctx context.Context) Priority {
+	p, ok := ctx.Value(priorityContextKey{}).(Priority)
+	if !ok {
+		return PriorityNormal
+	}
+	return p
+}
+
+// WithTenant returns a context that attributes the predictions created or
+// completed with it to tenant, such as a customer of a SaaS platform. The
+// usage ledger breaks down usage by tenant in UsageReport.ByTenant, and
+// UsageRecord.Tenant carries it to exporters and UsageMetrics.
+//
+// A prediction created with a tenant stays attributed to it when it's
+// waited for or fetched with a context that has none.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+func requestTenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+// CallMetadata describes the HTTP exchange behind an API call.
+type CallMetadata struct {
+	// RequestID is the ID the API assigned to the last request, if any.
+	// Include it when contacting support about a call.
+	RequestID string
+
+	// StatusCode is the status code of the last response, or 0 if none was
+	// received.
+	StatusCode int
+
+	// Header holds the headers of the last response.
+	Header http.Header
+
+	// Attempts is the number of requests sent, including retries.
+	Attempts int
+
+	// Duration is the total time spent on the call.
+	Duration time.Duration
+}
+
+// WithCallMetadata returns a context that records metadata about the calls
+// made with it into md. When the context is used for several calls, md
+// describes the most recent one to finish; calls may share the context
+// concurrently, but md should only be read once they have returned.
+func WithCallMetadata(ctx context.Context, md *CallMetadata) context.Context {
+	return context.WithValue(ctx, callMetadataContextKey{}, md)
+}
+
+// setRequestContextHeaders adds the headers carried by the request context.
+func setRequestContextHeaders(ctx context.Context, request *http.Request) {
+	if key, ok := ctx.Value(idempotencyKeyContextKey{}).(string); ok && key != "" {
+		request.Header.Set(idempotencyKeyHeader, key)
+	}
+	if id, ok := ctx.Value(correlationIDContextKey{}).(string); ok && id != "" {
+		request.Header.Set(correlationIDHeader, id)
+	}
+	setRequestHeaders(ctx, request)
+}
+
+// setRequestHeaders adds the headers set with WithRequestHeaders, without
+// replacing headers already set on the request.
+func setRequestHeaders(ctx context.Context, request *http.Request) {
+	for key, values := range requestHeaders(ctx) {
+		if _, ok := request.Header[key]; ok {
+			continue
+		}
+		request.Header[key] = append([]string(nil), values...)
+	}
+}
+
+// recordCallMetadata fills in the CallMetadata attached to ctx, if any.
+func recordCallMetadata(ctx context.Context, response *http.Response, attempts int, start time.Time) {
+	md, ok := ctx.Value(callMetadataContextKey{}).(*CallMetadata)
+	if !ok || md == nil {
+		return
+	}
+
+	metadata := CallMetadata{
+		Attempts: attempts,
+		Duration: time.Since(start),
+	}
+	if response != nil {
+		metadata.RequestID = response.Header.Get(requestIDHeader)
+		metadata.StatusCode = response.StatusCode
+		metadata.Header = response.Header
+	}
+	setCallMetadata(md, metadata)
+}
+
+// callMetadataMu guards writes to the CallMetadata attached to contexts,
+// which concurrent calls sharing one context would otherwise race on.
+var callMetadataMu sync.Mutex
+
+func setCallMetadata(md *CallMetadata, metadata CallMetadata) {
+	callMetadataMu.Lock()
+	defer callMetadataMu.Unlock()
+	*md = metadata
+}
@@ -0,0 +1,182 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestCallMetadata(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, "corr-123", r.Header.Get("X-Correlation-ID"))
+		w.Header().Set("X-Request-ID", "req-456")
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var md replicate.CallMetadata
+	ctx = replicate.WithCallMetadata(ctx, &md)
+	ctx = replicate.WithCorrelationID(ctx, "corr-123")
+
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+
+	assert.Equal(t, "req-456", md.RequestID)
+	assert.Equal(t, http.StatusOK, md.StatusCode)
+	assert.Equal(t, 2, md.Attempts)
+	assert.Greater(t, md.Duration, time.Duration(0))
+}
+
+func TestCallMetadataSharedContext(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "req-456")
+		if r.URL.Path == "/account" {
+			json.NewEncoder(w).Encode(&replicate.Account{Username: "acme"})
+			return
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var md replicate.CallMetadata
+	ctx = replicate.WithCallMetadata(ctx, &md)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := client.GetLimits(ctx)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, "req-456", md.RequestID)
+	assert.Equal(t, http.StatusOK, md.StatusCode)
+}
+
+func TestRequestIDInErrors(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Request-ID", "req-456")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail": "Not found."}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var md replicate.CallMetadata
+	_, err = client.GetPrediction(replicate.WithCallMetadata(ctx, &md), "ufawqhfynnddngldkgtslldrkq")
+	assert.ErrorContains(t, err, "Not found. [request ID: req-456]")
+	assert.Equal(t, "req-456", md.RequestID)
+	assert.Equal(t, http.StatusNotFound, md.StatusCode)
+}
+
+func TestWithRequestHeaders(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", r.Header.Get("Traceparent"))
+		assert.Equal(t, "inner", r.Header.Get("X-Team"))
+		assert.Equal(t, "outer", r.Header.Get("X-Service"))
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx := replicate.WithRequestHeaders(context.Background(), http.Header{
+		"traceparent": {"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+		"X-Team":      {"outer"},
+		"X-Service":   {"outer"},
+	})
+	ctx = replicate.WithRequestHeaders(ctx, http.Header{
+		"X-Team":        {"inner"},
+		"Authorization": {"Bearer other-token"},
+	})
+
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+}
+
+func TestWithRequestQuery(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "inner", query.Get("region"))
+		assert.Equal(t, "outer", query.Get("tenant"))
+		assert.Equal(t, "client", query.Get("route"))
+		assert.Equal(t, "abc", query.Get("cursor"))
+		json.NewEncoder(w).Encode(&replicate.Page[replicate.Prediction]{})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithQueryParams(url.Values{"route": {"client"}, "region": {"client"}}),
+	)
+	require.NoError(t, err)
+
+	ctx := replicate.WithRequestQuery(context.Background(), url.Values{
+		"region": {"outer"},
+		"tenant": {"outer"},
+	})
+	ctx = replicate.WithRequestQuery(ctx, url.Values{
+		"region": {"inner"},
+		"cursor": {"other"},
+	})
+
+	_, err = client.ListPredictions(ctx, replicate.WithCursor("abc"))
+	require.NoError(t, err)
+}
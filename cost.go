@@ -0,0 +1,179 @@
+package replicate
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// ErrNoRate is returned by EstimateCost when the rate table has no price
+// for a prediction.
+var ErrNoRate = errors.New("no rate for prediction")
+
+// TokenRate is the price of a model billed by token, in dollars per
+// million tokens.
+type TokenRate struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// RateTable holds the prices EstimateCost computes costs with. Models are
+// keyed by "owner/name".
+type RateTable struct {
+	// Tokens is the price of models billed by input and output tokens,
+	// such as official language models.
+	Tokens map[string]TokenRate
+
+	// Outputs is the price per output of models billed by output, such as
+	// official image models.
+	Outputs map[string]float64
+
+	// Hardware is the price per second of each hardware SKU, for models
+	// billed by the time they run.
+	Hardware map[string]float64
+
+	// ModelHardware is the hardware SKU each model billed by time runs on.
+	ModelHardware map[string]string
+
+	// DefaultHardware is the hardware SKU assumed for models billed by
+	// time that aren't in ModelHardware. If empty, their cost can't be
+	// estimated.
+	DefaultHardware string
+}
+
+// DefaultRateTable returns a copy of the bundled rate table, which has the
+// public prices of Replicate's hardware and of popular official models at
+// the time of release. Prices change, so override the entries that matter
+// to you, and set ModelHardware for the models you run on dedicated
+// hardware.
+func DefaultRateTable() RateTable {
+	return RateTable{
+		Tokens: map[string]TokenRate{
+			"meta/meta-llama-3-8b-instruct":        {InputPerMillion: 0.05, OutputPerMillion: 0.25},
+			"meta/meta-llama-3-70b-instruct":       {InputPerMillion: 0.65, OutputPerMillion: 2.75},
+			"meta/meta-llama-3.1-405b-instruct":    {InputPerMillion: 9.5, OutputPerMillion: 9.5},
+			"meta/llama-2-70b-chat":                {InputPerMillion: 0.65, OutputPerMillion: 2.75},
+			"mistralai/mixtral-8x7b-instruct-v0.1": {InputPerMillion: 0.3, OutputPerMillion: 1},
+			"mistralai/mistral-7b-instruct-v0.2":   {InputPerMillion: 0.05, OutputPerMillion: 0.25},
+		},
+		Outputs: map[string]float64{
+			"black-forest-labs/flux-schnell": 0.003,
+			"black-forest-labs/flux-dev":     0.025,
+			"black-forest-labs/flux-pro":     0.055,
+			"black-forest-labs/flux-1.1-pro": 0.04,
+		},
+		Hardware: map[string]float64{
+			"cpu":               0.0001,
+			"gpu-t4":            0.000225,
+			"gpu-a40-small":     0.000575,
+			"gpu-a40-large":     0.000725,
+			"gpu-a40-large-2x":  0.00145,
+			"gpu-a40-large-4x":  0.0029,
+			"gpu-a40-large-8x":  0.0058,
+			"gpu-l40s":          0.000975,
+			"gpu-l40s-2x":       0.00195,
+			"gpu-l40s-4x":       0.0039,
+			"gpu-l40s-8x":       0.0078,
+			"gpu-a100-large":    0.0014,
+			"gpu-a100-large-2x": 0.0028,
+			"gpu-a100-large-4x": 0.0056,
+			"gpu-a100-large-8x": 0.0112,
+			"gpu-h100":          0.001525,
+			"gpu-h100-2x":       0.00305,
+			"gpu-h100-4x":       0.0061,
+			"gpu-h100-8x":       0.0122,
+		},
+		ModelHardware: map[string]string{},
+	}
+}
+
+// WithRateTable sets the prices the client estimates costs with, for the
+// budget guard and UsageReport. The default is DefaultRateTable.
+func WithRateTable(rates RateTable) ClientOption {
+	return func(o *clientOptions) error {
+		rates = rates.Clone()
+		o.rateTable = &rates
+		return nil
+	}
+}
+
+// bundledRates is the default rate table. It must not be modified.
+var bundledRates = DefaultRateTable()
+
+// rateTable returns the prices the client estimates costs with.
+func (r *Client) rateTable() RateTable {
+	if r.options.rateTable != nil {
+		return *r.options.rateTable
+	}
+	return bundledRates
+}
+
+// Clone returns a copy of the table that can be changed without affecting
+// t.
+func (t RateTable) Clone() RateTable {
+	t.Tokens = maps.Clone(t.Tokens)
+	t.Outputs = maps.Clone(t.Outputs)
+	t.Hardware = maps.Clone(t.Hardware)
+	t.ModelHardware = maps.Clone(t.ModelHardware)
+	return t
+}
+
+// EstimateCost estimates the cost of a prediction in dollars, from its
+// metrics and the prices in rates.
+//
+// Models in rates.Tokens are priced by the token counts they report, and
+// models in rates.Outputs by the number of outputs. Others are priced by
+// their predict time and the price of their hardware. If rates has no price
+// for the prediction, the error wraps ErrNoRate.
+//
+// The estimate doesn't include setup time or the idle time of deployments,
+// and the actual charge may differ.
+func EstimateCost(p *Prediction, rates RateTable) (float64, error) {
+	if p == nil {
+		return 0, errors.New("prediction is nil")
+	}
+
+	model := p.Model
+	if rate, ok := rates.Tokens[model]; ok {
+		var input, output int
+		if p.Metrics != nil && p.Metrics.InputTokenCount != nil {
+			input = *p.Metrics.InputTokenCount
+		}
+		if p.Metrics != nil && p.Metrics.OutputTokenCount != nil {
+			output = *p.Metrics.OutputTokenCount
+		}
+		return (float64(input)*rate.InputPerMillion + float64(output)*rate.OutputPerMillion) / 1e6, nil
+	}
+
+	if price, ok := rates.Outputs[model]; ok {
+		return price * float64(countOutputs(p)), nil
+	}
+
+	hardware, ok := rates.ModelHardware[model]
+	if !ok {
+		hardware = rates.DefaultHardware
+	}
+	if hardware == "" {
+		return 0, fmt.Errorf("%w %s: unknown hardware for model %q", ErrNoRate, p.ID, model)
+	}
+	price, ok := rates.Hardware[hardware]
+	if !ok {
+		return 0, fmt.Errorf("%w %s: unknown price for hardware %q", ErrNoRate, p.ID, hardware)
+	}
+	if p.Metrics == nil || p.Metrics.PredictTime == nil {
+		return 0, fmt.Errorf("prediction %s has no predict time", p.ID)
+	}
+	return price * *p.Metrics.PredictTime, nil
+}
+
+// countOutputs returns the number of outputs of a prediction, which is the
+// length of a list output, or one otherwise.
+func countOutputs(p *Prediction) int {
+	switch output := p.Output.(type) {
+	case nil:
+		return 0
+	case []any:
+		return len(output)
+	}
+	return 1
+}
@@ -0,0 +1,63 @@
+package replicate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestEstimateCost(t *testing.T) {
+	rates := replicate.DefaultRateTable()
+	rates.ModelHardware["stability-ai/sdxl"] = "gpu-a40-large"
+
+	inputTokens, outputTokens, predictTime := 1000, 500, 12.0
+	cost, err := replicate.EstimateCost(&replicate.Prediction{
+		ID:    "llm",
+		Model: "meta/meta-llama-3-70b-instruct",
+		Metrics: &replicate.PredictionMetrics{
+			InputTokenCount:  &inputTokens,
+			OutputTokenCount: &outputTokens,
+		},
+	}, rates)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.00065+0.001375, cost, 1e-12)
+
+	cost, err = replicate.EstimateCost(&replicate.Prediction{
+		ID:     "image",
+		Model:  "black-forest-labs/flux-schnell",
+		Output: []any{"https://example.com/0.png", "https://example.com/1.png"},
+	}, rates)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.006, cost, 1e-12)
+
+	cost, err = replicate.EstimateCost(&replicate.Prediction{
+		ID:      "sdxl",
+		Model:   "stability-ai/sdxl",
+		Metrics: &replicate.PredictionMetrics{PredictTime: &predictTime},
+	}, rates)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.0087, cost, 1e-12)
+
+	_, err = replicate.EstimateCost(&replicate.Prediction{
+		ID:      "custom",
+		Model:   "acme/custom",
+		Metrics: &replicate.PredictionMetrics{PredictTime: &predictTime},
+	}, rates)
+	assert.ErrorIs(t, err, replicate.ErrNoRate)
+
+	rates.DefaultHardware = "gpu-t4"
+	cost, err = replicate.EstimateCost(&replicate.Prediction{
+		ID:      "custom",
+		Model:   "acme/custom",
+		Metrics: &replicate.PredictionMetrics{PredictTime: &predictTime},
+	}, rates)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.0027, cost, 1e-12)
+
+	// The bundled table isn't changed by overrides.
+	_, ok := replicate.DefaultRateTable().ModelHardware["stability-ai/sdxl"]
+	assert.False(t, ok)
+}
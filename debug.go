@@ -0,0 +1,130 @@
+package replicate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDebugBodyLength limits how much of each body is written by WithDebug.
+const maxDebugBodyLength = 4096
+
+// debugWriter serializes dumps from concurrent requests.
+type debugWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// WithDebug writes a dump of each request the client makes, and the response
+// it receives, to w.
+//
+// The API token, webhook signing secrets, cookies, and data URL payloads
+// are redacted, and bodies longer than 4 KiB are truncated. Response bodies
+// are dumped before they're decoded, so this is useful for troubleshooting
+// responses the client rejects.
+func WithDebug(w io.Writer) ClientOption {
+	return func(o *clientOptions) error {
+		if w == nil {
+			o.debug = nil
+			return nil
+		}
+		o.debug = &debugWriter{w: w}
+		return nil
+	}
+}
+
+func (r *Client) dumpRequest(request *http.Request) {
+	if r.options.debug == nil {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--> %s %s\n", request.Method, r.redact(request.URL.String()))
+	r.writeDebugHeaders(&b, request.Header)
+
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(body, maxDebugBodyLength+1))
+			body.Close()
+			r.writeDebugBody(&b, data)
+		}
+	}
+
+	r.writeDebug(b.String())
+}
+
+// dumpResponse writes the response headers and the start of its body. The
+// body is put back so it can still be read in full.
+func (r *Client) dumpResponse(request *http.Request, response *http.Response, err error, duration time.Duration) {
+	if r.options.debug == nil {
+		return
+	}
+
+	var b strings.Builder
+	if err != nil || response == nil {
+		fmt.Fprintf(&b, "<-- %s %s failed (%s): %s\n\n", request.Method, r.redact(request.URL.String()), duration, r.redact(fmt.Sprint(err)))
+		r.writeDebug(b.String())
+		return
+	}
+
+	fmt.Fprintf(&b, "<-- %s %s %s (%s)\n", response.Status, request.Method, r.redact(request.URL.String()), duration)
+	r.writeDebugHeaders(&b, response.Header)
+
+	if response.Body != nil && response.Body != http.NoBody {
+		data, _ := io.ReadAll(io.LimitReader(response.Body, maxDebugBodyLength+1))
+		response.Body = &replayedBody{
+			Reader: io.MultiReader(bytes.NewReader(data), response.Body),
+			Closer: response.Body,
+		}
+		r.writeDebugBody(&b, data)
+	}
+
+	r.writeDebug(b.String())
+}
+
+func (r *Client) writeDebugHeaders(b *strings.Builder, header http.Header) {
+	header = r.redactHeader(header)
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range header[key] {
+			fmt.Fprintf(b, "%s: %s\n", key, value)
+		}
+	}
+}
+
+func (r *Client) writeDebugBody(b *strings.Builder, data []byte) {
+	truncated := len(data) > maxDebugBodyLength
+	if truncated {
+		data = data[:maxDebugBodyLength]
+	}
+
+	b.WriteString("\n")
+	b.WriteString(r.redact(string(data)))
+	if truncated {
+		b.WriteString("... (truncated)")
+	}
+	b.WriteString("\n")
+}
+
+func (r *Client) writeDebug(s string) {
+	d := r.options.debug
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, _ = io.WriteString(d.w, s+"\n")
+}
+
+// replayedBody is a response body whose start has already been read.
+type replayedBody struct {
+	io.Reader
+	io.Closer
+}
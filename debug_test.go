@@ -0,0 +1,75 @@
+package replicate_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestWithDebug(t *testing.T) {
+	longOutput := strings.Repeat("a", 5000)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": "ufawqhfynnddngldkgtslldrkq", "status": "starting", "logs": "` + longOutput + `"}`))
+	}))
+	defer mockServer.Close()
+
+	var buf bytes.Buffer
+	client, err := replicate.NewClient(
+		replicate.WithToken("secret-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithDebug(&buf),
+	)
+	require.NoError(t, err)
+
+	input := replicate.PredictionInput{"image": "data:image/png;base64,iVBORw0KGgo="}
+	prediction, err := client.CreatePrediction(context.Background(), "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	require.NotNil(t, prediction.Logs)
+	assert.Len(t, *prediction.Logs, len(longOutput), "the response body is still read in full")
+
+	dump := buf.String()
+	assert.Contains(t, dump, "--> POST "+mockServer.URL+"/predictions")
+	assert.Contains(t, dump, "Authorization: Bearer [REDACTED]")
+	assert.Contains(t, dump, `"image":"data:image/png;base64,[REDACTED]"`)
+	assert.Contains(t, dump, "<-- 201 Created POST")
+	assert.Contains(t, dump, "... (truncated)")
+	assert.NotContains(t, dump, "secret-token")
+	assert.NotContains(t, dump, "iVBORw0KGgo")
+}
+
+func TestWithDebugRedactsWebhookSecret(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Set-Cookie", "session=abc123")
+		w.Write([]byte(`{"key": "whsec_5WbX5kEWLlfzsGNjH64I8lOOqUB6e8FH"}`))
+	}))
+	defer mockServer.Close()
+
+	var buf bytes.Buffer
+	client, err := replicate.NewClient(
+		replicate.WithToken("secret-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithDebug(&buf),
+	)
+	require.NoError(t, err)
+
+	secret, err := client.GetDefaultWebhookSecret(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "whsec_5WbX5kEWLlfzsGNjH64I8lOOqUB6e8FH", secret.Key)
+
+	dump := buf.String()
+	assert.Contains(t, dump, `"key": "whsec_[REDACTED]"`)
+	assert.Contains(t, dump, "Set-Cookie: [REDACTED]")
+	assert.NotContains(t, dump, "5WbX5kEWLlfzsGNjH64I8lOOqUB6e8FH")
+	assert.NotContains(t, dump, "abc123")
+}
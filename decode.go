@@ -0,0 +1,148 @@
+package replicate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDecodeSnippetLength limits the body excerpt included in a DecodeError.
+const maxDecodeSnippetLength = 256
+
+// DecodeError is returned when a successful response can't be decoded.
+type DecodeError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// RequestID is the ID the API assigned to the request, if any.
+	RequestID string
+
+	// ContentType is the content type of the response.
+	ContentType string
+
+	// Path is the dotted path of the field that failed to decode, if known.
+	Path string
+
+	// Offset is the byte offset in the body where decoding failed, or -1
+	// if unknown.
+	Offset int64
+
+	// Snippet is an excerpt of the body around Offset.
+	Snippet string
+
+	// Err is the underlying decoding error.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to unmarshal response (status %d", e.StatusCode)
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, ", request ID %s", e.RequestID)
+	}
+	b.WriteString(")")
+	if e.Path != "" {
+		fmt.Fprintf(&b, " at %q", e.Path)
+	}
+	if e.Offset >= 0 {
+		fmt.Fprintf(&b, " at offset %d", e.Offset)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	if e.Snippet != "" {
+		fmt.Fprintf(&b, " [body: %s]", e.Snippet)
+	}
+	return b.String()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// bodyDecoder is implemented by response types that decode themselves from
+// the response body as it is read, instead of from a buffered copy.
+type bodyDecoder interface {
+	decodeBody(r io.Reader) error
+}
+
+// decodeResponse unmarshals a response body into out. Failures, including
+// panics in custom unmarshalers, are returned as a *DecodeError.
+func decodeResponse(resp *http.Response, data []byte, out interface{}) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = newDecodeError(resp, data, fmt.Errorf("panic while decoding: %v", p))
+		}
+	}()
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return newDecodeError(resp, data, err)
+	}
+	return nil
+}
+
+// decodeResponseBody decodes a response body with decoder as it is read.
+func decodeResponseBody(resp *http.Response, decoder bodyDecoder) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = newDecodeError(resp, nil, fmt.Errorf("panic while decoding: %v", p))
+		}
+	}()
+
+	if err := decoder.decodeBody(resp.Body); err != nil {
+		return newDecodeError(resp, nil, err)
+	}
+	return nil
+}
+
+func newDecodeError(resp *http.Response, data []byte, err error) *DecodeError {
+	decodeErr := &DecodeError{Offset: -1, Err: err}
+	if resp != nil {
+		decodeErr.StatusCode = resp.StatusCode
+		decodeErr.RequestID = resp.Header.Get(requestIDHeader)
+		decodeErr.ContentType = resp.Header.Get("Content-Type")
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		decodeErr.Offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		decodeErr.Offset = typeErr.Offset
+		decodeErr.Path = typeErr.Field
+	}
+
+	decodeErr.Snippet = snippetAround(data, decodeErr.Offset)
+	return decodeErr
+}
+
+// snippetAround returns an excerpt of data centered on offset, or the start
+// of data if offset is unknown.
+func snippetAround(data []byte, offset int64) string {
+	start := 0
+	if offset > 0 {
+		start = int(offset) - maxDecodeSnippetLength/2
+		if start < 0 {
+			start = 0
+		}
+		if start > len(data) {
+			start = len(data)
+		}
+	}
+	end := start + maxDecodeSnippetLength
+	if end > len(data) {
+		end = len(data)
+	}
+
+	snippet := strings.ToValidUTF8(string(data[start:end]), string(utf8.RuneError))
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(data) {
+		snippet += "..."
+	}
+	return snippet
+}
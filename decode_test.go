@@ -0,0 +1,64 @@
+package replicate_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		path    string
+		offset  int64
+		snippet string
+	}{
+		{
+			name:    "wrong type",
+			body:    `{"id": "ufawqhfynnddngldkgtslldrkq", "metrics": {"predict_time": "fast"}}`,
+			path:    "metrics.predict_time",
+			offset:  71,
+			snippet: `{"id": "ufawqhfynnddngldkgtslldrkq", "metrics": {"predict_time": "fast"}}`,
+		},
+		{
+			name:    "syntax error",
+			body:    `{"id": "ufawqhfynnddngldkgtslldrkq",`,
+			offset:  36,
+			snippet: `{"id": "ufawqhfynnddngldkgtslldrkq",`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-Request-ID", "req-123")
+				w.Write([]byte(tt.body))
+			}))
+			defer mockServer.Close()
+
+			client, err := replicate.NewClient(
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+			)
+			require.NoError(t, err)
+
+			_, err = client.GetPrediction(context.Background(), "ufawqhfynnddngldkgtslldrkq")
+
+			var decodeErr *replicate.DecodeError
+			require.ErrorAs(t, err, &decodeErr)
+			assert.Equal(t, http.StatusOK, decodeErr.StatusCode)
+			assert.Equal(t, "req-123", decodeErr.RequestID)
+			assert.Equal(t, tt.path, decodeErr.Path)
+			assert.Equal(t, tt.offset, decodeErr.Offset)
+			assert.Equal(t, tt.snippet, decodeErr.Snippet)
+			assert.Contains(t, err.Error(), "status 200, request ID req-123")
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package replicate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxDeduplicatedPredictions limits how many predictions are remembered for
+// deduplication.
+const maxDeduplicatedPredictions = 10000
+
+// WithPredictionDeduplication makes the client reuse predictions instead of
+// creating identical ones. A prediction created within window of an earlier
+// one with the same token, model, version, input, webhook, and stream
+// setting returns the earlier prediction: still running, or completed, as last seen
+// by the client. Identical predictions created at the same time share one
+// request.
+//
+// Failed and canceled predictions aren't reused. Inputs are compared after
+// files are uploaded, so predictions with file inputs are only deduplicated
+// when the files are given as URLs.
+func WithPredictionDeduplication(window time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if window <= 0 {
+			return fmt.Errorf("deduplication window must be positive, got %s", window)
+		}
+		o.dedupeWindow = window
+		return nil
+	}
+}
+
+// predictionDedupe remembers recently created predictions by a hash of
+// their request.
+type predictionDedupe struct {
+	mu      sync.Mutex
+	entries map[string]*dedupeEntry
+	byID    map[string]*dedupeEntry
+	order   []*dedupeEntry
+}
+
+type dedupeEntry struct {
+	key       string
+	createdAt time.Time
+
+	// ready is closed once the request that creates the prediction
+	// finishes. prediction is nil if it failed.
+	ready      chan struct{}
+	prediction *Prediction
+}
+
+// createPrediction sends a request that creates a prediction, once the
+// budget guard allows it, reusing an identical prediction if deduplication
+// is enabled.
+func (r *Client) createPrediction(req *http.Request, prediction *Prediction) error {
+	res, err := r.reserveBudget(req.Context())
+	if err != nil {
+		return err
+	}
+	err = r.createDeduplicated(req, prediction)
+	r.settleBudget(res, prediction, err)
+	return err
+}
+
+// createDeduplicated sends a request that creates a prediction, reusing an
+// identical prediction if deduplication is enabled.
+func (r *Client) createDeduplicated(req *http.Request, prediction *Prediction) error {
+	window := r.options.dedupeWindow
+	if window <= 0 {
+		return r.do(req, prediction)
+	}
+
+	key, err := dedupeKey(req)
+	if err != nil {
+		return err
+	}
+
+	d := &r.state.dedupe
+	for {
+		now := r.clock().Now()
+		d.mu.Lock()
+		entry, ok := d.entries[key]
+		if ok && now.Sub(entry.createdAt) >= window {
+			d.removeLocked(entry)
+			ok = false
+		}
+		if !ok {
+			break
+		}
+		d.mu.Unlock()
+
+		select {
+		case <-entry.ready:
+		case <-req.Context().Done():
+			return classifyError(req.Context().Err())
+		}
+
+		d.mu.Lock()
+		reused := entry.prediction
+		if reused != nil && reused.Status != Failed && reused.Status != Canceled {
+			*prediction = *reused
+			d.mu.Unlock()
+			return nil
+		}
+		// The earlier request or prediction failed, so create a new one.
+		if d.entries[key] == entry {
+			d.removeLocked(entry)
+		}
+		d.mu.Unlock()
+	}
+
+	entry := &dedupeEntry{key: key, createdAt: r.clock().Now(), ready: make(chan struct{})}
+	d.addLocked(entry)
+	d.mu.Unlock()
+
+	err = r.do(req, prediction)
+
+	d.mu.Lock()
+	if err == nil {
+		created := *prediction
+		entry.prediction = &created
+		d.byID[prediction.ID] = entry
+	} else if d.entries[key] == entry {
+		d.removeLocked(entry)
+	}
+	close(entry.ready)
+	d.mu.Unlock()
+	return err
+}
+
+// updateDeduplicated keeps the prediction remembered for deduplication up
+// to date as the client sees it progress.
+func (r *Client) updateDeduplicated(prediction *Prediction) {
+	if r.options.dedupeWindow <= 0 || prediction == nil || prediction.ID == "" {
+		return
+	}
+
+	d := &r.state.dedupe
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if entry, ok := d.byID[prediction.ID]; ok {
+		updated := *prediction
+		entry.prediction = &updated
+	}
+}
+
+func (d *predictionDedupe) addLocked(entry *dedupeEntry) {
+	if d.entries == nil {
+		d.entries = make(map[string]*dedupeEntry)
+		d.byID = make(map[string]*dedupeEntry)
+	}
+	d.entries[entry.key] = entry
+	d.order = append(d.order, entry)
+	for len(d.order) > maxDeduplicatedPredictions {
+		if oldest := d.order[0]; d.entries[oldest.key] == oldest {
+			d.removeLocked(oldest)
+		}
+		d.order = d.order[1:]
+	}
+}
+
+func (d *predictionDedupe) removeLocked(entry *dedupeEntry) {
+	delete(d.entries, entry.key)
+	if entry.prediction != nil {
+		delete(d.byID, entry.prediction.ID)
+	}
+}
+
+// dedupeKey hashes the credentials, host, path, and body of a request that
+// creates a prediction, so requests made with different tokens, such as
+// those of different tenants, are never deduplicated. The body is marshaled
+// from maps, so its keys are sorted and equal inputs give equal bodies.
+func dedupeKey(req *http.Request) (string, error) {
+	h := sha256.New()
+	for _, part := range []string{req.Header.Get("Authorization"), req.URL.Host, req.URL.Path} {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body: %w", err)
+		}
+		defer body.Close()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,148 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestPredictionDeduplication(t *testing.T) {
+	var created atomic.Int32
+	release := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			<-release
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			id := fmt.Sprintf("prediction-%d", created.Add(1))
+			status := replicate.Starting
+			if body.Input["fail"] == true {
+				status = replicate.Failed
+			}
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: status})
+		case http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Succeeded, Output: "done"})
+		}
+	}))
+	defer mockServer.Close()
+
+	clock := replicatetest.NewFakeClock(time.Now())
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithClock(clock),
+		replicate.WithPredictionDeduplication(time.Hour),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	input := func() replicate.PredictionInput {
+		return replicate.PredictionInput{"prompt": "a cat", "options": map[string]any{"b": 2, "a": 1}}
+	}
+
+	// Identical concurrent submissions share one request.
+	var wg sync.WaitGroup
+	ids := make([]string, 3)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prediction, err := client.CreatePrediction(ctx, "owner/model", input(), nil, false)
+			if assert.NoError(t, err) {
+				ids[i] = prediction.ID
+			}
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []string{"prediction-1", "prediction-1", "prediction-1"}, ids)
+	assert.Equal(t, int32(1), created.Load())
+
+	// Once the client has seen it complete, the completed prediction is
+	// returned.
+	_, err = client.GetPrediction(ctx, "prediction-1")
+	require.NoError(t, err)
+	prediction, err := client.CreatePrediction(ctx, "owner/model", input(), nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+	assert.Equal(t, "done", prediction.Output)
+
+	// Different inputs, or a webhook, make a different prediction.
+	prediction, err = client.CreatePrediction(ctx, "owner/model", replicate.PredictionInput{"prompt": "a dog"}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "prediction-2", prediction.ID)
+	prediction, err = client.CreatePrediction(ctx, "owner/model", input(), &replicate.Webhook{URL: "https://example.com/hook"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "prediction-3", prediction.ID)
+
+	// Failed predictions aren't reused.
+	failing := replicate.PredictionInput{"fail": true}
+	for _, want := range []string{"prediction-4", "prediction-5"} {
+		prediction, err = client.CreatePrediction(ctx, "owner/model", failing, nil, false)
+		require.NoError(t, err)
+		assert.Equal(t, want, prediction.ID)
+	}
+
+	// Predictions are only reused within the window.
+	clock.Advance(time.Hour)
+	prediction, err = client.CreatePrediction(ctx, "owner/model", input(), nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "prediction-6", prediction.ID)
+
+	_, err = replicate.NewClient(replicate.WithToken("test-token"), replicate.WithPredictionDeduplication(0))
+	assert.ErrorContains(t, err, "deduplication window must be positive")
+}
+
+func TestPredictionDeduplicationByToken(t *testing.T) {
+	var created atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := fmt.Sprintf("%s-%d", strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), created.Add(1))
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Starting})
+	}))
+	defer mockServer.Close()
+
+	type tenantKey struct{}
+	client, err := replicate.NewClient(
+		replicate.WithTokenProvider(replicate.TokenProviderFunc(func(ctx context.Context) (string, error) {
+			return ctx.Value(tenantKey{}).(string), nil
+		})),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithPredictionDeduplication(time.Hour),
+	)
+	require.NoError(t, err)
+	derived, err := client.With(replicate.WithToken("token-c"))
+	require.NoError(t, err)
+
+	input := replicate.PredictionInput{"prompt": "a cat"}
+	create := func(ctx context.Context, client *replicate.Client) string {
+		prediction, err := client.CreatePrediction(ctx, "v1", input, nil, false)
+		require.NoError(t, err)
+		return prediction.ID
+	}
+
+	ctxA := context.WithValue(context.Background(), tenantKey{}, "token-a")
+	ctxB := context.WithValue(context.Background(), tenantKey{}, "token-b")
+	assert.Equal(t, "token-a-1", create(ctxA, client))
+	assert.Equal(t, "token-b-2", create(ctxB, client))
+	assert.Equal(t, "token-c-3", create(ctxA, derived))
+	assert.Equal(t, "token-a-1", create(ctxA, client), "the same token should still be deduplicated")
+	assert.Equal(t, int32(3), created.Load())
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 type Deployment struct {
@@ -53,7 +54,7 @@ func (c *Client) CreatePredictionWithDeployment(ctx context.Context, deploymentO
 	}
 
 	prediction := &Prediction{}
-	if err := c.do(req, prediction); err != nil {
+	if err := c.createPrediction(req, prediction); err != nil {
 		return nil, fmt.Errorf("failed to create prediction with deployment: %w", err)
 	}
 
@@ -73,10 +74,8 @@ func (c *Client) GetDeployment(ctx context.Context, deploymentOwner string, depl
 }
 
 // ListDeployments retrieves a list of deployments associated with the current account.
-func (c *Client) ListDeployments(ctx context.Context) (*Page[Deployment], error) {
-	response := &Page[Deployment]{}
-	path := "/deployments"
-	err := c.fetch(ctx, http.MethodGet, path, nil, &response)
+func (c *Client) ListDeployments(ctx context.Context, opts ...ListOption) (*Page[Deployment], error) {
+	response, err := listPage[Deployment](ctx, c, "/deployments", opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list deployments: %w", err)
 	}
@@ -92,8 +91,21 @@ type CreateDeploymentOptions struct {
 	MaxInstances int    `json:"max_instances"`
 }
 
-// CreateDeployment creates a new deployment.
+// CreateDeployment creates a new deployment. If options.Model is a name
+// without an owner, it's taken to be a model of the account returned by
+// CurrentOwner.
 func (c *Client) CreateDeployment(ctx context.Context, options CreateDeploymentOptions) (*Deployment, error) {
+	if options.Model != "" && !strings.Contains(options.Model, "/") {
+		owner, err := c.resolveOwner(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create deployment: %w", err)
+		}
+		options.Model = owner + "/" + options.Model
+	}
+	if err := c.validateHardware(ctx, options.Hardware); err != nil {
+		return nil, fmt.Errorf("failed to create deployment: %w", err)
+	}
+
 	deployment := &Deployment{}
 	path := "/deployments"
 	err := c.fetch(ctx, http.MethodPost, path, options, deployment)
@@ -114,6 +126,12 @@ type UpdateDeploymentOptions struct {
 
 // UpdateDeployment updates an existing deployment.
 func (c *Client) UpdateDeployment(ctx context.Context, deploymentOwner string, deploymentName string, options UpdateDeploymentOptions) (*Deployment, error) {
+	if options.Hardware != nil {
+		if err := c.validateHardware(ctx, *options.Hardware); err != nil {
+			return nil, fmt.Errorf("failed to update deployment: %w", err)
+		}
+	}
+
 	deployment := &Deployment{}
 	path := fmt.Sprintf("/deployments/%s/%s", deploymentOwner, deploymentName)
 	err := c.fetch(ctx, http.MethodPatch, path, options, deployment)
@@ -0,0 +1,85 @@
+package replicate
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// WithHeaders adds h to every request the client makes, such as headers
+// required by a gateway. It can be used more than once; later values for a
+// key replace earlier ones.
+//
+// Headers the client sets itself, like Authorization, and headers set with
+// WithRequestHeaders take precedence.
+func WithHeaders(h http.Header) ClientOption {
+	return func(o *clientOptions) error {
+		headers := o.headers.Clone()
+		if headers == nil {
+			headers = make(http.Header, len(h))
+		}
+		for key, values := range h {
+			headers[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+		}
+		o.headers = headers
+		return nil
+	}
+}
+
+// setClientHeaders adds the headers set with WithHeaders, without replacing
+// headers already set on the request.
+func (r *Client) setClientHeaders(request *http.Request) {
+	for key, values := range r.options.headers {
+		if _, ok := request.Header[key]; ok {
+			continue
+		}
+		request.Header[key] = append([]string(nil), values...)
+	}
+}
+
+// With returns a client derived from r, with opts applied on top of r's
+// options. It's cheap: the derived client shares r's HTTP transport and
+// connection pool, its rate limit tracking and concurrency limit, and its
+// stats, so it suits request-scoped customizations like a different
+// timeout or extra headers.
+//
+// Options that configure the transport, like WithProxy or WithTLSConfig,
+// give the derived client its own copy of the transport. The concurrency
+// limit and failover base URLs can't be changed in a derived client.
+func (r *Client) With(opts ...ClientOption) (*Client, error) {
+	options := r.options.clone()
+	if err := applyOptions(options, opts); err != nil {
+		return nil, err
+	}
+
+	if options.maxConcurrent != r.options.maxConcurrent {
+		return nil, errors.New("max concurrent requests can't be changed in a derived client")
+	}
+	if !slices.Equal(options.failoverBaseURLs, r.options.failoverBaseURLs) || (options.baseURL != r.options.baseURL && r.state.endpoints != nil) {
+		return nil, errors.New("base URLs can't be changed in a derived client with failover")
+	}
+
+	c := &Client{options: options, state: r.state}
+	if err := c.configureHTTPClient(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// clone returns a copy of o that can be modified without affecting o.
+func (o *clientOptions) clone() *clientOptions {
+	c := *o
+	c.appInfo = slices.Clone(o.appInfo)
+	c.failoverBaseURLs = slices.Clone(o.failoverBaseURLs)
+	c.middleware = slices.Clone(o.middleware)
+	c.hooks = callHooks{
+		onRequest:  slices.Clone(o.hooks.onRequest),
+		onResponse: slices.Clone(o.hooks.onResponse),
+		onError:    slices.Clone(o.hooks.onError),
+	}
+	c.headers = o.headers.Clone()
+	c.queryParams = cloneValues(o.queryParams)
+	c.modelConcurrency = maps.Clone(o.modelConcurrency)
+	return &c
+}
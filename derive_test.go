@@ -0,0 +1,86 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestClientWith(t *testing.T) {
+	var mu sync.Mutex
+	var headers []http.Header
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Clone())
+		mu.Unlock()
+		if r.Header.Get("X-Slow") != "" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		json.NewEncoder(w).Encode(&replicate.Account{Type: "user", Username: "alice"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithHeaders(http.Header{"X-Gateway-Tenant": {"acme"}}),
+	)
+	require.NoError(t, err)
+
+	derived, err := client.With(
+		replicate.WithHeaders(http.Header{"X-Team": {"images"}}),
+		replicate.WithTimeout(20*time.Millisecond),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = derived.GetCurrentAccount(ctx)
+	require.NoError(t, err)
+	_, err = client.GetCurrentAccount(replicate.WithRequestHeaders(ctx, http.Header{"X-Gateway-Tenant": {"override"}}))
+	require.NoError(t, err)
+
+	require.Len(t, headers, 2)
+	assert.Equal(t, "acme", headers[0].Get("X-Gateway-Tenant"))
+	assert.Equal(t, "images", headers[0].Get("X-Team"))
+	assert.Equal(t, "override", headers[1].Get("X-Gateway-Tenant"))
+	assert.Empty(t, headers[1].Get("X-Team"), "the parent client is unchanged")
+
+	// The derived client's timeout only applies to it.
+	_, err = derived.GetCurrentAccount(ctx)
+	require.NoError(t, err)
+	slowCtx := replicate.WithRequestHeaders(ctx, http.Header{"X-Slow": {"1"}})
+	_, err = derived.GetCurrentAccount(slowCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = client.GetCurrentAccount(slowCtx)
+	assert.NoError(t, err)
+
+	// Derived clients share stats with their parent.
+	assert.Equal(t, client.Stats(), derived.Stats())
+}
+
+func TestClientWithRestrictions(t *testing.T) {
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithMaxConcurrentRequests(4),
+	)
+	require.NoError(t, err)
+
+	_, err = client.With(replicate.WithMaxConcurrentRequests(8))
+	assert.ErrorContains(t, err, "max concurrent requests can't be changed")
+
+	_, err = client.With(replicate.WithFailoverBaseURLs("https://backup.example.com/v1"))
+	assert.ErrorContains(t, err, "base URLs can't be changed")
+
+	_, err = client.With(replicate.WithToken(""))
+	assert.ErrorIs(t, err, replicate.ErrNoAuth)
+}
@@ -0,0 +1,241 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Dispatcher spreads batch work across several clients, typically each with
+// the API token of a different account, to raise the aggregate throughput of
+// large offline jobs beyond what one token's rate limits allow.
+//
+// Each client runs its own workers, which take items from a shared queue,
+// so a client that is idle picks up work the others haven't started. When
+// an item fails with a transient error, such as the API rate limiting its
+// token, it goes back on the queue for any client to retry, and the client
+// that failed backs off before taking more work.
+type Dispatcher struct {
+	clients []*Client
+}
+
+// NewDispatcher creates a dispatcher that spreads work across clients.
+func NewDispatcher(clients ...*Client) (*Dispatcher, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("dispatcher requires at least one client")
+	}
+	for _, client := range clients {
+		if client == nil {
+			return nil, errors.New("dispatcher clients must not be nil")
+		}
+	}
+	return &Dispatcher{clients: slices.Clone(clients)}, nil
+}
+
+// NewDispatcherFromTokens creates a dispatcher with a client for each token.
+// opts are applied to every client. Each client tracks its own rate limits
+// and connection pool.
+func NewDispatcherFromTokens(tokens []string, opts ...ClientOption) (*Dispatcher, error) {
+	clients := make([]*Client, 0, len(tokens))
+	for i, token := range tokens {
+		if token == "" {
+			return nil, fmt.Errorf("token %d is empty", i)
+		}
+		client, err := NewClient(append(slices.Clone(opts), WithToken(token))...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for token %d: %w", i, err)
+		}
+		clients = append(clients, client)
+	}
+	return NewDispatcher(clients...)
+}
+
+// Clients returns the clients work is spread across.
+func (d *Dispatcher) Clients() []*Client {
+	return slices.Clone(d.clients)
+}
+
+// RunBatch runs a prediction for each item like Client.RunBatch, spreading
+// the items across the dispatcher's clients. Concurrency and RateLimit in
+// opts apply to each client separately, so the aggregate limits grow with
+// the number of clients. Retries of an item may run with any client.
+func (d *Dispatcher) RunBatch(ctx context.Context, items []BatchItem, opts BatchOptions) *BatchResult[*Prediction] {
+	b := &dispatch{
+		items:        items,
+		opts:         opts,
+		result:       &BatchResult[*Prediction]{Items: make([]BatchItemResult[*Prediction], len(items))},
+		queue:        make(chan int, len(items)),
+		models:       make([]ModelRef, len(items)),
+		attempts:     make([]int, len(items)),
+		replacements: make([]int, len(items)),
+		started:      make([]bool, len(items)),
+		done:         make([]bool, len(items)),
+		finished:     make(chan struct{}),
+	}
+	if b.opts.Backoff == nil {
+		b.opts.Backoff = defaultBackoff
+	}
+	if b.opts.PollingInterval <= 0 {
+		b.opts.PollingInterval = defaultPollingInterval
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	for i := range items {
+		b.result.Items[i].Index = i
+		b.models[i] = items[i].Model
+		b.queue <- i
+	}
+	b.remaining = len(items)
+	if b.remaining == 0 {
+		close(b.finished)
+	}
+
+	var wg sync.WaitGroup
+	for _, client := range d.clients {
+		clientCtx, done := client.background(ctx)
+		defer done()
+
+		limiter := newBatchLimiter(client, opts.RateLimit)
+		for w := 0; w < concurrency; w++ {
+			wg.Add(1)
+			go func(client *Client) {
+				defer wg.Done()
+				b.work(clientCtx, client, limiter)
+			}(client)
+		}
+	}
+	wg.Wait()
+
+	// Items are left over if ctx is done or every client was closed.
+	err := ctx.Err()
+	if err == nil {
+		err = ErrClientClosed
+	}
+	for i := range items {
+		if !b.done[i] {
+			b.result.Items[i].Err = err
+		}
+	}
+	return b.result
+}
+
+// dispatch is the state of a Dispatcher.RunBatch call.
+type dispatch struct {
+	items  []BatchItem
+	opts   BatchOptions
+	result *BatchResult[*Prediction]
+
+	// queue holds the items waiting to run. It has room for every item,
+	// so putting one back never blocks.
+	queue chan int
+
+	mu           sync.Mutex
+	models       []ModelRef
+	attempts     []int
+	replacements []int
+	started      []bool
+	done         []bool
+	remaining    int
+	finished     chan struct{}
+}
+
+// work runs items from the queue with client until every item is done or
+// ctx is done.
+func (b *dispatch) work(ctx context.Context, client *Client, limiter *batchLimiter) {
+	runOptions := &fallbackOptions{interval: b.opts.PollingInterval, startTimeout: b.opts.StartTimeout}
+	for {
+		var i int
+		select {
+		case <-b.finished:
+			return
+		case <-ctx.Done():
+			return
+		case i = <-b.queue:
+		}
+
+		b.mu.Lock()
+		first := !b.started[i]
+		b.started[i] = true
+		b.mu.Unlock()
+		if first {
+			prediction, resumed, err := b.opts.loadCheckpoint(ctx, i, b.items[i])
+			if resumed || err != nil {
+				b.finish(i, prediction, err)
+				continue
+			}
+		}
+
+		if err := limiter.wait(ctx); err != nil {
+			b.queue <- i
+			return
+		}
+		b.mu.Lock()
+		model := b.models[i]
+		b.mu.Unlock()
+
+		prediction, err := client.runModelRef(ctx, model, b.items[i].Input, runOptions)
+		if err != nil && ctx.Err() != nil {
+			// Another client may still finish the item if only this one
+			// was closed.
+			b.queue <- i
+			return
+		}
+
+		b.mu.Lock()
+		if b.opts.canReplace(err, b.replacements[i]) {
+			b.replacements[i]++
+			b.models[i] = b.opts.replacementModel(model)
+			b.mu.Unlock()
+			b.queue <- i
+			continue
+		}
+		attempt := b.attempts[i]
+		b.attempts[i]++
+		b.mu.Unlock()
+
+		if err != nil && attempt < b.opts.Retries && isTransientRunError(err) {
+			// Back off before putting the item back, so no worker retries
+			// it early. Any client may pick it up then.
+			sleepErr := client.sleep(ctx, b.opts.Backoff.NextDelay(attempt))
+			b.queue <- i
+			if sleepErr != nil {
+				return
+			}
+			continue
+		}
+
+		if saveErr := b.opts.saveCheckpoint(ctx, client.clock().Now(), i, b.items[i], prediction, b.runs(i), err); saveErr != nil {
+			prediction, err = nil, errors.Join(err, saveErr)
+		}
+		b.finish(i, prediction, err)
+	}
+}
+
+// runs returns the number of predictions run for item i.
+func (b *dispatch) runs(i int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.attempts[i] + b.replacements[i]
+}
+
+func (b *dispatch) finish(i int, prediction *Prediction, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.result.Items[i].Value = prediction
+	b.result.Items[i].Err = err
+	b.done[i] = true
+	if b.opts.OnItemDone != nil {
+		b.opts.OnItemDone(b.result.Items[i])
+	}
+
+	b.remaining--
+	if b.remaining == 0 {
+		close(b.finished)
+	}
+}
@@ -0,0 +1,138 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestDispatcherRunBatch(t *testing.T) {
+	var mu sync.Mutex
+	created := map[string]int{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		if r.Method == http.MethodPost {
+			mu.Lock()
+			created[token]++
+			mu.Unlock()
+			if token == "Bearer token-b" {
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"detail": "Request was throttled."}`))
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Succeeded})
+	}))
+	defer mockServer.Close()
+
+	dispatcher, err := replicate.NewDispatcherFromTokens(
+		[]string{"token-a", "token-b"},
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+	assert.Len(t, dispatcher.Clients(), 2)
+
+	items := make([]replicate.BatchItem, 10)
+	for i := range items {
+		items[i] = replicate.BatchItem{Model: replicate.ModelRef{Identifier: "owner/model"}, Input: replicate.PredictionInput{"i": i}}
+	}
+	result := dispatcher.RunBatch(context.Background(), items, replicate.BatchOptions{
+		Concurrency:     1,
+		Retries:         3,
+		Backoff:         &replicate.ConstantBackoff{Base: 20 * time.Millisecond},
+		PollingInterval: time.Millisecond,
+	})
+	require.NoError(t, result.Err())
+	assert.Len(t, result.Succeeded(), 10)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 10, created["Bearer token-a"])
+	assert.Positive(t, created["Bearer token-b"])
+
+	_, err = replicate.NewDispatcherFromTokens(nil)
+	assert.ErrorContains(t, err, "at least one client")
+	_, err = replicate.NewDispatcherFromTokens([]string{""})
+	assert.ErrorContains(t, err, "token 0 is empty")
+}
+
+func TestDispatcherRunBatchBackoff(t *testing.T) {
+	clock := replicatetest.NewFakeClock(time.Now())
+
+	var mu sync.Mutex
+	var attempts []time.Time
+	countAttempts := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(attempts)
+	}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			mu.Lock()
+			attempts = append(attempts, clock.Now())
+			n := len(attempts)
+			mu.Unlock()
+			if n < 3 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"detail": "Request was throttled."}`))
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Succeeded})
+	}))
+	defer mockServer.Close()
+
+	dispatcher, err := replicate.NewDispatcherFromTokens(
+		[]string{"token-a"},
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+		replicate.WithClock(clock),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results := make(chan *replicate.BatchResult[*replicate.Prediction], 1)
+	go func() {
+		items := []replicate.BatchItem{{Model: replicate.ModelRef{Identifier: "owner/model"}, Input: replicate.PredictionInput{}}}
+		results <- dispatcher.RunBatch(ctx, items, replicate.BatchOptions{
+			Concurrency: 4,
+			Retries:     3,
+			Backoff:     &replicate.ConstantBackoff{Base: 10 * time.Second},
+		})
+	}()
+
+	for n := 1; n < 3; n++ {
+		require.Eventually(t, func() bool { return countAttempts() == n }, time.Second, time.Millisecond)
+		require.NoError(t, clock.BlockUntil(ctx, 1))
+		// The other workers must not retry the item during the backoff.
+		time.Sleep(50 * time.Millisecond)
+		require.Equal(t, n, countAttempts(), "attempt %d should wait for the backoff", n+1)
+		clock.Advance(10 * time.Second)
+	}
+	// Let the last attempt finish polling.
+	require.Eventually(t, func() bool { return countAttempts() == 3 }, time.Second, time.Millisecond)
+	clock.AutoAdvance(true)
+	clock.Advance(time.Minute)
+
+	result := <-results
+	require.NoError(t, result.Err())
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, attempts, 3)
+	for i := 1; i < len(attempts); i++ {
+		assert.Equal(t, 10*time.Second, attempts[i].Sub(attempts[i-1]))
+	}
+}
@@ -0,0 +1,116 @@
+package replicate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables read by NewClientFromEnv.
+const (
+	envBaseURL      = "REPLICATE_BASE_URL"
+	envProxy        = "REPLICATE_PROXY"
+	envTimeout      = "REPLICATE_TIMEOUT"
+	envMaxRetries   = "REPLICATE_MAX_RETRIES"
+	envRetryBackoff = "REPLICATE_RETRY_BACKOFF"
+)
+
+// NewClientFromEnv creates a client configured from environment variables,
+// followed by opts, which take precedence:
+//
+//   - REPLICATE_API_TOKEN: the API token, required unless opts set one
+//   - REPLICATE_BASE_URL: the base URL of the API
+//   - REPLICATE_PROXY: a proxy URL, as for WithProxy
+//   - REPLICATE_TIMEOUT: the timeout of each request attempt, such as "30s"
+//   - REPLICATE_MAX_RETRIES: how many times failed requests are retried
+//   - REPLICATE_RETRY_BACKOFF: the delay before the first retry, such as
+//     "500ms", doubling for each retry after it
+//
+// Variables that are unset or empty are ignored. The token is only required
+// if opts don't set one, and then its absence fails with ErrEnvVarNotSet or
+// ErrEnvVarEmpty. An invalid value fails with an error that names the
+// variable.
+func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
+	envOpts, err := envOptions()
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewClient(append(envOpts, opts...)...)
+	if errors.Is(err, ErrNoAuth) {
+		if _, ok := os.LookupEnv(envAuthToken); ok {
+			return nil, ErrEnvVarEmpty
+		}
+		return nil, ErrEnvVarNotSet
+	}
+	return client, err
+}
+
+func envOptions() ([]ClientOption, error) {
+	var opts []ClientOption
+
+	if os.Getenv(envAuthToken) != "" {
+		opts = append(opts, WithTokenFromEnv())
+	}
+
+	if baseURL := os.Getenv(envBaseURL); baseURL != "" {
+		if err := validateBaseURL(baseURL); err != nil {
+			return nil, envError(envBaseURL, err)
+		}
+		opts = append(opts, WithBaseURL(baseURL))
+	}
+
+	if proxy := os.Getenv(envProxy); proxy != "" {
+		if err := WithProxy(proxy)(&clientOptions{}); err != nil {
+			return nil, envError(envProxy, err)
+		}
+		opts = append(opts, WithProxy(proxy))
+	}
+
+	if value := os.Getenv(envTimeout); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err == nil {
+			err = positiveTimeout("request", timeout)
+		}
+		if err != nil {
+			return nil, envError(envTimeout, err)
+		}
+		opts = append(opts, WithTimeout(timeout))
+	}
+
+	maxRetries := defaultMaxRetries
+	var backoff Backoff = defaultBackoff
+	retriesSet := false
+	if value := os.Getenv(envMaxRetries); value != "" {
+		n, err := strconv.Atoi(value)
+		if err == nil && n < 0 {
+			err = fmt.Errorf("must not be negative, got %d", n)
+		}
+		if err != nil {
+			return nil, envError(envMaxRetries, err)
+		}
+		maxRetries = n
+		retriesSet = true
+	}
+	if value := os.Getenv(envRetryBackoff); value != "" {
+		base, err := time.ParseDuration(value)
+		if err == nil && base <= 0 {
+			err = fmt.Errorf("must be positive, got %s", base)
+		}
+		if err != nil {
+			return nil, envError(envRetryBackoff, err)
+		}
+		backoff = &ExponentialBackoff{Base: base, Multiplier: 2, Jitter: defaultBackoff.Jitter}
+		retriesSet = true
+	}
+	if retriesSet {
+		opts = append(opts, WithRetryPolicy(maxRetries, backoff))
+	}
+
+	return opts, nil
+}
+
+func envError(name string, err error) error {
+	return fmt.Errorf("invalid %s environment variable: %w", name, err)
+}
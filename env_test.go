@@ -0,0 +1,113 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestNewClientFromEnv(t *testing.T) {
+	var requests atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "Bearer env-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/v1/account", r.URL.Path)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer mockServer.Close()
+
+	t.Setenv("REPLICATE_API_TOKEN", "env-token")
+	t.Setenv("REPLICATE_BASE_URL", mockServer.URL+"/v1")
+	t.Setenv("REPLICATE_TIMEOUT", "5s")
+	t.Setenv("REPLICATE_MAX_RETRIES", "2")
+	t.Setenv("REPLICATE_RETRY_BACKOFF", "1ms")
+
+	client, err := replicate.NewClientFromEnv()
+	require.NoError(t, err)
+	_, err = client.GetCurrentAccount(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+
+	// Options take precedence over the environment.
+	requests.Store(0)
+	client, err = replicate.NewClientFromEnv(replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}))
+	require.NoError(t, err)
+	_, err = client.GetCurrentAccount(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestNewClientFromEnvErrors(t *testing.T) {
+	t.Setenv("REPLICATE_API_TOKEN", "env-token")
+
+	for name, value := range map[string]string{
+		"REPLICATE_BASE_URL":      "api.replicate.com",
+		"REPLICATE_PROXY":         "ftp://proxy",
+		"REPLICATE_TIMEOUT":       "ten seconds",
+		"REPLICATE_MAX_RETRIES":   "-1",
+		"REPLICATE_RETRY_BACKOFF": "0s",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			_, err := replicate.NewClientFromEnv()
+			assert.ErrorContains(t, err, "invalid "+name+" environment variable")
+		})
+	}
+
+	t.Setenv("REPLICATE_API_TOKEN", "")
+	_, err := replicate.NewClientFromEnv()
+	assert.ErrorIs(t, err, replicate.ErrEnvVarEmpty)
+}
+
+func TestNewClientFromEnvWithoutToken(t *testing.T) {
+	var requests atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "Bearer option-token", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(&replicate.Account{Type: "user", Username: "alice"})
+	}))
+	defer mockServer.Close()
+
+	t.Setenv("REPLICATE_API_TOKEN", "")
+	os.Unsetenv("REPLICATE_API_TOKEN")
+	t.Setenv("REPLICATE_BASE_URL", mockServer.URL)
+
+	_, err := replicate.NewClientFromEnv()
+	assert.ErrorIs(t, err, replicate.ErrEnvVarNotSet)
+
+	provider := replicate.TokenProviderFunc(func(context.Context) (string, error) {
+		return "option-token", nil
+	})
+	for _, opt := range []replicate.ClientOption{replicate.WithToken("option-token"), replicate.WithTokenProvider(provider)} {
+		client, err := replicate.NewClientFromEnv(opt)
+		require.NoError(t, err)
+		_, err = client.GetCurrentAccount(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestNewClientFromEnvAccount(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(&replicate.Account{Type: "user", Username: "alice"})
+	}))
+	defer mockServer.Close()
+
+	t.Setenv("REPLICATE_API_TOKEN", "env-token")
+	t.Setenv("REPLICATE_BASE_URL", mockServer.URL)
+
+	client, err := replicate.NewClientFromEnv()
+	require.NoError(t, err)
+	account, err := client.GetCurrentAccount(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "alice", account.Username)
+}
@@ -2,11 +2,44 @@ package replicate
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"regexp"
 	"strings"
+	"time"
 )
 
+var (
+	// ErrUnauthorized is matched by API errors with status 401.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrPaymentRequired is matched by API errors with status 402.
+	ErrPaymentRequired = errors.New("payment required")
+	// ErrForbidden is matched by API errors with status 403.
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound is matched by API errors with status 404.
+	ErrNotFound = errors.New("not found")
+	// ErrValidation is matched by API errors with status 422, whose offending
+	// fields are available in APIError.InvalidFields, and by InputError.
+	ErrValidation = errors.New("validation failed")
+	// ErrRateLimited is matched by API errors with status 429.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServerTimeout is matched by API errors with status 408 or 504,
+	// when the API or a gateway in front of it gave up on the request.
+	ErrServerTimeout = errors.New("server timeout")
+	// ErrCanceled is matched by errors caused by the caller canceling the
+	// context of a call.
+	ErrCanceled = errors.New("canceled")
+	// ErrDeadlineExceeded is matched by errors caused by a context deadline
+	// or a client timeout expiring before the API responded.
+	ErrDeadlineExceeded = errors.New("deadline exceeded")
+)
+
+// requestIDHeader is the response header carrying the ID the API assigned to
+// a request.
+const requestIDHeader = "X-Request-ID"
+
 // APIError represents an error returned by the Replicate API
 type APIError struct {
 	// Type is a URI that identifies the error type.
@@ -23,22 +56,135 @@ type APIError struct {
 
 	// Instance is a URI that identifies the specific occurrence of the error.
 	Instance string `json:"instance,omitempty"`
+
+	// InvalidFields lists the inputs that failed validation, for errors with status 422.
+	InvalidFields []InvalidField `json:"invalid_fields,omitempty"`
+
+	// RequestID is the ID the API assigned to the failed request, if any.
+	RequestID string `json:"-"`
+
+	// Header holds the response headers, including any rate limit headers.
+	Header http.Header `json:"-"`
+
+	// ContentType is the content type of the error response.
+	ContentType string `json:"-"`
+
+	// Body holds the start of the error response body, up to the client's
+	// error body limit.
+	Body string `json:"-"`
+
+	// BodyTruncated is true if the response body was longer than Body.
+	BodyTruncated bool `json:"-"`
+
+	// RateLimit is the rate limit state reported with the error, if any.
+	// It is always set for errors with status 429 when the API reports it.
+	RateLimit *RateLimitState `json:"-"`
+}
+
+// InvalidField describes an input that failed validation.
+type InvalidField struct {
+	Type        string `json:"type,omitempty"`
+	Field       string `json:"field,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+const (
+	// defaultErrorBodyLimit is how much of an error response body is kept.
+	defaultErrorBodyLimit = 4 << 10
+
+	// maxErrorParseLength limits how much of an error response body is read
+	// to parse it, regardless of how much is kept.
+	maxErrorParseLength = 1 << 20
+
+	// maxErrorDetailLength limits the body text included in the detail of
+	// errors with a non-JSON body.
+	maxErrorDetailLength = 512
+)
+
+var (
+	htmlTagPattern    = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]*>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// readErrorBody reads up to limit bytes of an error response body, reporting
+// whether there was more.
+func readErrorBody(body io.Reader, limit int) ([]byte, bool, error) {
+	data, err := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data) > limit {
+		return data[:limit], true, nil
+	}
+	return data, false, nil
+}
+
+// truncateBody cuts Body to limit bytes, and marks it truncated if it was
+// longer.
+func (e *APIError) truncateBody(limit int) {
+	if len(e.Body) > limit {
+		e.Body = e.Body[:limit]
+		e.BodyTruncated = true
+	}
 }
 
 func unmarshalAPIError(resp *http.Response, data []byte) *APIError {
-	apiError := APIError{}
+	apiError := APIError{Body: string(data)}
 	err := json.Unmarshal(data, &apiError)
 	if err != nil {
-		apiError.Detail = fmt.Sprintf("Unknown error: %s", err)
+		// The error didn't come from the API itself, for example an HTML
+		// page from a proxy. Summarize the body so the error isn't opaque.
+		if resp != nil {
+			apiError.Title = http.StatusText(resp.StatusCode)
+		}
+		apiError.Detail = summarizeErrorBody(data)
 	}
 
-	if apiError.Status == 0 && resp != nil {
-		apiError.Status = resp.StatusCode
+	if resp != nil {
+		apiError.ContentType = resp.Header.Get("Content-Type")
+		if apiError.Status == 0 {
+			apiError.Status = resp.StatusCode
+		}
+		apiError.RequestID = resp.Header.Get(requestIDHeader)
+		apiError.Header = resp.Header
+		apiError.RateLimit = parseRateLimit(resp.Header, time.Now())
 	}
 
 	return &apiError
 }
 
+// summarizeErrorBody reduces a non-JSON error body to a short line of text.
+func summarizeErrorBody(data []byte) string {
+	text := htmlTagPattern.ReplaceAllString(string(data), " ")
+	text = strings.TrimSpace(whitespacePattern.ReplaceAllString(text, " "))
+	if len(text) > maxErrorDetailLength {
+		text = strings.ToValidUTF8(text[:maxErrorDetailLength], "") + "..."
+	}
+	return text
+}
+
+// Is reports whether the error matches one of the sentinel errors for its
+// status code, such as ErrNotFound, so callers can use errors.Is.
+func (e *APIError) Is(target error) bool {
+	switch e.Status {
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusPaymentRequired:
+		return target == ErrPaymentRequired
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusUnprocessableEntity:
+		return target == ErrValidation
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return target == ErrServerTimeout
+	}
+	return false
+}
+
 func (e APIError) Error() string {
 	components := []string{}
 	if e.Type != "" {
@@ -58,10 +204,25 @@ func (e APIError) Error() string {
 		output = "unknown error"
 	}
 
+	if len(e.InvalidFields) > 0 {
+		fields := make([]string, len(e.InvalidFields))
+		for i, f := range e.InvalidFields {
+			fields[i] = f.Field
+			if f.Description != "" {
+				fields[i] = fmt.Sprintf("%s: %s", f.Field, f.Description)
+			}
+		}
+		output = fmt.Sprintf("%s [%s]", output, strings.Join(fields, "; "))
+	}
+
 	if e.Instance != "" {
 		output = fmt.Sprintf("%s (%s)", output, e.Instance)
 	}
 
+	if e.RequestID != "" {
+		output = fmt.Sprintf("%s [request ID: %s]", output, e.RequestID)
+	}
+
 	return output
 }
 
@@ -0,0 +1,180 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestAPIErrorSentinels(t *testing.T) {
+	tests := []struct {
+		status   int
+		sentinel error
+	}{
+		{http.StatusUnauthorized, replicate.ErrUnauthorized},
+		{http.StatusPaymentRequired, replicate.ErrPaymentRequired},
+		{http.StatusNotFound, replicate.ErrNotFound},
+		{http.StatusUnprocessableEntity, replicate.ErrValidation},
+		{http.StatusTooManyRequests, replicate.ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-Request-ID", "req-123")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"detail": "something went wrong"}`))
+			}))
+			defer mockServer.Close()
+
+			client, err := replicate.NewClient(
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+				replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+			)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.sentinel)
+
+			var apiErr *replicate.APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.status, apiErr.Status)
+			assert.Equal(t, "req-123", apiErr.RequestID)
+			assert.Equal(t, "something went wrong", apiErr.Detail)
+
+			for _, other := range tests {
+				if other.sentinel != tt.sentinel {
+					assert.NotErrorIs(t, err, other.sentinel)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIErrorInvalidFields(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{
+			"title": "Input validation failed",
+			"status": 422,
+			"invalid_fields": [{"type": "required", "field": "prompt", "description": "prompt is required"}]
+		}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.CreatePredictionWithModel(ctx, "owner", "model", replicate.PredictionInput{}, nil, false)
+	require.ErrorIs(t, err, replicate.ErrValidation)
+	assert.ErrorContains(t, err, "prompt: prompt is required")
+
+	var apiErr *replicate.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Len(t, apiErr.InvalidFields, 1)
+	assert.Equal(t, "prompt", apiErr.InvalidFields[0].Field)
+}
+
+func TestAPIErrorLongJSONBody(t *testing.T) {
+	fields := make([]replicate.InvalidField, 100)
+	for i := range fields {
+		fields[i] = replicate.InvalidField{Type: "required", Field: fmt.Sprintf("field_%d", i), Description: "field is required"}
+	}
+	body, err := json.Marshal(map[string]any{
+		"title":          "Input validation failed",
+		"detail":         "Many inputs are invalid",
+		"status":         422,
+		"invalid_fields": fields,
+	})
+	require.NoError(t, err)
+	require.Greater(t, len(body), 4<<10)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	for _, limit := range []int{-1, 0} {
+		opts := []replicate.ClientOption{
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(mockServer.URL),
+		}
+		want := 4 << 10
+		if limit >= 0 {
+			opts = append(opts, replicate.WithErrorBodyLimit(limit))
+			want = limit
+		}
+		client, err := replicate.NewClient(opts...)
+		require.NoError(t, err)
+
+		_, err = client.CreatePredictionWithModel(context.Background(), "owner", "model", replicate.PredictionInput{}, nil, false)
+		require.ErrorIs(t, err, replicate.ErrValidation)
+
+		var apiErr *replicate.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, "Input validation failed", apiErr.Title)
+		assert.Equal(t, "Many inputs are invalid", apiErr.Detail)
+		assert.Len(t, apiErr.InvalidFields, 100)
+		assert.Len(t, apiErr.Body, want)
+		assert.True(t, apiErr.BodyTruncated)
+	}
+}
+
+func TestAPIErrorNonJSONBody(t *testing.T) {
+	page := `<html><head><title>502 Bad Gateway</title><style>body { color: red; }</style></head>
+<body><center><h1>502 Bad Gateway</h1></center><hr><center>nginx</center>` + strings.Repeat(" ", 200) + `</body></html>`
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(page))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+		replicate.WithErrorBodyLimit(200),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.Error(t, err)
+
+	var apiErr *replicate.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.Status)
+	assert.Equal(t, "text/html", apiErr.ContentType)
+	assert.Len(t, apiErr.Body, 200)
+	assert.True(t, apiErr.BodyTruncated)
+	assert.Equal(t, "Bad Gateway", apiErr.Title)
+	assert.Equal(t, "502 Bad Gateway 502 Bad Gateway nginx", apiErr.Detail)
+	assert.ErrorContains(t, err, "Bad Gateway: 502 Bad Gateway 502 Bad Gateway nginx")
+}
@@ -0,0 +1,183 @@
+package replicate
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTrackedPredictions bounds the number of predictions whose progress is
+// remembered for events.
+const maxTrackedPredictions = 10000
+
+// PredictionEventType is the kind of a PredictionEvent.
+type PredictionEventType string
+
+const (
+	// PredictionEventSubmitted is emitted when the client creates a
+	// prediction.
+	PredictionEventSubmitted PredictionEventType = "submitted"
+
+	// PredictionEventStarted is emitted when a prediction is first seen
+	// processing.
+	PredictionEventStarted PredictionEventType = "started"
+
+	// PredictionEventOutput is emitted when a prediction is seen with new
+	// output.
+	PredictionEventOutput PredictionEventType = "output"
+
+	// PredictionEventCompleted is emitted when a prediction is first seen
+	// succeeded.
+	PredictionEventCompleted PredictionEventType = "completed"
+
+	// PredictionEventFailed is emitted when a prediction is first seen
+	// failed or canceled.
+	PredictionEventFailed PredictionEventType = "failed"
+)
+
+// PredictionEvent is a change in a prediction observed by the client.
+type PredictionEvent struct {
+	Type PredictionEventType
+
+	// Prediction is a copy of the prediction as the client saw it.
+	Prediction *Prediction
+
+	Time time.Time
+}
+
+// Subscribe calls handler with the events of every prediction the client
+// creates or polls, so that concerns like metrics, persistence, and
+// notifications can observe all prediction activity in one place. If types
+// are given, only events of those types are delivered. The returned
+// function unsubscribes.
+//
+// Events are derived from the API responses the client receives: a
+// prediction that finishes between two polls is reported as started and
+// completed together, and one that is never polled again after being
+// created only gets a submitted event. Subscriptions are shared with
+// clients derived with With.
+//
+// handler is called synchronously, in the goroutine that received the
+// response, so it must be safe for concurrent use and return quickly.
+func (r *Client) Subscribe(handler func(PredictionEvent), types ...PredictionEventType) (unsubscribe func()) {
+	b := &r.state.events
+	sub := &eventSubscriber{handler: handler, types: slices.Clone(types)}
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, sub)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.subscribers = slices.DeleteFunc(slices.Clone(b.subscribers), func(s *eventSubscriber) bool {
+				return s == sub
+			})
+			if len(b.subscribers) == 0 {
+				b.tracked = nil
+			}
+		})
+	}
+}
+
+type eventSubscriber struct {
+	handler func(PredictionEvent)
+	types   []PredictionEventType
+}
+
+func (s *eventSubscriber) wants(t PredictionEventType) bool {
+	return len(s.types) == 0 || slices.Contains(s.types, t)
+}
+
+// eventBus dispatches prediction events to subscribers.
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers []*eventSubscriber
+
+	// tracked is the last seen state of predictions, so that polling a
+	// prediction again only emits events for what changed.
+	tracked map[string]*trackedPrediction
+}
+
+func (b *eventBus) trackLocked(prediction *Prediction, known bool) {
+	if !known && len(b.tracked) >= maxTrackedPredictions {
+		// Make room by forgetting finished predictions.
+		for id, t := range b.tracked {
+			if t.status.Terminated() {
+				delete(b.tracked, id)
+			}
+		}
+		if len(b.tracked) >= maxTrackedPredictions {
+			return
+		}
+	}
+	if b.tracked == nil {
+		b.tracked = make(map[string]*trackedPrediction)
+	}
+	b.tracked[prediction.ID] = &trackedPrediction{status: prediction.Status, output: prediction.Output}
+}
+
+type trackedPrediction struct {
+	status Status
+	output PredictionOutput
+}
+
+// observePrediction emits the events for a prediction decoded from the
+// response to request.
+func (r *Client) observePrediction(request *http.Request, prediction *Prediction) {
+	if prediction == nil || prediction.ID == "" {
+		return
+	}
+
+	b := &r.state.events
+	b.mu.Lock()
+	if len(b.subscribers) == 0 {
+		b.mu.Unlock()
+		return
+	}
+
+	var types []PredictionEventType
+	last, ok := b.tracked[prediction.ID]
+	if !ok {
+		last = &trackedPrediction{status: Starting}
+	}
+	if request.Method == http.MethodPost && !strings.HasSuffix(request.URL.Path, "/cancel") {
+		types = append(types, PredictionEventSubmitted)
+	}
+	if last.status == Starting && prediction.Status != Starting {
+		types = append(types, PredictionEventStarted)
+	}
+	if prediction.Output != nil && !reflect.DeepEqual(prediction.Output, last.output) {
+		types = append(types, PredictionEventOutput)
+	}
+	if !last.status.Terminated() {
+		switch prediction.Status {
+		case Succeeded:
+			types = append(types, PredictionEventCompleted)
+		case Failed, Canceled:
+			types = append(types, PredictionEventFailed)
+		}
+	}
+	b.trackLocked(prediction, ok)
+	subscribers := b.subscribers
+	b.mu.Unlock()
+
+	if len(types) == 0 {
+		return
+	}
+	now := r.clock().Now()
+	for _, t := range types {
+		snapshot := *prediction
+		event := PredictionEvent{Type: t, Prediction: &snapshot, Time: now}
+		for _, sub := range subscribers {
+			if sub.wants(t) {
+				sub.handler(event)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestSubscribe(t *testing.T) {
+	polls := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prediction := &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting}
+		if r.Method == http.MethodGet {
+			polls++
+			switch polls {
+			case 1:
+				prediction.Status = replicate.Processing
+				prediction.Output = []any{"Hello"}
+			case 2:
+				prediction.Status = replicate.Processing
+				prediction.Output = []any{"Hello"}
+			default:
+				prediction.Status = replicate.Succeeded
+				prediction.Output = []any{"Hello", " world"}
+			}
+		}
+		json.NewEncoder(w).Encode(prediction)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var events, completed []replicate.PredictionEventType
+	unsubscribe := client.Subscribe(func(e replicate.PredictionEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", e.Prediction.ID)
+		events = append(events, e.Type)
+	})
+	client.Subscribe(func(e replicate.PredictionEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		completed = append(completed, e.Type)
+	}, replicate.PredictionEventCompleted, replicate.PredictionEventFailed)
+
+	prediction, err := client.CreatePrediction(context.Background(), "owner/model", replicate.PredictionInput{}, nil, false)
+	require.NoError(t, err)
+	require.NoError(t, client.Wait(context.Background(), prediction, replicate.WithPollingInterval(time.Millisecond)))
+
+	// Seeing a finished prediction again doesn't repeat its events.
+	_, err = client.GetPrediction(context.Background(), prediction.ID)
+	require.NoError(t, err)
+
+	unsubscribe()
+	polls = 0
+	_, err = client.GetPrediction(context.Background(), prediction.ID)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []replicate.PredictionEventType{
+		replicate.PredictionEventSubmitted,
+		replicate.PredictionEventStarted,
+		replicate.PredictionEventOutput,
+		replicate.PredictionEventOutput,
+		replicate.PredictionEventCompleted,
+	}, events)
+	assert.Equal(t, []replicate.PredictionEventType{replicate.PredictionEventCompleted}, completed)
+}
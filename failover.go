@@ -0,0 +1,238 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultHealthCheckInterval = 30 * time.Second
+
+// WithFailoverBaseURLs sets base URLs to fail over to when the base URL is
+// unreachable, in order of preference.
+//
+// Requests go to the most preferred healthy endpoint. When a request fails
+// with a network error or a 502, 503, or 504 response, the endpoint is marked
+// unhealthy and later requests and retries use the next one. The client keeps
+// using that endpoint until a health check shows a more preferred one has
+// recovered.
+func WithFailoverBaseURLs(baseURLs ...string) ClientOption {
+	return func(o *clientOptions) error {
+		for _, baseURL := range baseURLs {
+			u, err := url.Parse(baseURL)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("invalid failover base URL %q", baseURL)
+			}
+		}
+		o.failoverBaseURLs = append(o.failoverBaseURLs, baseURLs...)
+		return nil
+	}
+}
+
+// WithHealthCheckInterval sets how often unhealthy endpoints are checked for
+// recovery. The default is 30 seconds.
+func WithHealthCheckInterval(interval time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if interval <= 0 {
+			return errors.New("health check interval must be positive")
+		}
+		o.healthCheckInterval = interval
+		return nil
+	}
+}
+
+// ActiveBaseURL returns the base URL requests are currently sent to.
+func (r *Client) ActiveBaseURL() string {
+	if r.state.endpoints == nil {
+		return r.options.baseURL
+	}
+	return r.state.endpoints.active()
+}
+
+type endpoint struct {
+	baseURL   string
+	healthy   bool
+	checking  bool
+	lastCheck time.Time
+}
+
+// endpointSet tracks the health of the base URLs a client can use.
+type endpointSet struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	interval  time.Duration
+}
+
+func newEndpointSet(baseURLs []string, interval time.Duration) *endpointSet {
+	s := &endpointSet{interval: interval}
+	for _, baseURL := range baseURLs {
+		s.endpoints = append(s.endpoints, &endpoint{baseURL: baseURL, healthy: true})
+	}
+	return s
+}
+
+// active returns the most preferred healthy endpoint, or the most preferred
+// endpoint if none are healthy.
+func (s *endpointSet) active() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.activeLocked().baseURL
+}
+
+func (s *endpointSet) activeLocked() *endpoint {
+	for _, e := range s.endpoints {
+		if e.healthy {
+			return e
+		}
+	}
+	return s.endpoints[0]
+}
+
+// markUnhealthy records a failed request to baseURL. It reports whether the
+// endpoint was healthy before.
+func (s *endpointSet) markUnhealthy(baseURL string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.endpoints {
+		if e.baseURL == baseURL && e.healthy {
+			e.healthy = false
+			e.lastCheck = now
+			return true
+		}
+	}
+	return false
+}
+
+// dueForCheck returns the unhealthy endpoints that haven't been checked
+// recently, and marks them as being checked.
+func (s *endpointSet) dueForCheck(now time.Time) []*endpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*endpoint
+	for _, e := range s.endpoints {
+		if !e.healthy && !e.checking && now.Sub(e.lastCheck) >= s.interval {
+			e.checking = true
+			due = append(due, e)
+		}
+	}
+	return due
+}
+
+// finishCheck records the result of a health check. It reports whether the
+// endpoint recovered.
+func (s *endpointSet) finishCheck(e *endpoint, healthy bool, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.checking = false
+	e.lastCheck = now
+	recovered := healthy && !e.healthy
+	e.healthy = e.healthy || healthy
+	return recovered
+}
+
+// routeToEndpoint returns a copy of the request pointed at the active
+// endpoint, and starts health checks for endpoints that are due. It also
+// returns the base URL the request is sent to, which is empty if the request
+// isn't subject to failover.
+func (r *Client) routeToEndpoint(request *http.Request) (*http.Request, string) {
+	endpoints := r.state.endpoints
+	if endpoints == nil {
+		return request, ""
+	}
+	if _, ok := requestBaseURL(request.Context()); ok {
+		// Requests with their own base URL aren't subject to failover.
+		return request, ""
+	}
+
+	for _, e := range endpoints.dueForCheck(time.Now()) {
+		ctx, done := r.background(context.Background())
+		go func(e *endpoint) {
+			defer done()
+			r.checkEndpoint(ctx, e)
+		}(e)
+	}
+
+	baseURL := endpoints.active()
+	primary := strings.TrimSuffix(r.options.baseURL, "/")
+	if !strings.HasPrefix(request.URL.String(), primary) {
+		return request, ""
+	}
+	if baseURL == r.options.baseURL {
+		return request, baseURL
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + strings.TrimPrefix(request.URL.String(), primary))
+	if err != nil {
+		return request, ""
+	}
+	routed := request.Clone(request.Context())
+	routed.URL = u
+	routed.Host = ""
+	return routed, baseURL
+}
+
+// recordEndpointResult marks the endpoint unhealthy if the request failed in
+// a way that suggests it is unreachable.
+func (r *Client) recordEndpointResult(baseURL string, response *http.Response, err error) {
+	if baseURL == "" {
+		return
+	}
+	if err == nil && (response == nil || !isGatewayError(response.StatusCode)) {
+		return
+	}
+	if err != nil && errors.Is(err, context.Canceled) {
+		return
+	}
+	if r.state.endpoints.markUnhealthy(baseURL, time.Now()) {
+		r.state.counters.circuitOpened.Add(1)
+	}
+}
+
+// checkEndpoint probes an endpoint. Any response other than a gateway error
+// shows the network path works.
+func (r *Client) checkEndpoint(ctx context.Context, e *endpoint) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	healthy := r.probeEndpoint(ctx, e)
+	if r.state.endpoints.finishCheck(e, healthy, time.Now()) {
+		r.state.counters.circuitClosed.Add(1)
+	}
+}
+
+func (r *Client) probeEndpoint(ctx context.Context, e *endpoint) bool {
+	token, err := r.token(ctx)
+	if err != nil {
+		return false
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, constructURL(e.baseURL, "/account"), nil)
+	if err != nil {
+		return false
+	}
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	r.setUserAgent(request)
+
+	response, err := r.c.Do(request)
+	if err != nil {
+		return false
+	}
+	response.Body.Close()
+	return !isGatewayError(response.StatusCode)
+}
+
+func isGatewayError(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestFailoverBaseURLs(t *testing.T) {
+	var primaryDown atomic.Bool
+	primaryDown.Store(true)
+
+	var primaryRequests, backupRequests atomic.Int32
+	handler := func(down *atomic.Bool, requests *atomic.Int32) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			if down != nil && down.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			switch r.URL.Path {
+			case "/v1/account":
+				json.NewEncoder(w).Encode(&replicate.Account{Username: "acme"})
+			case "/v1/predictions/ufawqhfynnddngldkgtslldrkq":
+				json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+			default:
+				t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			}
+		}
+	}
+
+	primary := httptest.NewServer(handler(&primaryDown, &primaryRequests))
+	defer primary.Close()
+	backup := httptest.NewServer(handler(nil, &backupRequests))
+	defer backup.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(primary.URL+"/v1"),
+		replicate.WithFailoverBaseURLs(backup.URL+"/v1"),
+		replicate.WithHealthCheckInterval(10*time.Millisecond),
+		replicate.WithRetryPolicy(2, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	prediction, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, int32(1), primaryRequests.Load())
+	assert.Equal(t, int32(1), backupRequests.Load())
+	assert.Equal(t, backup.URL+"/v1", client.ActiveBaseURL())
+
+	// Requests stick to the backup while the primary is down.
+	time.Sleep(20 * time.Millisecond)
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), backupRequests.Load())
+
+	primaryDown.Store(false)
+	assert.Eventually(t, func() bool {
+		_, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+		require.NoError(t, err)
+		return client.ActiveBaseURL() == primary.URL+"/v1"
+	}, time.Second, 15*time.Millisecond)
+
+	stats := client.Stats()
+	assert.Equal(t, uint64(1), stats.CircuitOpened)
+	assert.Equal(t, uint64(1), stats.CircuitClosed)
+}
+
+func TestFailoverBaseURLsMustBeValid(t *testing.T) {
+	_, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithFailoverBaseURLs("not a url"),
+	)
+	assert.ErrorContains(t, err, "invalid failover base URL")
+}
@@ -0,0 +1,275 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrNoModels = errors.New("no models to run")
+
+// ModelRef refers to a model, model version, or deployment to run.
+type ModelRef struct {
+	// Identifier is a model identifier in the form "owner/name" or
+	// "owner/name:version".
+	Identifier string
+
+	// Deployment is a deployment in the form "owner/name". If set,
+	// Identifier is ignored.
+	Deployment string
+}
+
+func (m ModelRef) String() string {
+	if m.Deployment != "" {
+		return "deployments/" + m.Deployment
+	}
+	return m.Identifier
+}
+
+// RunModelRef creates a prediction of model, waits for it to finish, and
+// returns it. If the prediction doesn't succeed, the error is a
+// *ModelError. Like Submit, it waits for a slot of the model's concurrency
+// cap, if one is set with WithModelConcurrency. opts configure the wait, as
+// for Wait.
+//
+// If onCreate isn't nil, the prediction is created with streaming enabled,
+// and onCreate is called with it before waiting, for example to stream its
+// output. An error from onCreate is returned without waiting.
+func (r *Client) RunModelRef(ctx context.Context, model ModelRef, input PredictionInput, onCreate func(*Prediction) error, opts ...WaitOption) (*Prediction, error) {
+	release, err := r.acquireModelSlot(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	prediction, err := r.createModelRefPrediction(ctx, model, input, onCreate != nil)
+	if err != nil {
+		return nil, err
+	}
+	if onCreate != nil {
+		if err := onCreate(prediction); err != nil {
+			return nil, err
+		}
+	}
+	if !prediction.Status.Terminated() {
+		if err := r.Wait(ctx, prediction, opts...); err != nil {
+			return nil, err
+		}
+	}
+	if prediction.Status != Succeeded {
+		return nil, &ModelError{Prediction: prediction}
+	}
+	return prediction, nil
+}
+
+// createModelRefPrediction creates a prediction of model, which is a model
+// or a deployment.
+func (r *Client) createModelRefPrediction(ctx context.Context, model ModelRef, input PredictionInput, stream bool) (*Prediction, error) {
+	if model.Deployment != "" {
+		owner, name, ok := strings.Cut(model.Deployment, "/")
+		if !ok || owner == "" || name == "" {
+			return nil, fmt.Errorf("invalid deployment %q, it must be in the format \"owner/name\"", model.Deployment)
+		}
+		return r.CreatePredictionWithDeployment(ctx, owner, name, input, nil, stream)
+	}
+	return r.CreatePrediction(ctx, model.Identifier, input, nil, stream)
+}
+
+// FallbackClass is a set of error classes that cause RunWithFallback to try
+// the next model.
+type FallbackClass int
+
+const (
+	// FallbackOnModelError falls back when the prediction fails or is
+	// canceled.
+	FallbackOnModelError FallbackClass = 1 << iota
+
+	// FallbackOnCapacity falls back when the API is rate limiting requests
+	// or is temporarily unavailable.
+	FallbackOnCapacity
+
+	// FallbackOnTimeout falls back when a model doesn't finish within the
+	// timeout set with WithFallbackTimeout.
+	FallbackOnTimeout
+
+	// FallbackOnAll falls back on every error class.
+	FallbackOnAll = FallbackOnModelError | FallbackOnCapacity | FallbackOnTimeout
+)
+
+type fallbackOptions struct {
+	classes  FallbackClass
+	timeout  time.Duration
+	interval time.Duration
+
+	// startTimeout is set by RunBatch from BatchOptions.StartTimeout.
+	startTimeout time.Duration
+}
+
+// FallbackOption is a function that modifies the options for
+// RunWithFallback.
+type FallbackOption func(*fallbackOptions)
+
+// WithFallbackOn sets the error classes that cause the next model to be
+// tried. The default is FallbackOnAll.
+func WithFallbackOn(classes FallbackClass) FallbackOption {
+	return func(o *fallbackOptions) {
+		o.classes = classes
+	}
+}
+
+// WithFallbackTimeout sets how long each model has to finish before the
+// prediction is canceled and the next model is tried.
+func WithFallbackTimeout(d time.Duration) FallbackOption {
+	return func(o *fallbackOptions) {
+		o.timeout = d
+	}
+}
+
+// WithFallbackPollingInterval sets the interval between polls while waiting
+// for each prediction.
+func WithFallbackPollingInterval(interval time.Duration) FallbackOption {
+	return func(o *fallbackOptions) {
+		o.interval = interval
+	}
+}
+
+// FallbackAttempt records a model that was tried and why it failed.
+type FallbackAttempt struct {
+	Model ModelRef
+	Err   error
+}
+
+// FallbackResult is the result of RunWithFallback.
+type FallbackResult struct {
+	// Output is the output of the prediction that succeeded.
+	Output PredictionOutput
+
+	// Prediction is the prediction that succeeded.
+	Prediction *Prediction
+
+	// Model is the model that served the request.
+	Model ModelRef
+
+	// Failed lists the models that were tried before Model, in order.
+	Failed []FallbackAttempt
+}
+
+// FallbackError is returned by RunWithFallback when no model succeeded.
+type FallbackError struct {
+	Attempts []FallbackAttempt
+}
+
+func (e *FallbackError) Error() string {
+	parts := make([]string, len(e.Attempts))
+	for i, a := range e.Attempts {
+		parts[i] = fmt.Sprintf("%s: %v", a.Model, a.Err)
+	}
+	return "all models failed: " + strings.Join(parts, "; ")
+}
+
+// Unwrap returns the errors of each attempt.
+func (e *FallbackError) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, a := range e.Attempts {
+		errs[i] = a.Err
+	}
+	return errs
+}
+
+// RunWithFallback runs the models in order until one succeeds.
+//
+// The next model is only tried if a model fails with an error in one of the
+// configured classes; other errors, such as invalid input, are returned
+// immediately. If every model fails, the returned error is a *FallbackError.
+func (r *Client) RunWithFallback(ctx context.Context, models []ModelRef, input PredictionInput, opts ...FallbackOption) (*FallbackResult, error) {
+	if len(models) == 0 {
+		return nil, ErrNoModels
+	}
+
+	options := &fallbackOptions{
+		classes:  FallbackOnAll,
+		interval: defaultPollingInterval,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var failed []FallbackAttempt
+	for _, model := range models {
+		prediction, err := r.runModelRef(ctx, model, input, options)
+		if err == nil {
+			return &FallbackResult{
+				Output:     prediction.Output,
+				Prediction: prediction,
+				Model:      model,
+				Failed:     failed,
+			}, nil
+		}
+
+		failed = append(failed, FallbackAttempt{Model: model, Err: err})
+		if ctx.Err() != nil || !options.shouldFallback(err) {
+			break
+		}
+	}
+
+	return nil, &FallbackError{Attempts: failed}
+}
+
+func (r *Client) runModelRef(ctx context.Context, model ModelRef, input PredictionInput, options *fallbackOptions) (*Prediction, error) {
+	if options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+
+	release, err := r.acquireModelSlot(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	prediction, err := r.createModelRefPrediction(ctx, model, input, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.waitForRun(ctx, prediction, options); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			// Don't leave the abandoned prediction running.
+			cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			defer cancel()
+			_, _ = r.CancelPrediction(cancelCtx, prediction.ID)
+		}
+		return nil, err
+	}
+
+	if prediction.Status != Succeeded {
+		return nil, &ModelError{Prediction: prediction}
+	}
+
+	return prediction, nil
+}
+
+func (o *fallbackOptions) shouldFallback(err error) bool {
+	var modelErr *ModelError
+	if errors.As(err, &modelErr) {
+		return o.classes&FallbackOnModelError != 0
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return o.classes&FallbackOnTimeout != 0
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Status {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return o.classes&FallbackOnCapacity != 0
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestRunWithFallback(t *testing.T) {
+	var created []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			created = append(created, r.URL.Path)
+			switch r.URL.Path {
+			case "/models/acme/busy/predictions":
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case "/models/acme/broken/predictions":
+				json.NewEncoder(w).Encode(&replicate.Prediction{ID: "broken", Status: replicate.Starting})
+			case "/deployments/acme/backup/predictions":
+				json.NewEncoder(w).Encode(&replicate.Prediction{ID: "backup", Status: replicate.Starting})
+			default:
+				t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			}
+		case r.URL.Path == "/predictions/broken":
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "broken", Status: replicate.Failed, Error: "CUDA out of memory"})
+		case r.URL.Path == "/predictions/backup":
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "backup", Status: replicate.Succeeded, Output: "hello"})
+		default:
+			t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+
+	models := []replicate.ModelRef{
+		{Identifier: "acme/busy"},
+		{Identifier: "acme/broken"},
+		{Deployment: "acme/backup"},
+	}
+
+	t.Run("falls back", func(t *testing.T) {
+		created = nil
+		result, err := client.RunWithFallback(context.Background(), models, replicate.PredictionInput{"prompt": "hi"},
+			replicate.WithFallbackPollingInterval(time.Millisecond))
+		require.NoError(t, err)
+
+		assert.Equal(t, "hello", result.Output)
+		assert.Equal(t, replicate.ModelRef{Deployment: "acme/backup"}, result.Model)
+		require.Len(t, result.Failed, 2)
+		var apiErr *replicate.APIError
+		require.ErrorAs(t, result.Failed[0].Err, &apiErr)
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.Status)
+		var modelErr *replicate.ModelError
+		assert.ErrorAs(t, result.Failed[1].Err, &modelErr)
+		assert.Len(t, created, 3)
+	})
+
+	t.Run("stops on excluded classes", func(t *testing.T) {
+		created = nil
+		_, err := client.RunWithFallback(context.Background(), models, replicate.PredictionInput{"prompt": "hi"},
+			replicate.WithFallbackOn(replicate.FallbackOnCapacity),
+			replicate.WithFallbackPollingInterval(time.Millisecond))
+
+		var fallbackErr *replicate.FallbackError
+		require.ErrorAs(t, err, &fallbackErr)
+		assert.Len(t, fallbackErr.Attempts, 2)
+		assert.Len(t, created, 2)
+	})
+}
+
+func TestRunModelRef(t *testing.T) {
+	var streamed []bool
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			streamed = append(streamed, body["stream"] == true)
+			switch r.URL.Path {
+			case "/deployments/acme/backup/predictions":
+				json.NewEncoder(w).Encode(&replicate.Prediction{ID: "backup", Status: replicate.Starting})
+			case "/models/acme/broken/predictions":
+				json.NewEncoder(w).Encode(&replicate.Prediction{ID: "broken", Status: replicate.Failed, Error: "CUDA out of memory"})
+			default:
+				t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			}
+		case r.URL.Path == "/predictions/backup":
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "backup", Status: replicate.Succeeded, Output: "hello"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	var created *replicate.Prediction
+	prediction, err := client.RunModelRef(ctx, replicate.ModelRef{Deployment: "acme/backup"}, replicate.PredictionInput{}, func(p *replicate.Prediction) error {
+		created = p
+		return nil
+	}, replicate.WithPollingInterval(time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", prediction.Output)
+	assert.Same(t, created, prediction)
+
+	_, err = client.RunModelRef(ctx, replicate.ModelRef{Identifier: "acme/broken"}, replicate.PredictionInput{}, nil)
+	var modelErr *replicate.ModelError
+	require.ErrorAs(t, err, &modelErr)
+	assert.Equal(t, "broken", modelErr.Prediction.ID)
+	assert.Equal(t, []bool{true, false}, streamed)
+
+	_, err = client.RunModelRef(ctx, replicate.ModelRef{Deployment: "backup"}, replicate.PredictionInput{}, nil)
+	assert.ErrorContains(t, err, `invalid deployment "backup"`)
+}
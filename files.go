@@ -158,9 +158,8 @@ func (r *Client) createFile(ctx context.Context, reader io.Reader, options Creat
 }
 
 // ListFiles lists your files.
-func (r *Client) ListFiles(ctx context.Context) (*Page[File], error) {
-	response := &Page[File]{}
-	err := r.fetch(ctx, http.MethodGet, "/files", nil, response)
+func (r *Client) ListFiles(ctx context.Context, opts ...ListOption) (*Page[File], error) {
+	response, err := listPage[File](ctx, r, "/files", opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
@@ -0,0 +1,61 @@
+package replicate
+
+import (
+	"context"
+)
+
+// PredictionFuture is a handle to a prediction started with Submit. Its
+// methods are safe for concurrent use.
+type PredictionFuture struct {
+	done       chan struct{}
+	prediction *Prediction
+	err        error
+}
+
+// Submit starts running a model in the background and returns a future for
+// its result, so several predictions can run at once without managing
+// goroutines, for example with errgroup:
+//
+//	upscaled := client.Submit(ctx, "owner/upscaler", input)
+//	captioned := client.Submit(ctx, "owner/captioner", input)
+//	g, ctx := errgroup.WithContext(ctx)
+//	g.Go(func() error { _, err := upscaled.Result(ctx); return err })
+//	g.Go(func() error { _, err := captioned.Result(ctx); return err })
+//	err := g.Wait()
+//
+// The prediction is created and waited for with ctx, so canceling ctx stops
+// waiting. opts configure the wait, as for Wait.
+func (r *Client) Submit(ctx context.Context, identifier string, input PredictionInput, opts ...WaitOption) *PredictionFuture {
+	f := &PredictionFuture{done: make(chan struct{})}
+	ctx, done := r.background(ctx)
+	go func() {
+		defer done()
+		defer close(f.done)
+		f.prediction, f.err = r.runToCompletion(ctx, identifier, input, opts...)
+	}()
+	return f
+}
+
+func (r *Client) runToCompletion(ctx context.Context, identifier string, input PredictionInput, opts ...WaitOption) (*Prediction, error) {
+	return r.RunModelRef(ctx, ModelRef{Identifier: identifier}, input, nil, opts...)
+}
+
+// Done returns a channel that's closed when the prediction has finished, or
+// failed to run.
+func (f *PredictionFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result waits for the prediction to finish and returns it. If the
+// prediction didn't succeed, the error is a *ModelError.
+//
+// If ctx is done first, Result returns its error, and the prediction keeps
+// running; call Result again to wait for it.
+func (f *PredictionFuture) Result(ctx context.Context) (*Prediction, error) {
+	select {
+	case <-f.done:
+		return f.prediction, f.err
+	case <-ctx.Done():
+		return nil, classifyError(ctx.Err())
+	}
+}
@@ -0,0 +1,81 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestSubmit(t *testing.T) {
+	release := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			model := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/models/owner/"), "/predictions")
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: model, Status: replicate.Starting})
+		case http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			prediction := &replicate.Prediction{ID: id, Status: replicate.Succeeded, Output: id + " output"}
+			switch id {
+			case "slow":
+				<-release
+			case "broken":
+				prediction.Status = replicate.Failed
+				prediction.Error = "boom"
+			}
+			json.NewEncoder(w).Encode(prediction)
+		}
+	}))
+	defer mockServer.Close()
+	defer close(release)
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	interval := replicate.WithPollingInterval(time.Millisecond)
+	fast := client.Submit(ctx, "owner/fast", replicate.PredictionInput{}, interval)
+	broken := client.Submit(ctx, "owner/broken", replicate.PredictionInput{}, interval)
+	slow := client.Submit(ctx, "owner/slow", replicate.PredictionInput{}, interval)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		_, err := fast.Result(gctx)
+		return err
+	})
+	g.Go(func() error {
+		_, err := broken.Result(gctx)
+		return err
+	})
+	err = g.Wait()
+	var modelErr *replicate.ModelError
+	require.ErrorAs(t, err, &modelErr)
+	assert.Equal(t, "broken", modelErr.Prediction.ID)
+
+	prediction, err := fast.Result(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "fast output", prediction.Output)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = slow.Result(timeout)
+	assert.ErrorIs(t, err, replicate.ErrDeadlineExceeded)
+	select {
+	case <-slow.Done():
+		t.Fatal("slow prediction finished early")
+	default:
+	}
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 )
 
 type Hardware struct {
@@ -32,7 +37,98 @@ func (r *Client) ListHardware(ctx context.Context) (*[]Hardware, error) {
 	response := &[]Hardware{}
 	err := r.fetch(ctx, http.MethodGet, "/hardware", nil, response)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list collections: %w", err)
+		return nil, fmt.Errorf("failed to list hardware: %w", err)
 	}
 	return response, nil
 }
+
+// HardwareError is returned when creating a model or deployment with
+// hardware that isn't available, as reported by ListHardware.
+//
+// HardwareError matches ErrValidation.
+type HardwareError struct {
+	// SKU is the requested hardware.
+	SKU string
+
+	// Valid are the SKUs of the available hardware.
+	Valid []string
+}
+
+func (e *HardwareError) Error() string {
+	return fmt.Sprintf("invalid hardware %q: must be one of %s", e.SKU, strings.Join(e.Valid, ", "))
+}
+
+func (e *HardwareError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// WithHardwareValidation makes CreateModel, CreateDeployment, and
+// UpdateDeployment check the requested hardware against ListHardware before
+// sending the request, and fail with a *HardwareError if it isn't available.
+// The hardware list is cached for ttl, and shared with clients derived with
+// With. If the list can't be fetched, the request is sent unchecked.
+func WithHardwareValidation(ttl time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if ttl <= 0 {
+			return fmt.Errorf("hardware cache TTL must be positive, got %s", ttl)
+		}
+		o.hardwareTTL = ttl
+		return nil
+	}
+}
+
+// hardwareCache holds the SKUs of the available hardware for
+// WithHardwareValidation.
+type hardwareCache struct {
+	mu      sync.Mutex
+	skus    []string
+	fetched time.Time
+}
+
+// validateHardware returns a *HardwareError if hardware validation is
+// enabled and sku isn't available. An empty sku leaves the choice to the
+// API.
+func (r *Client) validateHardware(ctx context.Context, sku string) error {
+	if r.options.hardwareTTL <= 0 || sku == "" {
+		return nil
+	}
+
+	skus, err := r.hardwareSKUs(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
+		r.log(ctx, slog.LevelWarn, "not validating hardware", slog.String("hardware", sku), r.errorAttr(err))
+		return nil
+	}
+	if !slices.Contains(skus, sku) {
+		return &HardwareError{SKU: sku, Valid: skus}
+	}
+	return nil
+}
+
+// hardwareSKUs returns the cached hardware SKUs, listing them again when
+// they're older than the TTL.
+func (r *Client) hardwareSKUs(ctx context.Context) ([]string, error) {
+	c := &r.state.hardware
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := r.clock().Now()
+	if c.skus != nil && now.Sub(c.fetched) < r.options.hardwareTTL {
+		return c.skus, nil
+	}
+
+	hardware, err := r.ListHardware(ctx)
+	if err != nil {
+		return nil, err
+	}
+	skus := make([]string, 0, len(*hardware))
+	for _, h := range *hardware {
+		skus = append(skus, h.SKU)
+	}
+	slices.Sort(skus)
+	c.skus = skus
+	c.fetched = now
+	return skus, nil
+}
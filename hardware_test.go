@@ -0,0 +1,100 @@
+package replicate_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestHardwareValidation(t *testing.T) {
+	var listed, created atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hardware":
+			listed.Add(1)
+			w.Write([]byte(`[{"sku": "gpu-t4", "name": "Nvidia T4 GPU"}, {"sku": "cpu", "name": "CPU"}]`))
+		case "/models":
+			created.Add(1)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"owner": "acme", "name": "hotdog"}`))
+		case "/deployments", "/deployments/acme/hotdog":
+			created.Add(1)
+			w.Write([]byte(`{"owner": "acme", "name": "hotdog"}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	clock := replicatetest.NewFakeClock(time.Now())
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithClock(clock),
+		replicate.WithHardwareValidation(time.Hour),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = client.CreateModel(ctx, "acme", "hotdog", replicate.CreateModelOptions{Visibility: "private", Hardware: "gpu-t5"})
+	var hardwareErr *replicate.HardwareError
+	require.ErrorAs(t, err, &hardwareErr)
+	assert.ErrorIs(t, err, replicate.ErrValidation)
+	assert.Equal(t, "gpu-t5", hardwareErr.SKU)
+	assert.Equal(t, []string{"cpu", "gpu-t4"}, hardwareErr.Valid)
+	assert.Contains(t, err.Error(), `invalid hardware "gpu-t5": must be one of cpu, gpu-t4`)
+	assert.Equal(t, int32(0), created.Load())
+
+	_, err = client.CreateModel(ctx, "acme", "hotdog", replicate.CreateModelOptions{Visibility: "private", Hardware: "gpu-t4"})
+	require.NoError(t, err)
+	_, err = client.CreateDeployment(ctx, replicate.CreateDeploymentOptions{Name: "hotdog", Model: "acme/hotdog", Hardware: "cpu"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), listed.Load(), "hardware list should be cached")
+
+	hardware := "gpu-a100"
+	_, err = client.UpdateDeployment(ctx, "acme", "hotdog", replicate.UpdateDeploymentOptions{Hardware: &hardware})
+	assert.ErrorAs(t, err, &hardwareErr)
+	_, err = client.UpdateDeployment(ctx, "acme", "hotdog", replicate.UpdateDeploymentOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), created.Load())
+
+	clock.Advance(time.Hour)
+	_, err = client.CreateDeployment(ctx, replicate.CreateDeploymentOptions{Name: "hotdog", Model: "acme/hotdog", Hardware: "cpu"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), listed.Load(), "hardware list should be refreshed after the TTL")
+}
+
+func TestHardwareValidationListFails(t *testing.T) {
+	var created atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hardware" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"detail": "forbidden"}`))
+			return
+		}
+		created.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"owner": "acme", "name": "hotdog"}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithHardwareValidation(time.Hour),
+	)
+	require.NoError(t, err)
+
+	_, err = client.CreateModel(context.Background(), "acme", "hotdog", replicate.CreateModelOptions{Visibility: "private", Hardware: "gpu-t4"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), created.Load())
+}
@@ -0,0 +1,129 @@
+package replicate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithHedgedReads enables hedged GET requests, such as GetPrediction and
+// GetModel. If no response arrives within delay, a second identical request
+// is sent and whichever responds first is used. This trades a small amount of
+// extra traffic for lower tail latency on unreliable networks.
+//
+// With WithMaxConcurrentRequests, the second request needs a free request
+// slot of its own, and the request isn't hedged if none is free.
+func WithHedgedReads(delay time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if delay <= 0 {
+			return fmt.Errorf("hedge delay must be positive, got %s", delay)
+		}
+		o.hedgeDelay = delay
+		return nil
+	}
+}
+
+// send sends a single attempt of a request, hedging it if enabled.
+func (r *Client) send(request *http.Request) (*http.Response, error) {
+	var response *http.Response
+	var err error
+	if r.options.hedgeDelay <= 0 || request.Method != http.MethodGet {
+		response, err = r.c.Do(request)
+	} else {
+		response, err = r.sendHedged(request, r.options.hedgeDelay)
+	}
+	if err != nil {
+		return response, err
+	}
+
+	decompressResponse(response)
+	return response, nil
+}
+
+type hedgeResult struct {
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+func (r *Client) sendHedged(request *http.Request, delay time.Duration) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	// release frees the request slot taken for the attempt, once it's done.
+	launch := func(release func()) {
+		ctx, cancel := context.WithCancel(request.Context())
+		resp, err := r.c.Do(request.Clone(ctx)) //nolint:bodyclose
+		results <- hedgeResult{resp: resp, err: err, cancel: sync.OnceFunc(func() {
+			cancel()
+			release()
+		})}
+	}
+
+	go launch(func() {})
+	launched := 1
+
+	timer := r.clock().NewTimer(delay)
+	defer timer.Stop()
+
+	var firstErr error
+	for received := 0; received < launched; {
+		select {
+		case <-timer.C():
+			// The first attempt holds the slot taken by do. Don't wait for
+			// another, since the first attempt may finish in the meantime.
+			release, ok := r.tryAcquire()
+			if !ok {
+				continue
+			}
+			go launch(release)
+			launched++
+		case res := <-results:
+			received++
+			if res.err == nil {
+				if pending := launched - received; pending > 0 {
+					go discardHedged(results, pending)
+				}
+				res.resp.Body = &cancelOnClose{ReadCloser: res.resp.Body, cancel: res.cancel}
+				return res.resp, nil
+			}
+
+			res.cancel()
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if launched == 1 {
+				// Don't hedge a request that already failed; let the retry
+				// policy decide what to do with it.
+				return nil, res.err
+			}
+		}
+	}
+
+	return nil, firstErr
+}
+
+// discardHedged cleans up the attempts that lost the race.
+func discardHedged(results <-chan hedgeResult, n int) {
+	for i := 0; i < n; i++ {
+		res := <-results
+		res.cancel()
+		if res.resp != nil {
+			res.resp.Body.Close()
+		}
+	}
+}
+
+// cancelOnClose cancels the context of a hedged attempt once its body has
+// been consumed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,77 @@
+package replicate
+
+import (
+	"net/http"
+	"time"
+)
+
+// CallEvent describes a request made by the client, for lifecycle hooks.
+type CallEvent struct {
+	// Operation is the name of the client method that made the request, such
+	// as "CreatePrediction".
+	Operation string
+
+	// Request is the request being sent. Hooks must not modify it or read
+	// its body.
+	Request *http.Request
+
+	// Attempt is the number of the attempt, starting at 1.
+	Attempt int
+
+	// Response is the response received, if any. Hooks must not read its
+	// body.
+	Response *http.Response
+
+	// Duration is the time spent on the attempt for OnResponse hooks, and
+	// on the whole call, including retries, for OnError and slow call hooks.
+	Duration time.Duration
+
+	// Err is the error returned by the call, for OnError and slow call
+	// hooks.
+	Err error
+}
+
+// CallHook is a function called at a point in the lifecycle of a request.
+// Hooks are called synchronously and should return quickly.
+type CallHook func(event CallEvent)
+
+type callHooks struct {
+	onRequest  []CallHook
+	onResponse []CallHook
+	onError    []CallHook
+}
+
+// WithOnRequest adds a hook that is called before each attempt of a request
+// is sent.
+func WithOnRequest(hook CallHook) ClientOption {
+	return func(o *clientOptions) error {
+		o.hooks.onRequest = append(o.hooks.onRequest, hook)
+		return nil
+	}
+}
+
+// WithOnResponse adds a hook that is called for each response received,
+// whatever its status code.
+func WithOnResponse(hook CallHook) ClientOption {
+	return func(o *clientOptions) error {
+		o.hooks.onResponse = append(o.hooks.onResponse, hook)
+		return nil
+	}
+}
+
+// WithOnError adds a hook that is called when a call returns an error, after
+// any retries.
+func WithOnError(hook CallHook) ClientOption {
+	return func(o *clientOptions) error {
+		o.hooks.onError = append(o.hooks.onError, hook)
+		return nil
+	}
+}
+
+func runHooks(hooks []CallHook, event CallEvent) {
+	for _, hook := range hooks {
+		if hook != nil {
+			hook(event)
+		}
+	}
+}
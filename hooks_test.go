@@ -0,0 +1,58 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestLifecycleHooks(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/predictions/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail": "Not found."}`))
+			return
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	var requests, responses, errs []replicate.CallEvent
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithOnRequest(func(e replicate.CallEvent) { requests = append(requests, e) }),
+		replicate.WithOnResponse(func(e replicate.CallEvent) { responses = append(responses, e) }),
+		replicate.WithOnError(func(e replicate.CallEvent) { errs = append(errs, e) }),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetPrediction(context.Background(), "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+
+	_, err = client.GetPrediction(context.Background(), "missing")
+	require.Error(t, err)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, "GetPrediction", requests[0].Operation)
+	assert.Equal(t, 1, requests[0].Attempt)
+	assert.Nil(t, requests[0].Response)
+
+	require.Len(t, responses, 2)
+	assert.Equal(t, http.StatusOK, responses[0].Response.StatusCode)
+	assert.Equal(t, http.StatusNotFound, responses[1].Response.StatusCode)
+	assert.Positive(t, responses[0].Duration)
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "GetPrediction", errs[0].Operation)
+	assert.Equal(t, 1, errs[0].Attempt)
+	assert.Equal(t, "/predictions/missing", errs[0].Request.URL.Path)
+	assert.ErrorIs(t, errs[0].Err, replicate.ErrNotFound)
+}
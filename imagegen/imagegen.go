@@ -0,0 +1,252 @@
+// Package imagegen runs text-to-image models on Replicate, hiding the input
+// conventions of the popular model families behind one set of options.
+package imagegen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register the GIF decoder for Result.Images.
+	_ "image/jpeg" // Register the JPEG decoder for Result.Images.
+	_ "image/png"  // Register the PNG decoder for Result.Images.
+	"maps"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// Options configure Generate. Zero values leave the model's defaults.
+type Options struct {
+	// Width and Height are the size of the images, in pixels.
+	Width  int
+	Height int
+
+	// Seed makes generation reproducible. Nil uses a random seed.
+	Seed *int
+
+	// NumOutputs is the number of images to generate.
+	NumOutputs int
+
+	// NegativePrompt describes what the images shouldn't contain, for
+	// models that support it.
+	NegativePrompt string
+
+	// Format renders the prompt and options as model input. The default
+	// is chosen from the model's name: FluxFormat for FLUX models, and
+	// SDXLFormat for others.
+	Format InputFormat
+
+	// Input holds extra model inputs, which take precedence over those
+	// set from the other options.
+	Input replicate.PredictionInput
+
+	// PollingInterval is how often the prediction is polled until it
+	// finishes. The default is the same as for Client.Wait.
+	PollingInterval time.Duration
+}
+
+// InputFormat renders a prompt and options as the input of a model.
+type InputFormat func(prompt string, opts Options) replicate.PredictionInput
+
+// SDXLFormat sets the inputs of Stable Diffusion models: prompt,
+// negative_prompt, width, height, seed, and num_outputs.
+func SDXLFormat(prompt string, opts Options) replicate.PredictionInput {
+	input := replicate.PredictionInput{"prompt": prompt}
+	if opts.NegativePrompt != "" {
+		input["negative_prompt"] = opts.NegativePrompt
+	}
+	if opts.Width > 0 {
+		input["width"] = opts.Width
+	}
+	if opts.Height > 0 {
+		input["height"] = opts.Height
+	}
+	setCommon(input, opts)
+	return input
+}
+
+// FluxFormat sets the inputs of FLUX models, which take an aspect ratio
+// rather than a size: the aspect ratio they support that is closest to
+// Width by Height is used. It asks for PNG output, since FLUX models
+// default to WebP, which Result.Images can't decode.
+func FluxFormat(prompt string, opts Options) replicate.PredictionInput {
+	input := replicate.PredictionInput{"prompt": prompt, "output_format": "png"}
+	if opts.Width > 0 && opts.Height > 0 {
+		input["aspect_ratio"] = closestAspectRatio(opts.Width, opts.Height)
+	}
+	setCommon(input, opts)
+	return input
+}
+
+func setCommon(input replicate.PredictionInput, opts Options) {
+	if opts.Seed != nil {
+		input["seed"] = *opts.Seed
+	}
+	if opts.NumOutputs > 0 {
+		input["num_outputs"] = opts.NumOutputs
+	}
+}
+
+var fluxAspectRatios = []string{"1:1", "16:9", "21:9", "3:2", "2:3", "4:5", "5:4", "3:4", "4:3", "9:16", "9:21"}
+
+func closestAspectRatio(width, height int) string {
+	target := math.Log(float64(width) / float64(height))
+	best, bestDiff := fluxAspectRatios[0], math.Inf(1)
+	for _, ratio := range fluxAspectRatios {
+		var w, h float64
+		fmt.Sscanf(ratio, "%g:%g", &w, &h)
+		if diff := math.Abs(math.Log(w/h) - target); diff < bestDiff {
+			best, bestDiff = ratio, diff
+		}
+	}
+	return best
+}
+
+// formatFor returns the input format of a model, from its name.
+func formatFor(model replicate.ModelRef) InputFormat {
+	name := model.Deployment
+	if name == "" {
+		name = model.Identifier
+	}
+	if strings.Contains(strings.ToLower(name), "flux") {
+		return FluxFormat
+	}
+	return SDXLFormat
+}
+
+// Result is the result of Generate.
+type Result struct {
+	// URLs are the URLs of the generated images. They may be data URIs.
+	URLs []string
+
+	// Prediction is the finished prediction.
+	Prediction *replicate.Prediction
+
+	client *replicate.Client
+}
+
+// Generate runs a text-to-image model on prompt and returns the URLs of the
+// images it generated, which Result.Images decodes and Result.Save writes
+// to disk. If the prediction doesn't succeed, the error is a
+// *replicate.ModelError.
+func Generate(ctx context.Context, client *replicate.Client, model replicate.ModelRef, prompt string, opts Options) (*Result, error) {
+	format := opts.Format
+	if format == nil {
+		format = formatFor(model)
+	}
+	input := format(prompt, opts)
+	maps.Copy(input, opts.Input)
+
+	var waitOpts []replicate.WaitOption
+	if opts.PollingInterval > 0 {
+		waitOpts = append(waitOpts, replicate.WithPollingInterval(opts.PollingInterval))
+	}
+	prediction, err := client.RunModelRef(ctx, model, input, nil, waitOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	urls, err := outputURLs(prediction.Output)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected output of prediction %s: %w", prediction.ID, err)
+	}
+	return &Result{URLs: urls, Prediction: prediction, client: client}, nil
+}
+
+// outputURLs normalizes the output of an image model, which is a URL or a
+// list of URLs.
+func outputURLs(output replicate.PredictionOutput) ([]string, error) {
+	switch v := output.(type) {
+	case string:
+		return []string{v}, nil
+	case []any:
+		urls := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a URL, got %T", item)
+			}
+			urls = append(urls, s)
+		}
+		if len(urls) == 0 {
+			return nil, errors.New("no images")
+		}
+		return urls, nil
+	}
+	return nil, fmt.Errorf("expected a URL or a list of URLs, got %T", output)
+}
+
+// Download returns the contents of the images, in order.
+func (r *Result) Download(ctx context.Context) ([][]byte, error) {
+	result := r.client.DownloadFiles(ctx, r.URLs)
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+	return result.Values(), nil
+}
+
+// Images downloads and decodes the images. PNG, JPEG, and GIF images are
+// supported, and other formats can be registered with image.RegisterFormat.
+func (r *Result) Images(ctx context.Context) ([]image.Image, error) {
+	files, err := r.Download(ctx)
+	if err != nil {
+		return nil, err
+	}
+	images := make([]image.Image, len(files))
+	for i, data := range files {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image %d: %w", i, err)
+		}
+		images[i] = img
+	}
+	return images, nil
+}
+
+// Save downloads the images into dir, which must exist, and returns their
+// paths. The files are named after the prediction, with the extension of
+// the image format.
+func (r *Result) Save(ctx context.Context, dir string) ([]string, error) {
+	files, err := r.Download(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(files))
+	for i, data := range files {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%s-%d%s", r.Prediction.ID, i, extension(r.URLs[i], data)))
+		if err := os.WriteFile(paths[i], data, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to save image %d: %w", i, err)
+		}
+	}
+	return paths, nil
+}
+
+// extension returns the file extension of an image, from its URL or, for
+// data URIs and URLs without one, its contents.
+func extension(rawURL string, data []byte) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Scheme != "data" {
+		if ext := path.Ext(u.Path); ext != "" {
+			return ext
+		}
+	}
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ""
+}
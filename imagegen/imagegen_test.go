@@ -0,0 +1,103 @@
+package imagegen_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/imagegen"
+)
+
+func TestGenerate(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	var encoded bytes.Buffer
+	require.NoError(t, png.Encode(&encoded, img))
+
+	var body struct {
+		Input replicate.PredictionInput `json:"input"`
+	}
+	mockServer := httptest.NewUnstartedServer(nil)
+	mockServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/models/black-forest-labs/flux-schnell/predictions":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "gm3qorzdhgbfurvjtvhg6dckhu", Status: replicate.Starting})
+		case r.URL.Path == "/predictions/gm3qorzdhgbfurvjtvhg6dckhu":
+			json.NewEncoder(w).Encode(&replicate.Prediction{
+				ID:     "gm3qorzdhgbfurvjtvhg6dckhu",
+				Status: replicate.Succeeded,
+				Output: []any{mockServer.URL + "/output/out-0"},
+			})
+		case r.URL.Path == "/output/out-0":
+			w.Write(encoded.Bytes())
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	mockServer.Start()
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	seed := 0
+	result, err := imagegen.Generate(context.Background(), client, replicate.ModelRef{Identifier: "black-forest-labs/flux-schnell"}, "a red pixel", imagegen.Options{
+		Width:           1920,
+		Height:          1080,
+		Seed:            &seed,
+		NumOutputs:      1,
+		PollingInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, replicate.PredictionInput{
+		"prompt":        "a red pixel",
+		"aspect_ratio":  "16:9",
+		"output_format": "png",
+		"seed":          float64(0),
+		"num_outputs":   float64(1),
+	}, body.Input)
+	assert.Equal(t, []string{mockServer.URL + "/output/out-0"}, result.URLs)
+
+	images, err := result.Images(context.Background())
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, img.Bounds(), images[0].Bounds())
+	r, _, _, _ := images[0].At(1, 1).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+
+	dir := t.TempDir()
+	paths, err := result.Save(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "gm3qorzdhgbfurvjtvhg6dckhu-0.png")}, paths)
+	saved, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, encoded.Bytes(), saved)
+}
+
+func TestSDXLFormat(t *testing.T) {
+	input := imagegen.SDXLFormat("a cat", imagegen.Options{Width: 1024, Height: 768, NegativePrompt: "blurry"})
+	assert.Equal(t, replicate.PredictionInput{
+		"prompt":          "a cat",
+		"negative_prompt": "blurry",
+		"width":           1024,
+		"height":          768,
+	}, input)
+}
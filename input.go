@@ -0,0 +1,181 @@
+package replicate
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// maxInputDepth limits how deeply nested inputs are checked.
+const maxInputDepth = 64
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// InputError describes an input value that can't be sent to the API.
+//
+// InputError matches ErrValidation.
+type InputError struct {
+	// Key is the path of the offending value, such as "prompt" or
+	// "images[2]".
+	Key string
+
+	// Reason explains what is wrong with the value.
+	Reason string
+
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (e *InputError) Error() string {
+	return fmt.Sprintf("invalid input %q: %s", e.Key, e.Reason)
+}
+
+func (e *InputError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// Validate reports values that can't be encoded as JSON, such as channels,
+// functions, and NaN or infinite floats. The returned error is an
+// *InputError naming the offending key.
+func (i PredictionInput) Validate() error {
+	for key, value := range i {
+		if err := validateInputValue(key, reflect.ValueOf(value), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateInputValue(path string, v reflect.Value, depth int) error {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > maxInputDepth {
+		return &InputError{Key: path, Reason: "value is nested too deeply"}
+	}
+
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return &InputError{Key: path, Reason: fmt.Sprintf("unsupported type %s", v.Type())}
+	case reflect.Complex64, reflect.Complex128:
+		return &InputError{Key: path, Reason: fmt.Sprintf("unsupported type %s", v.Type())}
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) {
+			return &InputError{Key: path, Reason: "NaN is not a valid JSON number"}
+		}
+		if math.IsInf(f, 0) {
+			return &InputError{Key: path, Reason: "infinity is not a valid JSON number"}
+		}
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return validateInputValue(path, v.Elem(), depth+1)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			// Byte slices are encoded as base64 strings.
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := validateInputValue(fmt.Sprintf("%s[%d]", path, i), v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if err := validateInputValue(path+"."+key, iter.Value(), depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if err := validateInputValue(path+"."+name, v.Field(i), depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveFileInputs returns a copy of input with File values replaced by
+// their "get" URL. Files without a URL are refreshed from the API.
+func (r *Client) resolveFileInputs(ctx context.Context, input PredictionInput) (PredictionInput, error) {
+	if input == nil {
+		return nil, nil
+	}
+
+	resolved := make(PredictionInput, len(input))
+	for key, value := range input {
+		var file *File
+		switch v := value.(type) {
+		case *File:
+			if v == nil {
+				return nil, &InputError{Key: key, Reason: "file is nil"}
+			}
+			file = v
+		case File:
+			file = &v
+		default:
+			resolved[key] = value
+			continue
+		}
+
+		url, err := r.fileURL(ctx, file)
+		if err != nil {
+			return nil, &InputError{Key: key, Reason: err.Error(), Err: err}
+		}
+		resolved[key] = url
+	}
+
+	return resolved, nil
+}
+
+func (r *Client) fileURL(ctx context.Context, file *File) (string, error) {
+	if url := file.URLs["get"]; url != "" {
+		return url, nil
+	}
+	if file.ID == "" {
+		return "", errors.New("file has no ID or URL")
+	}
+
+	refreshed, err := r.GetFile(ctx, file.ID)
+	if err != nil {
+		return "", fmt.Errorf("file %s has no URL and could not be refreshed: %w", file.ID, err)
+	}
+	if url := refreshed.URLs["get"]; url != "" {
+		return url, nil
+	}
+	return "", fmt.Errorf("file %s has no URL", file.ID)
+}
@@ -0,0 +1,109 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestPredictionInputValidate(t *testing.T) {
+	type options struct {
+		Scale float64 `json:"scale"`
+	}
+
+	tests := []struct {
+		name  string
+		input replicate.PredictionInput
+		key   string
+	}{
+		{"valid", replicate.PredictionInput{"prompt": "hi", "seed": 42, "bytes": []byte("x"), "nil": nil}, ""},
+		{"channel", replicate.PredictionInput{"ch": make(chan int)}, "ch"},
+		{"func", replicate.PredictionInput{"fn": func() {}}, "fn"},
+		{"NaN", replicate.PredictionInput{"temperature": math.NaN()}, "temperature"},
+		{"infinity in slice", replicate.PredictionInput{"weights": []float64{1, math.Inf(1)}}, "weights[1]"},
+		{"nested map", replicate.PredictionInput{"config": map[string]interface{}{"top_p": math.Inf(-1)}}, "config.top_p"},
+		{"struct field", replicate.PredictionInput{"options": &options{Scale: math.NaN()}}, "options.scale"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.input.Validate()
+			if tt.key == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var inputErr *replicate.InputError
+			require.ErrorAs(t, err, &inputErr)
+			assert.Equal(t, tt.key, inputErr.Key)
+			assert.ErrorIs(t, err, replicate.ErrValidation)
+		})
+	}
+}
+
+func TestCreatePredictionRejectsInvalidInput(t *testing.T) {
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL("http://127.0.0.1:0"),
+	)
+	require.NoError(t, err)
+
+	_, err = client.CreatePrediction(context.Background(), "owner/model", replicate.PredictionInput{"temperature": math.NaN()}, nil, false)
+	assert.EqualError(t, err, `invalid input "temperature": NaN is not a valid JSON number`)
+}
+
+func TestCreatePredictionWithFileInputs(t *testing.T) {
+	var body map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files/stale":
+			json.NewEncoder(w).Encode(&replicate.File{
+				ID:   "stale",
+				URLs: map[string]string{"get": "https://api.replicate.com/v1/files/stale/download"},
+			})
+		case "/files/gone":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail": "Not found."}`))
+		case "/predictions":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+		default:
+			t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	input := replicate.PredictionInput{
+		"image": replicate.File{URLs: map[string]string{"get": "https://example.com/image.png"}},
+		"mask":  &replicate.File{ID: "stale"},
+	}
+	_, err = client.CreatePrediction(ctx, "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"image": "https://example.com/image.png",
+		"mask":  "https://api.replicate.com/v1/files/stale/download",
+	}, body["input"])
+	assert.IsType(t, &replicate.File{}, input["mask"], "input should not be modified")
+
+	var nilFile *replicate.File
+	_, err = client.CreatePrediction(ctx, "owner/model", replicate.PredictionInput{"image": nilFile}, nil, false)
+	assert.EqualError(t, err, `invalid input "image": file is nil`)
+
+	_, err = client.CreatePrediction(ctx, "owner/model", replicate.PredictionInput{"image": &replicate.File{ID: "gone"}}, nil, false)
+	assert.ErrorIs(t, err, replicate.ErrNotFound)
+}
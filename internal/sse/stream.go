@@ -20,6 +20,13 @@ type Streamer struct {
 	maxRetries int
 	backoff    Backoff
 
+	// OnReconnect, if set, is called before reconnecting after the
+	// connection was closed.
+	OnReconnect func()
+
+	// Sleep, if set, waits between connection attempts instead of a timer.
+	Sleep func(ctx context.Context, d time.Duration) error
+
 	attempt     int
 	lastEventID string
 
@@ -36,6 +43,21 @@ func NewStreamer(c *http.Client, url string, maxRetries int, backoff Backoff) *S
 	}
 }
 
+func (s *Streamer) sleep(ctx context.Context, d time.Duration) error {
+	if s.Sleep != nil {
+		return s.Sleep(ctx, d)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 var ErrMaximumRetries = errors.New("Exceeded maximum retries")
 
 // connect (re-)establishes the connection to the SSE server. It only returns an
@@ -52,13 +74,8 @@ func (s *Streamer) connect(ctx context.Context) error {
 			delay = s.backoff.NextDelay(s.attempt - 1)
 		}
 		s.attempt++
-		reconnectDelay := time.NewTimer(delay)
-		// once we only support go 1.23+, we can use time.After() here and simplify
-		defer reconnectDelay.Stop()
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-reconnectDelay.C:
+		if err := s.sleep(ctx, delay); err != nil {
+			return err
 		}
 
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
@@ -104,6 +121,9 @@ func (s *Streamer) NextEvent(ctx context.Context) (*Event, error) {
 		e, err := s.decoder.Next()
 		if err != nil {
 			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				if s.OnReconnect != nil {
+					s.OnReconnect()
+				}
 				if err = s.connect(ctx); err != nil {
 					return nil, err
 				}
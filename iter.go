@@ -0,0 +1,78 @@
+//go:build go1.23
+
+package replicate
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// PaginatePredictions returns an iterator over all predictions, fetching
+// pages as needed.
+//
+// If fetching a page fails, the iterator yields the error and stops. Use
+// WithCursor to resume from a page returned by an earlier listing.
+func (r *Client) PaginatePredictions(ctx context.Context, opts ...ListOption) iter.Seq2[Prediction, error] {
+	return paginateSeq[Prediction](ctx, r, "/predictions", opts)
+}
+
+// PaginateTrainings returns an iterator over all trainings, fetching pages
+// as needed.
+func (r *Client) PaginateTrainings(ctx context.Context, opts ...ListOption) iter.Seq2[Training, error] {
+	return paginateSeq[Training](ctx, r, "/trainings", opts)
+}
+
+// PaginateModels returns an iterator over all public models, fetching pages
+// as needed.
+func (r *Client) PaginateModels(ctx context.Context, opts ...ListOption) iter.Seq2[Model, error] {
+	return paginateSeq[Model](ctx, r, "/models", opts)
+}
+
+// PaginateModelVersions returns an iterator over the versions of a model,
+// fetching pages as needed.
+func (r *Client) PaginateModelVersions(ctx context.Context, modelOwner string, modelName string, opts ...ListOption) iter.Seq2[ModelVersion, error] {
+	return paginateSeq[ModelVersion](ctx, r, fmt.Sprintf("/models/%s/%s/versions", modelOwner, modelName), opts)
+}
+
+// PaginateDeployments returns an iterator over all deployments, fetching
+// pages as needed.
+func (r *Client) PaginateDeployments(ctx context.Context, opts ...ListOption) iter.Seq2[Deployment, error] {
+	return paginateSeq[Deployment](ctx, r, "/deployments", opts)
+}
+
+// PaginateFiles returns an iterator over all files, fetching pages as
+// needed.
+func (r *Client) PaginateFiles(ctx context.Context, opts ...ListOption) iter.Seq2[File, error] {
+	return paginateSeq[File](ctx, r, "/files", opts)
+}
+
+// PaginateCollections returns an iterator over all collections, fetching
+// pages as needed.
+func (r *Client) PaginateCollections(ctx context.Context, opts ...ListOption) iter.Seq2[Collection, error] {
+	return paginateSeq[Collection](ctx, r, "/collections", opts)
+}
+
+func paginateSeq[T any](ctx context.Context, client *Client, path string, opts []ListOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		options, err := newListOptions(opts)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+
+		s := newSweep[T](client, options, nil, options.path(path))
+		defer s.stop()
+		for {
+			item, ok, err := s.next(ctx)
+			if err != nil {
+				yield(item, err)
+				return
+			}
+			if !ok || !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+//go:build go1.23
+
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestPaginatePredictions(t *testing.T) {
+	var serverURL string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/predictions", r.URL.Path)
+
+		page := &replicate.Page[replicate.Prediction]{}
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			next := serverURL + "/predictions?cursor=2"
+			page.Next = &next
+			page.Results = []replicate.Prediction{{ID: "a"}, {ID: "b"}}
+		case "2":
+			next := serverURL + "/predictions?cursor=3"
+			page.Next = &next
+			page.Results = []replicate.Prediction{{ID: "c"}}
+		case "3":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"detail": "boom"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer mockServer.Close()
+	serverURL = mockServer.URL
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+
+	var ids []string
+	var iterErr error
+	for prediction, err := range client.PaginatePredictions(context.Background()) {
+		if err != nil {
+			iterErr = err
+			break
+		}
+		ids = append(ids, prediction.ID)
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, ids)
+	assert.ErrorContains(t, iterErr, "boom")
+
+	ids = nil
+	for prediction, err := range client.PaginatePredictions(context.Background()) {
+		require.NoError(t, err)
+		ids = append(ids, prediction.ID)
+		if len(ids) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"a", "b"}, ids)
+}
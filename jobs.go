@@ -0,0 +1,311 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrJobNotFound is returned by JobStore.LoadJob for unknown jobs.
+var ErrJobNotFound = errors.New("job not found")
+
+// Job is a durable record of a prediction run by a Supervisor, or of an
+// item of a checkpointed batch.
+type Job struct {
+	// ID identifies the job. It's chosen by the caller, and is also used as
+	// the idempotency key when creating the prediction.
+	ID string
+
+	// Identifier is the model identifier to run, in the form "owner/name"
+	// or "owner/name:version".
+	Identifier string
+
+	// Input is the input of the prediction.
+	Input PredictionInput
+
+	// PredictionID is the ID of the prediction, once it's been created.
+	PredictionID string
+
+	// Status is the last known status of the prediction.
+	Status Status
+
+	// Output is the output of the prediction. It's only set for the items
+	// of checkpointed batches; see BatchOptions.Checkpoint.
+	Output PredictionOutput
+
+	// Handled is true once the job's handler has succeeded. Jobs that
+	// aren't handled are resumed by Supervisor.Start.
+	Handled bool
+
+	// Checkpoint is true for the records of checkpointed batch items,
+	// which aren't run by a Supervisor. They're saved handled, so they
+	// aren't listed as incomplete; see BatchOptions.Checkpoint.
+	Checkpoint bool
+
+	// Attempts is the number of times the handler has been called.
+	Attempts int
+
+	// LastError is the error from the last failed attempt to run or handle
+	// the job, if any.
+	LastError string
+
+	// CreatedAt and UpdatedAt are when the job was submitted and last
+	// saved.
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (j *Job) clone() *Job {
+	c := *j
+	c.Input = maps.Clone(j.Input)
+	return &c
+}
+
+// JobStore persists jobs for a Supervisor. Implementations must be safe for
+// concurrent use. Back it with a database to survive restarts.
+type JobStore interface {
+	// SaveJob creates or replaces the job with the same ID.
+	SaveJob(ctx context.Context, job *Job) error
+
+	// LoadJob returns the job with the given ID, or an error matching
+	// ErrJobNotFound.
+	LoadJob(ctx context.Context, id string) (*Job, error)
+
+	// ListIncompleteJobs returns the jobs that haven't been handled.
+	ListIncompleteJobs(ctx context.Context) ([]*Job, error)
+}
+
+// MemoryJobStore is a JobStore that keeps jobs in memory, for tests and
+// single-process use. Jobs don't survive a restart.
+type MemoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+var _ JobStore = (*MemoryJobStore)(nil)
+
+// NewMemoryJobStore returns an empty MemoryJobStore.
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{jobs: map[string]*Job{}}
+}
+
+// SaveJob stores a copy of job.
+func (s *MemoryJobStore) SaveJob(_ context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job.clone()
+	return nil
+}
+
+// LoadJob returns a copy of the job with the given ID.
+func (s *MemoryJobStore) LoadJob(_ context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return job.clone(), nil
+}
+
+// ListIncompleteJobs returns copies of the jobs that haven't been handled,
+// oldest first.
+func (s *MemoryJobStore) ListIncompleteJobs(_ context.Context) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var jobs []*Job
+	for _, job := range s.jobs {
+		if !job.Handled {
+			jobs = append(jobs, job.clone())
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+// JobHandler processes the finished prediction of a job, whatever its
+// status. If it returns an error, the job stays incomplete and is handled
+// again when the supervisor next starts, so handlers must be idempotent.
+type JobHandler func(ctx context.Context, job *Job, prediction *Prediction) error
+
+// Supervisor runs predictions as durable jobs, for at-least-once processing
+// that survives restarts and deploys.
+//
+// Jobs are saved to a JobStore before their prediction is created, and
+// after every change. When the supervisor starts, it re-attaches to the
+// predictions of jobs that weren't handled, creating any that weren't
+// created, and calls the handler once each finishes.
+type Supervisor struct {
+	client  *Client
+	store   JobStore
+	handle  JobHandler
+	options []WaitOption
+
+	mu     sync.Mutex
+	ctx    context.Context
+	active map[string]bool
+	wg     sync.WaitGroup
+	errs   []error
+}
+
+// NewSupervisor returns a supervisor that runs jobs with client, saves them
+// to store, and calls handle as their predictions finish. opts configure
+// how predictions are waited for, as for Wait.
+func NewSupervisor(client *Client, store JobStore, handle JobHandler, opts ...WaitOption) *Supervisor {
+	return &Supervisor{
+		client:  client,
+		store:   store,
+		handle:  handle,
+		options: opts,
+		active:  map[string]bool{},
+	}
+}
+
+// Start resumes the incomplete jobs in the store. Jobs are watched in the
+// background until they're handled or ctx is done.
+func (s *Supervisor) Start(ctx context.Context) error {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	jobs, err := s.store.ListIncompleteJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list incomplete jobs: %w", err)
+	}
+	for _, job := range jobs {
+		if job.Checkpoint {
+			continue
+		}
+		s.watch(job)
+	}
+	return nil
+}
+
+// Submit saves a new job and starts running it. It must be called after
+// Start. If a job with the same ID exists, it's returned instead, so
+// submitting a job again is safe.
+//
+// ctx is only used to save the job. The job itself runs until it's handled
+// or the context passed to Start is done.
+func (s *Supervisor) Submit(ctx context.Context, id, identifier string, input PredictionInput) (*Job, error) {
+	if id == "" {
+		return nil, errors.New("job ID must not be empty")
+	}
+
+	s.mu.Lock()
+	started := s.ctx != nil
+	s.mu.Unlock()
+	if !started {
+		return nil, errors.New("supervisor isn't started")
+	}
+
+	existing, err := s.store.LoadJob(ctx, id)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, ErrJobNotFound) {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+
+	now := s.client.clock().Now()
+	job := &Job{ID: id, Identifier: identifier, Input: input, CreatedAt: now, UpdatedAt: now}
+	if err := s.store.SaveJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to save job: %w", err)
+	}
+	s.watch(job.clone())
+	return job, nil
+}
+
+// Wait blocks until every job being watched has been handled or has
+// stopped, and returns the errors that stopped them.
+func (s *Supervisor) Wait() error {
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := errors.Join(s.errs...)
+	s.errs = nil
+	return err
+}
+
+// watch runs a job in the background, unless it's already being watched.
+func (s *Supervisor) watch(job *Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active[job.ID] {
+		return
+	}
+	s.active[job.ID] = true
+	ctx, done := s.client.background(s.ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer done()
+		err := s.run(ctx, job)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.active, job.ID)
+		if err != nil {
+			s.errs = append(s.errs, fmt.Errorf("job %s: %w", job.ID, err))
+		}
+	}()
+}
+
+func (s *Supervisor) run(ctx context.Context, job *Job) error {
+	var prediction *Prediction
+	var err error
+	if job.PredictionID == "" {
+		prediction, err = s.client.CreatePrediction(WithIdempotencyKey(ctx, job.ID), job.Identifier, job.Input, nil, false)
+		if err != nil {
+			return s.fail(ctx, job, err)
+		}
+		job.PredictionID = prediction.ID
+		job.Status = prediction.Status
+		if err := s.save(ctx, job); err != nil {
+			return err
+		}
+	} else {
+		prediction = &Prediction{ID: job.PredictionID, Status: job.Status}
+	}
+
+	if err := s.client.Wait(ctx, prediction, s.options...); err != nil {
+		return err
+	}
+	job.Status = prediction.Status
+	if err := s.save(ctx, job); err != nil {
+		return err
+	}
+
+	job.Attempts++
+	if err := s.handle(ctx, job.clone(), prediction); err != nil {
+		return s.fail(ctx, job, err)
+	}
+	job.Handled = true
+	job.LastError = ""
+	return s.save(ctx, job)
+}
+
+// fail records err on the job, leaving it to be retried on the next start.
+func (s *Supervisor) fail(ctx context.Context, job *Job, err error) error {
+	job.LastError = err.Error()
+	if saveErr := s.save(ctx, job); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+	return err
+}
+
+func (s *Supervisor) save(ctx context.Context, job *Job) error {
+	job.UpdatedAt = s.client.clock().Now()
+	if err := s.store.SaveJob(ctx, job); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestSupervisor(t *testing.T) {
+	var mu sync.Mutex
+	idempotencyKeys := []string{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			key := r.Header.Get("Idempotency-Key")
+			mu.Lock()
+			idempotencyKeys = append(idempotencyKeys, key)
+			mu.Unlock()
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "prediction-" + key, Status: replicate.Starting})
+		case http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Succeeded, Output: id})
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	store := replicate.NewMemoryJobStore()
+	// Jobs left behind by a previous process: one whose prediction was
+	// created, one that stopped before creating it, and one already handled.
+	require.NoError(t, store.SaveJob(ctx, &replicate.Job{ID: "running", Identifier: "owner/model", PredictionID: "existing", Status: replicate.Processing}))
+	require.NoError(t, store.SaveJob(ctx, &replicate.Job{ID: "pending", Identifier: "owner/model"}))
+	require.NoError(t, store.SaveJob(ctx, &replicate.Job{ID: "done", Identifier: "owner/model", PredictionID: "old", Handled: true}))
+
+	failOnce := map[string]bool{"new": true}
+	handled := map[string]replicate.PredictionOutput{}
+	handle := func(_ context.Context, job *replicate.Job, prediction *replicate.Prediction) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if failOnce[job.ID] {
+			failOnce[job.ID] = false
+			return errors.New("downstream unavailable")
+		}
+		handled[job.ID] = prediction.Output
+		return nil
+	}
+
+	supervisor := replicate.NewSupervisor(client, store, handle, replicate.WithPollingInterval(time.Millisecond))
+	_, err = supervisor.Submit(ctx, "new", "owner/model", nil)
+	assert.ErrorContains(t, err, "supervisor isn't started")
+
+	require.NoError(t, supervisor.Start(ctx))
+	job, err := supervisor.Submit(ctx, "new", "owner/model", replicate.PredictionInput{"prompt": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "new", job.ID)
+
+	err = supervisor.Wait()
+	assert.ErrorContains(t, err, "job new: downstream unavailable")
+	assert.Equal(t, map[string]replicate.PredictionOutput{
+		"running": "existing",
+		"pending": "prediction-pending",
+	}, handled)
+	assert.ElementsMatch(t, []string{"pending", "new"}, idempotencyKeys)
+
+	failed, err := store.LoadJob(ctx, "new")
+	require.NoError(t, err)
+	assert.False(t, failed.Handled)
+	assert.Equal(t, "prediction-new", failed.PredictionID)
+	assert.Equal(t, "downstream unavailable", failed.LastError)
+
+	// After a restart, the failed job is handled again without creating
+	// another prediction.
+	supervisor = replicate.NewSupervisor(client, store, handle, replicate.WithPollingInterval(time.Millisecond))
+	require.NoError(t, supervisor.Start(ctx))
+	require.NoError(t, supervisor.Wait())
+	assert.Equal(t, "prediction-new", handled["new"])
+	assert.Len(t, idempotencyKeys, 2)
+
+	incomplete, err := store.ListIncompleteJobs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, incomplete)
+
+	existing, err := supervisor.Submit(ctx, "new", "owner/other", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "owner/model", existing.Identifier)
+	assert.Equal(t, 2, existing.Attempts)
+
+	_, err = store.LoadJob(ctx, "missing")
+	assert.ErrorIs(t, err, replicate.ErrJobNotFound)
+}
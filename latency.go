@@ -0,0 +1,163 @@
+package replicate
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyBounds are the upper bounds of the latency histogram buckets.
+var latencyBounds = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// LatencyBucket counts calls that took at most UpperBound, and longer than
+// the previous bucket's bound. The last bucket's UpperBound is zero and
+// counts all longer calls.
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// LatencyHistogram summarizes the durations of calls to an operation,
+// including retries.
+type LatencyHistogram struct {
+	Count   uint64
+	Sum     time.Duration
+	Max     time.Duration
+	Buckets []LatencyBucket
+}
+
+// Mean returns the mean call duration.
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile estimates the q-quantile of call durations, for q between 0 and
+// 1, as the upper bound of the bucket containing it. Calls longer than the
+// largest bound are estimated as Max.
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(h.Count))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen uint64
+	for _, b := range h.Buckets {
+		seen += b.Count
+		if seen >= rank {
+			if b.UpperBound == 0 || b.UpperBound > h.Max {
+				return h.Max
+			}
+			return b.UpperBound
+		}
+	}
+	return h.Max
+}
+
+type latencyHistogram struct {
+	count  uint64
+	sum    time.Duration
+	max    time.Duration
+	counts []uint64
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(latencyBounds)+1)
+	}
+	h.count++
+	h.sum += d
+	if d > h.max {
+		h.max = d
+	}
+	i := sort.Search(len(latencyBounds), func(i int) bool { return d <= latencyBounds[i] })
+	h.counts[i]++
+}
+
+func (h *latencyHistogram) snapshot() LatencyHistogram {
+	buckets := make([]LatencyBucket, len(h.counts))
+	for i, count := range h.counts {
+		buckets[i].Count = count
+		if i < len(latencyBounds) {
+			buckets[i].UpperBound = latencyBounds[i]
+		}
+	}
+	return LatencyHistogram{Count: h.count, Sum: h.sum, Max: h.max, Buckets: buckets}
+}
+
+// latencyTracker records call durations by operation.
+type latencyTracker struct {
+	mu         sync.Mutex
+	histograms map[string]*latencyHistogram
+}
+
+func (t *latencyTracker) observe(operation string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.histograms == nil {
+		t.histograms = make(map[string]*latencyHistogram)
+	}
+	h, ok := t.histograms[operation]
+	if !ok {
+		h = &latencyHistogram{}
+		t.histograms[operation] = h
+	}
+	h.observe(d)
+}
+
+// Latency returns a histogram of call durations for each operation the
+// client has called, keyed by the name of the client method, such as
+// "GetPrediction".
+func (r *Client) Latency() map[string]LatencyHistogram {
+	t := &r.state.latency
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot := make(map[string]LatencyHistogram, len(t.histograms))
+	for operation, h := range t.histograms {
+		snapshot[operation] = h.snapshot()
+	}
+	return snapshot
+}
+
+// WithSlowCallThreshold sets a hook that is called for each call that takes
+// longer than d, including retries. The event's Err is set if the call
+// failed.
+func WithSlowCallThreshold(d time.Duration, hook CallHook) ClientOption {
+	return func(o *clientOptions) error {
+		if d <= 0 {
+			return fmt.Errorf("slow call threshold must be positive, got %s", d)
+		}
+		o.slowCallThreshold = d
+		o.onSlowCall = hook
+		return nil
+	}
+}
+
+func (r *Client) recordLatency(event CallEvent) {
+	r.state.latency.observe(event.Operation, event.Duration)
+
+	if threshold := r.options.slowCallThreshold; threshold > 0 && event.Duration > threshold && r.options.onSlowCall != nil {
+		r.options.onSlowCall(event)
+	}
+}
@@ -0,0 +1,75 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestLatencyAndSlowCalls(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/predictions/slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	var slow []replicate.CallEvent
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithSlowCallThreshold(20*time.Millisecond, func(e replicate.CallEvent) {
+			slow = append(slow, e)
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err = client.GetPrediction(ctx, "fast")
+		require.NoError(t, err)
+	}
+	_, err = client.GetPrediction(ctx, "slow")
+	require.NoError(t, err)
+	_, err = client.ListHardware(ctx)
+	require.Error(t, err)
+
+	require.Len(t, slow, 1)
+	assert.Equal(t, "GetPrediction", slow[0].Operation)
+	assert.Equal(t, "/predictions/slow", slow[0].Request.URL.Path)
+	assert.GreaterOrEqual(t, slow[0].Duration, 50*time.Millisecond)
+
+	latency := client.Latency()
+	require.Contains(t, latency, "GetPrediction")
+	require.Contains(t, latency, "ListHardware")
+
+	h := latency["GetPrediction"]
+	assert.EqualValues(t, 4, h.Count)
+	assert.GreaterOrEqual(t, h.Max, 50*time.Millisecond)
+	assert.LessOrEqual(t, h.Quantile(0.5), 25*time.Millisecond)
+	assert.Equal(t, h.Max, h.Quantile(1))
+	assert.Positive(t, h.Mean())
+
+	var total uint64
+	for _, b := range h.Buckets {
+		total += b.Count
+	}
+	assert.Equal(t, h.Count, total)
+}
+
+func TestWithSlowCallThresholdValidation(t *testing.T) {
+	_, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithSlowCallThreshold(0, func(replicate.CallEvent) {}),
+	)
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package replicate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Limits describes the limits the API reports for the authenticated
+// account.
+//
+// The API only reports the request rate limit, in the X-RateLimit-* headers
+// of its responses. It doesn't report how many predictions the account can
+// run at once.
+type Limits struct {
+	// RateLimit is the request rate limit, or nil if the API didn't report
+	// one.
+	RateLimit *RateLimitState
+
+	// Header holds the headers of the response the limits were read from.
+	Header http.Header
+}
+
+// GetLimits makes a lightweight request to the API and returns the rate
+// limit reported in its response, for pacing the requests of a Scheduler or
+// Dispatcher instead of guessing.
+func (r *Client) GetLimits(ctx context.Context) (*Limits, error) {
+	md := &CallMetadata{}
+	err := r.fetch(WithCallMetadata(ctx, md), http.MethodGet, "/account", nil, &Account{})
+	if outer, ok := ctx.Value(callMetadataContextKey{}).(*CallMetadata); ok && outer != nil {
+		setCallMetadata(outer, *md)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get limits: %w", err)
+	}
+
+	return parseLimits(md.Header, time.Now()), nil
+}
+
+// parseLimits reads the limits reported in the headers of a response.
+func parseLimits(header http.Header, now time.Time) *Limits {
+	return &Limits{
+		RateLimit: parseRateLimit(header, now),
+		Header:    header,
+	}
+}
@@ -0,0 +1,351 @@
+// Package llm provides helpers for running language models on Replicate
+// with a chat-style API, and for computing text embeddings.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// Role is the author of a message.
+type Role string
+
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
+// Message is a message in a conversation.
+type Message struct {
+	Role    Role
+	Content string
+
+	// Images are images attached to the message, for vision models.
+	Images []Image
+}
+
+// PromptFormat renders a conversation as the input of a model.
+type PromptFormat func(messages []Message) (replicate.PredictionInput, error)
+
+// ChatOptions configure Chat. Zero values leave the model's defaults.
+type ChatOptions struct {
+	// Temperature controls the randomness of the output.
+	Temperature float64
+
+	// MaxTokens is the maximum number of tokens to generate.
+	MaxTokens int
+
+	// TopP is the cumulative probability of the tokens sampled from.
+	TopP float64
+
+	// StopSequences end generation when the model outputs one of them.
+	StopSequences []string
+
+	// Format renders the messages as model input. The default is
+	// DefaultFormat.
+	Format PromptFormat
+
+	// ContextWindow, if set, is the number of tokens the model accepts. The
+	// oldest turns of the conversation are dropped until the prompt and
+	// MaxTokens fit in it, with TruncateMessages.
+	ContextWindow int
+
+	// Tokenizer counts tokens for ContextWindow. The default is
+	// DefaultTokenizer.
+	Tokenizer Tokenizer
+
+	// ImageInput maps the images attached to the messages to model
+	// inputs. The default is SingleImageInput("image").
+	ImageInput ImageInput
+
+	// Input holds extra model inputs, which take precedence over those
+	// set from the other options.
+	Input replicate.PredictionInput
+
+	// OnToken, if set, is called with each token as the model streams its
+	// output.
+	OnToken func(token string)
+
+	// PollingInterval is how often the prediction is polled until it
+	// finishes. The default is the same as for Client.Wait.
+	PollingInterval time.Duration
+}
+
+// Usage describes the resources a chat completion used, from the metrics
+// of its prediction. Fields are zero when the model doesn't report them.
+type Usage struct {
+	InputTokens      int
+	OutputTokens     int
+	TimeToFirstToken time.Duration
+	TokensPerSecond  float64
+}
+
+// ChatResponse is the result of Chat.
+type ChatResponse struct {
+	// Message is the model's reply.
+	Message Message
+
+	// Usage is the usage reported by the model.
+	Usage Usage
+
+	// Prediction is the finished prediction.
+	Prediction *replicate.Prediction
+}
+
+// Chat runs a language model on a conversation and returns its reply.
+//
+// The messages are rendered into the model's prompt and system_prompt
+// inputs by opts.Format, and the conversation must end with a message from
+// the user. Images attached to the messages are set as model inputs by
+// opts.ImageInput; local images are sent inline, or uploaded with the files
+// API if they're large. If opts.OnToken is set, the output is streamed and
+// OnToken is called with each token as it arrives. If the prediction
+// doesn't succeed, the error is a *replicate.ModelError.
+func Chat(ctx context.Context, client *replicate.Client, model replicate.ModelRef, messages []Message, opts ChatOptions) (*ChatResponse, error) {
+	messages, err := opts.truncate(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	format := opts.Format
+	if format == nil {
+		format = DefaultFormat
+	}
+	input, err := format(messages)
+	if err != nil {
+		return nil, err
+	}
+	images, err := resolveImages(ctx, client, messages)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) > 0 {
+		imageInput := opts.ImageInput
+		if imageInput == nil {
+			imageInput = SingleImageInput("image")
+		}
+		values, err := imageInput(images)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(input, values)
+	}
+	opts.applyTo(input)
+
+	var streamed strings.Builder
+	var onCreate func(*replicate.Prediction) error
+	if opts.OnToken != nil {
+		onCreate = func(prediction *replicate.Prediction) error {
+			return streamTokens(ctx, client, prediction, &streamed, opts.OnToken)
+		}
+	}
+	prediction, err := client.RunModelRef(ctx, model, input, onCreate, waitOptions(opts.PollingInterval)...)
+	if err != nil {
+		return nil, err
+	}
+
+	text := outputText(prediction.Output)
+	if text == "" {
+		text = streamed.String()
+	}
+	return &ChatResponse{
+		Message:    Message{Role: RoleAssistant, Content: text},
+		Usage:      usageFrom(prediction.Metrics),
+		Prediction: prediction,
+	}, nil
+}
+
+// waitOptions returns the options to wait for a prediction with, polling
+// every interval if it's set.
+func waitOptions(interval time.Duration) []replicate.WaitOption {
+	if interval <= 0 {
+		return nil
+	}
+	return []replicate.WaitOption{replicate.WithPollingInterval(interval)}
+}
+
+// truncate drops the oldest turns of the conversation to fit in the
+// context window, if one is set.
+func (o *ChatOptions) truncate(messages []Message) ([]Message, error) {
+	if o.ContextWindow <= 0 {
+		return messages, nil
+	}
+	tokenizer := o.Tokenizer
+	if tokenizer == nil {
+		tokenizer = DefaultTokenizer
+	}
+	return TruncateMessages(tokenizer, messages, o.ContextWindow-o.MaxTokens)
+}
+
+func (o *ChatOptions) applyTo(input replicate.PredictionInput) {
+	if o.Temperature != 0 {
+		input["temperature"] = o.Temperature
+	}
+	if o.MaxTokens != 0 {
+		input["max_tokens"] = o.MaxTokens
+	}
+	if o.TopP != 0 {
+		input["top_p"] = o.TopP
+	}
+	if len(o.StopSequences) > 0 {
+		input["stop_sequences"] = strings.Join(o.StopSequences, ",")
+	}
+	maps.Copy(input, o.Input)
+}
+
+// streamTokens streams the output of the prediction, writing each token to
+// w and passing it to onToken. It returns when the stream ends.
+func streamTokens(ctx context.Context, client *replicate.Client, prediction *replicate.Prediction, w *strings.Builder, onToken func(string)) error {
+	sseChan, errChan := client.StreamPrediction(ctx, prediction)
+	for {
+		select {
+		case event, ok := <-sseChan:
+			if !ok {
+				return nil
+			}
+			switch event.Type {
+			case replicate.SSETypeOutput:
+				w.WriteString(event.Data)
+				onToken(event.Data)
+			case replicate.SSETypeDone, replicate.SSETypeError:
+				// The prediction's final status is read after the stream.
+				return nil
+			}
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to stream output: %w", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// outputText concatenates the output of a language model, which is usually
+// a list of tokens.
+func outputText(output replicate.PredictionOutput) string {
+	switch v := output.(type) {
+	case string:
+		return v
+	case []any:
+		var b strings.Builder
+		for _, token := range v {
+			if s, ok := token.(string); ok {
+				b.WriteString(s)
+			}
+		}
+		return b.String()
+	}
+	return ""
+}
+
+func usageFrom(metrics *replicate.PredictionMetrics) Usage {
+	var usage Usage
+	if metrics == nil {
+		return usage
+	}
+	if metrics.InputTokenCount != nil {
+		usage.InputTokens = *metrics.InputTokenCount
+	}
+	if metrics.OutputTokenCount != nil {
+		usage.OutputTokens = *metrics.OutputTokenCount
+	}
+	if metrics.TimeToFirstToken != nil {
+		usage.TimeToFirstToken = time.Duration(*metrics.TimeToFirstToken * float64(time.Second))
+	}
+	if metrics.TokensPerSecond != nil {
+		usage.TokensPerSecond = *metrics.TokensPerSecond
+	}
+	return usage
+}
+
+// DefaultFormat sets system_prompt from the system messages, and prompt
+// from the rest of the conversation. A single user message is used as the
+// prompt as is; longer conversations are rendered as a transcript that ends
+// with a cue for the assistant's reply.
+func DefaultFormat(messages []Message) (replicate.PredictionInput, error) {
+	system, turns, err := splitMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	input := replicate.PredictionInput{}
+	if system != "" {
+		input["system_prompt"] = system
+	}
+	if len(turns) == 1 {
+		input["prompt"] = turns[0].Content
+		return input, nil
+	}
+
+	var b strings.Builder
+	for _, m := range turns {
+		if m.Role == RoleUser {
+			b.WriteString("User: ")
+		} else {
+			b.WriteString("Assistant: ")
+		}
+		b.WriteString(m.Content)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Assistant:")
+	input["prompt"] = b.String()
+	return input, nil
+}
+
+// Llama3Format renders the whole conversation, including the system
+// prompt, in the Llama 3 chat template, and disables the model's own
+// template, so that earlier turns keep their roles.
+func Llama3Format(messages []Message) (replicate.PredictionInput, error) {
+	system, turns, err := splitMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("<|begin_of_text|>")
+	writeTurn := func(role Role, content string) {
+		fmt.Fprintf(&b, "<|start_header_id|>%s<|end_header_id|>\n\n%s<|eot_id|>", role, content)
+	}
+	if system != "" {
+		writeTurn(RoleSystem, system)
+	}
+	for _, m := range turns {
+		writeTurn(m.Role, m.Content)
+	}
+	fmt.Fprintf(&b, "<|start_header_id|>%s<|end_header_id|>\n\n", RoleAssistant)
+
+	return replicate.PredictionInput{"prompt": b.String(), "prompt_template": "{prompt}"}, nil
+}
+
+// splitMessages separates the system prompt from the turns of the
+// conversation, and checks that the last turn is the user's.
+func splitMessages(messages []Message) (string, []Message, error) {
+	var system []string
+	var turns []Message
+	for _, m := range messages {
+		switch m.Role {
+		case RoleSystem:
+			system = append(system, m.Content)
+		case RoleUser, RoleAssistant:
+			turns = append(turns, m)
+		default:
+			return "", nil, fmt.Errorf("unknown message role %q", m.Role)
+		}
+	}
+	if len(turns) == 0 || turns[len(turns)-1].Role != RoleUser {
+		return "", nil, errors.New("the last message must be from the user")
+	}
+	return strings.Join(system, "\n\n"), turns, nil
+}
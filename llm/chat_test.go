@@ -0,0 +1,117 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/llm"
+)
+
+func intPtr(v int) *int           { return &v }
+func floatPtr(v float64) *float64 { return &v }
+
+func TestChat(t *testing.T) {
+	var body struct {
+		Input  replicate.PredictionInput `json:"input"`
+		Stream bool                      `json:"stream"`
+	}
+	mockServer := httptest.NewUnstartedServer(nil)
+	mockServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prediction := &replicate.Prediction{
+			ID:     "ufawqhfynnddngldkgtslldrkq",
+			Status: replicate.Starting,
+			URLs:   map[string]string{"stream": mockServer.URL + "/stream"},
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/models/meta/meta-llama-3-8b-instruct/predictions":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		case r.URL.Path == "/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: output\ndata: Hello\n\nevent: output\ndata: , Alice\n\nevent: done\ndata: {}\n\n")
+			return
+		case r.URL.Path == "/predictions/ufawqhfynnddngldkgtslldrkq":
+			prediction.Status = replicate.Succeeded
+			prediction.Output = []any{"Hello", ", Alice"}
+			prediction.Metrics = &replicate.PredictionMetrics{
+				InputTokenCount:  intPtr(12),
+				OutputTokenCount: intPtr(2),
+				TimeToFirstToken: floatPtr(0.25),
+			}
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(prediction)
+	})
+	mockServer.Start()
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	var tokens []string
+	resp, err := llm.Chat(context.Background(), client, replicate.ModelRef{Identifier: "meta/meta-llama-3-8b-instruct"}, []llm.Message{
+		{Role: llm.RoleSystem, Content: "You are terse."},
+		{Role: llm.RoleUser, Content: "Say hi to Alice."},
+	}, llm.ChatOptions{
+		Temperature:     0.7,
+		MaxTokens:       64,
+		OnToken:         func(token string) { tokens = append(tokens, token) },
+		PollingInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, replicate.PredictionInput{
+		"system_prompt": "You are terse.",
+		"prompt":        "Say hi to Alice.",
+		"temperature":   0.7,
+		"max_tokens":    float64(64),
+	}, body.Input)
+	assert.True(t, body.Stream)
+	assert.Equal(t, []string{"Hello", ", Alice"}, tokens)
+	assert.Equal(t, llm.Message{Role: llm.RoleAssistant, Content: "Hello, Alice"}, resp.Message)
+	assert.Equal(t, llm.Usage{InputTokens: 12, OutputTokens: 2, TimeToFirstToken: 250 * time.Millisecond}, resp.Usage)
+
+	_, err = llm.Chat(context.Background(), client, replicate.ModelRef{Identifier: "meta/meta-llama-3-8b-instruct"}, []llm.Message{
+		{Role: llm.RoleUser, Content: "Hi"},
+		{Role: llm.RoleAssistant, Content: "Hello"},
+	}, llm.ChatOptions{})
+	assert.ErrorContains(t, err, "last message must be from the user")
+}
+
+func TestFormats(t *testing.T) {
+	messages := []llm.Message{
+		{Role: llm.RoleSystem, Content: "Be brief."},
+		{Role: llm.RoleUser, Content: "Hi"},
+		{Role: llm.RoleAssistant, Content: "Hello"},
+		{Role: llm.RoleUser, Content: "Bye"},
+	}
+
+	input, err := llm.DefaultFormat(messages)
+	require.NoError(t, err)
+	assert.Equal(t, replicate.PredictionInput{
+		"system_prompt": "Be brief.",
+		"prompt":        "User: Hi\n\nAssistant: Hello\n\nUser: Bye\n\nAssistant:",
+	}, input)
+
+	input, err = llm.Llama3Format(messages)
+	require.NoError(t, err)
+	assert.Equal(t, "{prompt}", input["prompt_template"])
+	assert.Equal(t, "<|begin_of_text|>"+
+		"<|start_header_id|>system<|end_header_id|>\n\nBe brief.<|eot_id|>"+
+		"<|start_header_id|>user<|end_header_id|>\n\nHi<|eot_id|>"+
+		"<|start_header_id|>assistant<|end_header_id|>\n\nHello<|eot_id|>"+
+		"<|start_header_id|>user<|end_header_id|>\n\nBye<|eot_id|>"+
+		"<|start_header_id|>assistant<|end_header_id|>\n\n", input["prompt"])
+}
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/replicate/replicate-go"
+)
+
+// Conversation is a chat with a language model that remembers its
+// messages. It's safe for concurrent use; Send calls run one at a time.
+type Conversation struct {
+	client *replicate.Client
+	model  replicate.ModelRef
+	opts   ChatOptions
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewConversation starts a conversation with model. system, if set, is the
+// system prompt. opts configure each completion; if opts.ContextWindow is
+// set, the oldest turns are forgotten as the conversation outgrows it.
+func NewConversation(client *replicate.Client, model replicate.ModelRef, system string, opts ChatOptions) *Conversation {
+	c := &Conversation{client: client, model: model, opts: opts}
+	if system != "" {
+		c.messages = []Message{{Role: RoleSystem, Content: system}}
+	}
+	return c
+}
+
+// Send sends text to the model as the user, and returns the model's reply.
+func (c *Conversation) Send(ctx context.Context, text string) (*ChatResponse, error) {
+	return c.SendMessage(ctx, Message{Role: RoleUser, Content: text})
+}
+
+// SendMessage sends a message from the user, which may have images, and
+// returns the model's reply. The message and the reply are added to the
+// conversation if the completion succeeds.
+func (c *Conversation) SendMessage(ctx context.Context, message Message) (*ChatResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	messages, err := c.opts.truncate(append(slices.Clone(c.messages), message))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := Chat(ctx, c.client, c.model, messages, c.opts)
+	if err != nil {
+		return nil, err
+	}
+	c.messages = append(messages, resp.Message)
+	return resp, nil
+}
+
+// Messages returns the messages of the conversation that are remembered.
+func (c *Conversation) Messages() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.messages)
+}
+
+// Reset forgets every message but the system prompt.
+func (c *Conversation) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = slices.DeleteFunc(c.messages, func(m Message) bool {
+		return m.Role != RoleSystem
+	})
+}
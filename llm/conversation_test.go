@@ -0,0 +1,68 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/llm"
+)
+
+func TestConversation(t *testing.T) {
+	var prompts []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input replicate.PredictionInput `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		prompts = append(prompts, body.Input["prompt"].(string))
+		json.NewEncoder(w).Encode(&replicate.Prediction{
+			ID:     fmt.Sprintf("prediction-%d", len(prompts)),
+			Status: replicate.Succeeded,
+			Output: []any{fmt.Sprintf("reply %d", len(prompts))},
+		})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	conversation := llm.NewConversation(client, replicate.ModelRef{Identifier: "meta/meta-llama-3-8b-instruct"}, "Be brief.", llm.ChatOptions{
+		ContextWindow: 26,
+		Tokenizer:     wordTokenizer{},
+	})
+
+	resp, err := conversation.Send(context.Background(), "first question")
+	require.NoError(t, err)
+	assert.Equal(t, "reply 1", resp.Message.Content)
+
+	_, err = conversation.Send(context.Background(), "second question")
+	require.NoError(t, err)
+	assert.Equal(t, "User: first question\n\nAssistant: reply 1\n\nUser: second question\n\nAssistant:", prompts[1])
+
+	// The first exchange no longer fits in the context window.
+	_, err = conversation.Send(context.Background(), "third question")
+	require.NoError(t, err)
+	assert.Equal(t, "User: second question\n\nAssistant: reply 2\n\nUser: third question\n\nAssistant:", prompts[2])
+
+	assert.Equal(t, []llm.Message{
+		{Role: llm.RoleSystem, Content: "Be brief."},
+		{Role: llm.RoleUser, Content: "second question"},
+		{Role: llm.RoleAssistant, Content: "reply 2"},
+		{Role: llm.RoleUser, Content: "third question"},
+		{Role: llm.RoleAssistant, Content: "reply 3"},
+	}, conversation.Messages())
+
+	conversation.Reset()
+	assert.Equal(t, []llm.Message{{Role: llm.RoleSystem, Content: "Be brief."}}, conversation.Messages())
+}
@@ -0,0 +1,188 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+const defaultEmbedBatchSize = 32
+
+// EmbedInput renders a batch of texts as the input of an embedding model.
+type EmbedInput func(texts []string) (replicate.PredictionInput, error)
+
+// JSONListInput sets the input key to the texts encoded as a JSON array, as
+// models like nateraw/bge-large-en-v1.5 ("texts") and
+// replicate/all-mpnet-base-v2 ("text_batch") expect.
+func JSONListInput(key string) EmbedInput {
+	return func(texts []string) (replicate.PredictionInput, error) {
+		b, err := json.Marshal(texts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode texts: %w", err)
+		}
+		return replicate.PredictionInput{key: string(b)}, nil
+	}
+}
+
+// NewlineListInput sets the input key to the texts separated by newlines, as
+// models like andreasjansson/clip-features ("inputs") expect. Texts must not
+// contain newlines.
+func NewlineListInput(key string) EmbedInput {
+	return func(texts []string) (replicate.PredictionInput, error) {
+		for i, text := range texts {
+			if strings.ContainsAny(text, "\r\n") {
+				return nil, fmt.Errorf("text %d contains a newline", i)
+			}
+		}
+		return replicate.PredictionInput{key: strings.Join(texts, "\n")}, nil
+	}
+}
+
+// EmbedOptions configure EmbedWithOptions.
+type EmbedOptions struct {
+	// BatchSize is the maximum number of texts embedded by one prediction.
+	// The default is 32.
+	BatchSize int
+
+	// Input renders a batch of texts as model input. The default is
+	// JSONListInput("texts").
+	Input EmbedInput
+
+	// PollingInterval is how often each prediction is polled until it
+	// finishes. The default is the same as for Client.Wait.
+	PollingInterval time.Duration
+}
+
+// EmbedResponse is the result of EmbedWithOptions.
+type EmbedResponse struct {
+	// Embeddings holds the vector of each text, in order.
+	Embeddings [][]float32
+
+	// InputTokens is the number of tokens embedded, summed over the
+	// predictions. It's zero when the model doesn't report it.
+	InputTokens int
+
+	// Predictions are the finished predictions, one per batch.
+	Predictions []*replicate.Prediction
+}
+
+// Embed returns the embedding vector of each text, computed by an embedding
+// model with the default options. See EmbedWithOptions.
+func Embed(ctx context.Context, client *replicate.Client, model replicate.ModelRef, texts []string) ([][]float32, error) {
+	resp, err := EmbedWithOptions(ctx, client, model, texts, EmbedOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Embeddings, nil
+}
+
+// EmbedWithOptions returns the embedding vector of each text, computed by
+// an embedding model.
+//
+// The texts are split into batches of opts.BatchSize, each embedded by one
+// prediction, in turn. The output of each prediction is normalized from the
+// shapes popular models return: a list of vectors, a single vector, a list
+// of objects with an "embedding" field, or an object with an "embeddings"
+// field. If a prediction doesn't succeed, the error is a
+// *replicate.ModelError.
+func EmbedWithOptions(ctx context.Context, client *replicate.Client, model replicate.ModelRef, texts []string, opts EmbedOptions) (*EmbedResponse, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultEmbedBatchSize
+	}
+	format := opts.Input
+	if format == nil {
+		format = JSONListInput("texts")
+	}
+
+	resp := &EmbedResponse{Embeddings: make([][]float32, 0, len(texts))}
+	for start := 0; start < len(texts); start += batchSize {
+		batch := texts[start:min(start+batchSize, len(texts))]
+
+		input, err := format(batch)
+		if err != nil {
+			return nil, err
+		}
+		prediction, err := client.RunModelRef(ctx, model, input, nil, waitOptions(opts.PollingInterval)...)
+		if err != nil {
+			return nil, err
+		}
+
+		embeddings, err := parseEmbeddings(prediction.Output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse output of prediction %s: %w", prediction.ID, err)
+		}
+		if len(embeddings) != len(batch) {
+			return nil, fmt.Errorf("prediction %s returned %d embeddings for %d texts", prediction.ID, len(embeddings), len(batch))
+		}
+
+		resp.Embeddings = append(resp.Embeddings, embeddings...)
+		resp.InputTokens += usageFrom(prediction.Metrics).InputTokens
+		resp.Predictions = append(resp.Predictions, prediction)
+	}
+	return resp, nil
+}
+
+// parseEmbeddings normalizes the output of an embedding model to a list of
+// vectors.
+func parseEmbeddings(output replicate.PredictionOutput) ([][]float32, error) {
+	switch v := output.(type) {
+	case []any:
+		if len(v) == 0 {
+			return [][]float32{}, nil
+		}
+		if _, ok := v[0].(float64); ok {
+			vector, err := parseVector(v)
+			if err != nil {
+				return nil, err
+			}
+			return [][]float32{vector}, nil
+		}
+		embeddings := make([][]float32, len(v))
+		for i, item := range v {
+			if obj, ok := item.(map[string]any); ok {
+				item = obj["embedding"]
+			}
+			vector, err := parseVector(item)
+			if err != nil {
+				return nil, fmt.Errorf("embedding %d: %w", i, err)
+			}
+			embeddings[i] = vector
+		}
+		return embeddings, nil
+	case map[string]any:
+		if embeddings, ok := v["embeddings"]; ok {
+			return parseEmbeddings(embeddings)
+		}
+		if embedding, ok := v["embedding"]; ok {
+			vector, err := parseVector(embedding)
+			if err != nil {
+				return nil, err
+			}
+			return [][]float32{vector}, nil
+		}
+		return nil, errors.New("output has no embeddings field")
+	}
+	return nil, fmt.Errorf("unexpected output type %T", output)
+}
+
+func parseVector(value any) ([]float32, error) {
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a list of numbers, got %T", value)
+	}
+	vector := make([]float32, len(list))
+	for i, x := range list {
+		f, ok := x.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %T", x)
+		}
+		vector[i] = float32(f)
+	}
+	return vector, nil
+}
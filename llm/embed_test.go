@@ -0,0 +1,93 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/llm"
+)
+
+func TestEmbedWithOptions(t *testing.T) {
+	var batches [][]string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/models/nateraw/bge-large-en-v1.5/predictions", r.URL.Path)
+
+		var body struct {
+			Input struct {
+				Texts string `json:"texts"`
+			} `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		var texts []string
+		require.NoError(t, json.Unmarshal([]byte(body.Input.Texts), &texts))
+		batches = append(batches, texts)
+
+		// Alternate the output shapes embedding models return.
+		var output any
+		if len(batches) == 1 {
+			vectors := make([][]float64, len(texts))
+			for i := range texts {
+				vectors[i] = []float64{float64(len(batches)), float64(i)}
+			}
+			output = vectors
+		} else {
+			objects := make([]map[string]any, len(texts))
+			for i := range texts {
+				objects[i] = map[string]any{"embedding": []float64{float64(len(batches)), float64(i)}}
+			}
+			output = objects
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":      fmt.Sprintf("prediction-%d", len(batches)),
+			"status":  replicate.Succeeded,
+			"output":  output,
+			"metrics": map[string]any{"input_token_count": 2 * len(texts)},
+		})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	resp, err := llm.EmbedWithOptions(context.Background(), client, replicate.ModelRef{Identifier: "nateraw/bge-large-en-v1.5"},
+		[]string{"a", "b", "c"}, llm.EmbedOptions{BatchSize: 2, PollingInterval: time.Millisecond})
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, batches)
+	assert.Equal(t, [][]float32{{1, 0}, {1, 1}, {2, 0}}, resp.Embeddings)
+	assert.Equal(t, 6, resp.InputTokens)
+	assert.Len(t, resp.Predictions, 2)
+}
+
+func TestEmbedRejectsMismatchedOutput(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":     "prediction",
+			"status": replicate.Succeeded,
+			"output": []float64{0.1, 0.2},
+		})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	_, err = llm.Embed(context.Background(), client, replicate.ModelRef{Identifier: "nateraw/bge-large-en-v1.5"}, []string{"a", "b"})
+	assert.ErrorContains(t, err, "returned 1 embeddings for 2 texts")
+}
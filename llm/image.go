@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/replicate/replicate-go"
+)
+
+// maxInlineImageSize is the size above which images are uploaded with the
+// files API rather than sent inline as data URIs.
+const maxInlineImageSize = 256 << 10
+
+// Image is an image attached to a message, for vision models. Exactly one
+// of URL, Path, and Data must be set.
+type Image struct {
+	// URL is the URL of the image, which is passed to the model as is.
+	URL string
+
+	// Path is the path of a local image file.
+	Path string
+
+	// Data is the content of the image.
+	Data []byte
+}
+
+// ImageInput maps the URLs of the images of a conversation, in order, to
+// model inputs.
+type ImageInput func(images []string) (replicate.PredictionInput, error)
+
+// SingleImageInput sets key to the most recent image of the conversation,
+// for models like LLaVA that take one image per prediction.
+func SingleImageInput(key string) ImageInput {
+	return func(images []string) (replicate.PredictionInput, error) {
+		return replicate.PredictionInput{key: images[len(images)-1]}, nil
+	}
+}
+
+// ImageListInput sets key to the list of the images of the conversation,
+// for models that take several images.
+func ImageListInput(key string) ImageInput {
+	return func(images []string) (replicate.PredictionInput, error) {
+		return replicate.PredictionInput{key: images}, nil
+	}
+}
+
+// resolveImages returns the URLs of the images attached to the messages.
+// Local images are inlined as data URIs, or uploaded if they're large.
+func resolveImages(ctx context.Context, client *replicate.Client, messages []Message) ([]string, error) {
+	var urls []string
+	for i, m := range messages {
+		for j, img := range m.Images {
+			url, err := img.url(ctx, client)
+			if err != nil {
+				return nil, fmt.Errorf("message %d, image %d: %w", i, j, err)
+			}
+			urls = append(urls, url)
+		}
+	}
+	return urls, nil
+}
+
+func (img Image) url(ctx context.Context, client *replicate.Client) (string, error) {
+	set := 0
+	for _, ok := range []bool{img.URL != "", img.Path != "", img.Data != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return "", errors.New("exactly one of URL, Path, and Data must be set")
+	}
+	if img.URL != "" {
+		return img.URL, nil
+	}
+
+	data, contentType := img.Data, ""
+	if img.Path != "" {
+		var err error
+		data, err = os.ReadFile(img.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read image: %w", err)
+		}
+		contentType = mime.TypeByExtension(filepath.Ext(img.Path))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	if len(data) <= maxInlineImageSize {
+		return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+	}
+	options := &replicate.CreateFileOptions{ContentType: contentType}
+	if img.Path != "" {
+		options.Filename = filepath.Base(img.Path)
+	}
+	file, err := client.CreateFileFromBytes(ctx, data, options)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload image: %w", err)
+	}
+	if file.URLs["get"] == "" {
+		return "", fmt.Errorf("uploaded image %s has no URL", file.ID)
+	}
+	return file.URLs["get"], nil
+}
@@ -0,0 +1,86 @@
+package llm_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/llm"
+)
+
+func TestChatWithImages(t *testing.T) {
+	var input replicate.PredictionInput
+	var uploaded []byte
+	mockServer := httptest.NewUnstartedServer(nil)
+	mockServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			file, _, err := r.FormFile("content")
+			require.NoError(t, err)
+			defer file.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(file)
+			uploaded = buf.Bytes()
+			json.NewEncoder(w).Encode(&replicate.File{
+				ID:   "file-1",
+				URLs: map[string]string{"get": mockServer.URL + "/files/file-1"},
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/models/yorickvp/llava-13b/predictions":
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			input = body.Input
+			json.NewEncoder(w).Encode(&replicate.Prediction{
+				ID:     "prediction-1",
+				Status: replicate.Succeeded,
+				Output: []any{"Two ", "cats."},
+			})
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	mockServer.Start()
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "cat.png")
+	require.NoError(t, os.WriteFile(path, []byte("small"), 0o644))
+	large := bytes.Repeat([]byte{0xff}, 512<<10)
+
+	resp, err := llm.Chat(context.Background(), client, replicate.ModelRef{Identifier: "yorickvp/llava-13b"}, []llm.Message{
+		{Role: llm.RoleUser, Content: "How many cats?", Images: []llm.Image{
+			{URL: "https://example.com/cat.jpg"},
+			{Path: path},
+			{Data: large},
+		}},
+	}, llm.ChatOptions{ImageInput: llm.ImageListInput("images")})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Two cats.", resp.Message.Content)
+	assert.Equal(t, []any{
+		"https://example.com/cat.jpg",
+		"data:image/png;base64,c21hbGw=",
+		mockServer.URL + "/files/file-1",
+	}, input["images"])
+	assert.Equal(t, large, uploaded)
+
+	_, err = llm.Chat(context.Background(), client, replicate.ModelRef{Identifier: "yorickvp/llava-13b"}, []llm.Message{
+		{Role: llm.RoleUser, Content: "Hi", Images: []llm.Image{{URL: "https://example.com/cat.jpg", Path: path}}},
+	}, llm.ChatOptions{})
+	assert.ErrorContains(t, err, "exactly one of URL, Path, and Data must be set")
+}
@@ -0,0 +1,200 @@
+package llm
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// messageOverhead is the number of tokens counted for each message on top
+// of its content, for the role and the separators of the chat template.
+const messageOverhead = 4
+
+// ErrPromptTooLong is returned when the system prompt and the last message
+// of a conversation don't fit in the token budget on their own.
+var ErrPromptTooLong = errors.New("prompt is too long for the context window")
+
+// Tokenizer counts the tokens of text, to size prompts before they're
+// submitted.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// pretokenize splits text into the pieces BPE merges are applied within,
+// like the cl100k pattern, less the lookahead RE2 doesn't support.
+var pretokenize = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// DefaultTokenizer estimates token counts without a vocabulary. It splits
+// text like a byte-level BPE tokenizer does and counts a token for each
+// piece, a word or a run of digits or punctuation, plus one for every six
+// further ASCII characters and one per other character. That is close to
+// the counts of vocabularies like Llama 3's and cl100k for English text. Use
+// a BPE loaded with the model's vocabulary when exact counts matter.
+var DefaultTokenizer Tokenizer = estimator{}
+
+type estimator struct{}
+
+func (estimator) CountTokens(text string) int {
+	n := 0
+	for _, piece := range pretokenize.FindAllString(text, -1) {
+		ascii := 0
+		other := 0
+		for _, r := range piece {
+			if r < utf8.RuneSelf {
+				ascii++
+			} else {
+				other++
+			}
+		}
+		n += max(1, (ascii+5)/6+other)
+	}
+	return n
+}
+
+// BPE is a byte-level byte pair encoding tokenizer, such as those of GPT
+// and Llama 3 models.
+type BPE struct {
+	ranks map[string]int
+}
+
+// NewBPE returns a tokenizer with the given merge ranks, keyed by the bytes
+// of each token. The ranks must include every single byte.
+func NewBPE(ranks map[string]int) (*BPE, error) {
+	for b := 0; b < 256; b++ {
+		if _, ok := ranks[string([]byte{byte(b)})]; !ok {
+			return nil, fmt.Errorf("ranks are missing byte %#x", b)
+		}
+	}
+	return &BPE{ranks: ranks}, nil
+}
+
+// LoadBPE reads a vocabulary in the tiktoken format, with a base64-encoded
+// token and its rank on each line, and returns a tokenizer for it.
+func LoadBPE(r io.Reader) (*BPE, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		encoded, rank, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid vocabulary line %d", line)
+		}
+		token, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token on vocabulary line %d: %w", line, err)
+		}
+		ranks[string(token)], err = strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank on vocabulary line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read vocabulary: %w", err)
+	}
+	return NewBPE(ranks)
+}
+
+// Encode returns the tokens of text.
+func (t *BPE) Encode(text string) []int {
+	var tokens []int
+	for _, piece := range pretokenize.FindAllString(text, -1) {
+		if rank, ok := t.ranks[piece]; ok {
+			tokens = append(tokens, rank)
+			continue
+		}
+		for _, part := range t.merge(piece) {
+			tokens = append(tokens, t.ranks[part])
+		}
+	}
+	return tokens
+}
+
+// CountTokens returns the number of tokens of text.
+func (t *BPE) CountTokens(text string) int {
+	return len(t.Encode(text))
+}
+
+// merge splits piece into bytes and repeatedly merges the adjacent pair
+// with the lowest rank until no pair is a token.
+func (t *BPE) merge(piece string) []string {
+	parts := make([]string, len(piece))
+	for i := range parts {
+		parts[i] = piece[i : i+1]
+	}
+	for len(parts) > 1 {
+		best, bestRank := -1, 0
+		for i := 0; i < len(parts)-1; i++ {
+			if rank, ok := t.ranks[parts[i]+parts[i+1]]; ok && (best < 0 || rank < bestRank) {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+	return parts
+}
+
+// CountMessageTokens returns the number of tokens of a conversation,
+// including a small overhead per message for the chat template.
+func CountMessageTokens(tokenizer Tokenizer, messages []Message) int {
+	n := 0
+	for _, m := range messages {
+		n += messageOverhead + tokenizer.CountTokens(m.Content)
+	}
+	return n
+}
+
+// TruncateMessages drops the oldest turns of a conversation until it fits
+// in maxTokens. System messages and the last message are always kept, and
+// the kept turns start with a message from the user. If the conversation
+// can't fit, the error is ErrPromptTooLong.
+func TruncateMessages(tokenizer Tokenizer, messages []Message, maxTokens int) ([]Message, error) {
+	if len(messages) == 0 {
+		return messages, nil
+	}
+
+	total := CountMessageTokens(tokenizer, messages)
+	if total <= maxTokens {
+		return messages, nil
+	}
+
+	last := len(messages) - 1
+	drop := make([]bool, len(messages))
+	dropping := true
+	for i, m := range messages[:last] {
+		if m.Role == RoleSystem {
+			continue
+		}
+		// Keep dropping until the conversation fits and resumes with the
+		// user.
+		if dropping && (total > maxTokens || m.Role != RoleUser) {
+			drop[i] = true
+			total -= messageOverhead + tokenizer.CountTokens(m.Content)
+			continue
+		}
+		dropping = false
+	}
+	if total > maxTokens {
+		return nil, fmt.Errorf("%w: %d tokens is more than %d", ErrPromptTooLong, total, maxTokens)
+	}
+
+	kept := make([]Message, 0, len(messages))
+	for i, m := range messages {
+		if !drop[i] {
+			kept = append(kept, m)
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,72 @@
+package llm_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go/llm"
+)
+
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestBPE(t *testing.T) {
+	var vocab strings.Builder
+	for b := 0; b < 256; b++ {
+		fmt.Fprintf(&vocab, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(b)}), b)
+	}
+	fmt.Fprintf(&vocab, "%s 256\n", base64.StdEncoding.EncodeToString([]byte("ab")))
+	fmt.Fprintf(&vocab, "%s 257\n", base64.StdEncoding.EncodeToString([]byte("abc")))
+
+	bpe, err := llm.LoadBPE(strings.NewReader(vocab.String()))
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{257, 256}, bpe.Encode("abcab"))
+	assert.Equal(t, []int{'x', 257, ' ', 256}, bpe.Encode("xabc ab"))
+	assert.Equal(t, 4, bpe.CountTokens("xabc ab"))
+
+	_, err = llm.NewBPE(map[string]int{"a": 0})
+	assert.ErrorContains(t, err, "missing byte")
+}
+
+func TestDefaultTokenizer(t *testing.T) {
+	assert.Equal(t, 0, llm.DefaultTokenizer.CountTokens(""))
+	assert.Equal(t, 4, llm.DefaultTokenizer.CountTokens("Hello, world!"))
+}
+
+func TestTruncateMessages(t *testing.T) {
+	messages := []llm.Message{
+		{Role: llm.RoleSystem, Content: "Be brief."},
+		{Role: llm.RoleUser, Content: "one two three"},
+		{Role: llm.RoleAssistant, Content: "four five"},
+		{Role: llm.RoleUser, Content: "six"},
+		{Role: llm.RoleAssistant, Content: "seven"},
+		{Role: llm.RoleUser, Content: "eight nine"},
+	}
+	assert.Equal(t, 35, llm.CountMessageTokens(wordTokenizer{}, messages))
+
+	kept, err := llm.TruncateMessages(wordTokenizer{}, messages, 35)
+	require.NoError(t, err)
+	assert.Equal(t, messages, kept)
+
+	// Dropping the first user message is enough to fit, but the assistant's
+	// reply to it goes too, so the conversation resumes with the user.
+	kept, err = llm.TruncateMessages(wordTokenizer{}, messages, 34)
+	require.NoError(t, err)
+	assert.Equal(t, []llm.Message{messages[0], messages[3], messages[4], messages[5]}, kept)
+
+	kept, err = llm.TruncateMessages(wordTokenizer{}, messages, 12)
+	require.NoError(t, err)
+	assert.Equal(t, []llm.Message{messages[0], messages[5]}, kept)
+
+	_, err = llm.TruncateMessages(wordTokenizer{}, messages, 11)
+	assert.ErrorIs(t, err, llm.ErrPromptTooLong)
+}
@@ -0,0 +1,261 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/replicate/replicate-go"
+)
+
+const (
+	defaultMaxToolSteps = 8
+
+	toolCallStart = "<tool_call>"
+	toolCallEnd   = "</tool_call>"
+)
+
+// ErrTooManyToolSteps is returned by ChatWithTools when the model keeps
+// calling tools after ToolOptions.MaxSteps replies.
+var ErrTooManyToolSteps = errors.New("model didn't answer within the maximum number of tool steps")
+
+// Tool is a Go function a model can call.
+type Tool struct {
+	// Name identifies the tool to the model.
+	Name string
+
+	// Description tells the model what the tool does and when to use it.
+	Description string
+
+	// Parameters is the JSON schema of the tool's arguments.
+	Parameters map[string]any
+
+	// Func runs the tool with the arguments the model gave, and returns
+	// the result shown to the model. An error is shown to the model too,
+	// so it can correct its call.
+	Func func(ctx context.Context, arguments json.RawMessage) (string, error)
+}
+
+// ToolCall is a call of a tool by the model.
+type ToolCall struct {
+	Name      string
+	Arguments json.RawMessage
+
+	// Result is what the tool returned, and Err the error it failed with.
+	Result string
+	Err    error
+}
+
+// ToolOptions configure ChatWithTools.
+type ToolOptions struct {
+	// Chat configures each completion. OnToken is only called with the
+	// text of the model's replies outside tool calls.
+	Chat ChatOptions
+
+	// MaxSteps is the maximum number of completions. The default is 8.
+	MaxSteps int
+}
+
+// ToolResponse is the result of ChatWithTools.
+type ToolResponse struct {
+	// Message is the model's final answer.
+	Message Message
+
+	// Calls are the tool calls the model made, in order.
+	Calls []ToolCall
+
+	// Messages is the conversation, with the tool calls, their results, and
+	// the final answer appended, for continuing it.
+	Messages []Message
+
+	// Usage is the token counts summed over the completions.
+	Usage Usage
+
+	// Predictions are the finished predictions, one per completion.
+	Predictions []*replicate.Prediction
+}
+
+// ChatWithTools runs a language model on a conversation, letting it call
+// tools until it gives a final answer.
+//
+// The tools are described to the model in a system message, which asks it
+// to reply with a call in the form
+//
+//	<tool_call>{"name": "...", "arguments": {...}}</tool_call>
+//
+// to use one. Each call is run, its result is sent back to the model in a
+// user message, and the model is run again, until it replies without a
+// tool call. Models that follow instructions well, such as Llama 3 70B,
+// work best. If the model is still calling tools after opts.MaxSteps
+// replies, the error wraps ErrTooManyToolSteps.
+func ChatWithTools(ctx context.Context, client *replicate.Client, model replicate.ModelRef, messages []Message, tools []Tool, opts ToolOptions) (*ToolResponse, error) {
+	maxSteps := opts.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxToolSteps
+	}
+	byName := make(map[string]Tool, len(tools))
+	for _, tool := range tools {
+		if tool.Name == "" || tool.Func == nil {
+			return nil, errors.New("tools must have a name and a function")
+		}
+		byName[tool.Name] = tool
+	}
+
+	system, err := toolPrompt(tools)
+	if err != nil {
+		return nil, err
+	}
+	conversation := append([]Message{{Role: RoleSystem, Content: system}}, messages...)
+
+	resp := &ToolResponse{}
+	for step := 0; step < maxSteps; step++ {
+		chatOpts := opts.Chat
+		var filter *toolCallFilter
+		if opts.Chat.OnToken != nil {
+			filter = &toolCallFilter{onToken: opts.Chat.OnToken}
+			chatOpts.OnToken = filter.write
+		}
+
+		reply, err := Chat(ctx, client, model, conversation, chatOpts)
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil {
+			filter.flush()
+		}
+		resp.Predictions = append(resp.Predictions, reply.Prediction)
+		resp.Usage.InputTokens += reply.Usage.InputTokens
+		resp.Usage.OutputTokens += reply.Usage.OutputTokens
+
+		conversation = append(conversation, reply.Message)
+		calls, ok := parseToolCalls(reply.Message.Content)
+		if !ok {
+			resp.Message = reply.Message
+			resp.Messages = conversation[1:]
+			return resp, nil
+		}
+
+		results := make([]string, len(calls))
+		for i := range calls {
+			call := &calls[i]
+			tool, ok := byName[call.Name]
+			switch {
+			case call.Err != nil:
+			case !ok:
+				call.Err = fmt.Errorf("unknown tool %q", call.Name)
+			default:
+				call.Result, call.Err = tool.Func(ctx, call.Arguments)
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+			}
+			results[i] = call.resultMessage()
+		}
+		resp.Calls = append(resp.Calls, calls...)
+		conversation = append(conversation, Message{Role: RoleUser, Content: strings.Join(results, "\n")})
+	}
+	return nil, fmt.Errorf("%w (%d)", ErrTooManyToolSteps, maxSteps)
+}
+
+func toolPrompt(tools []Tool) (string, error) {
+	type toolSpec struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description,omitempty"`
+		Parameters  map[string]any `json:"parameters,omitempty"`
+	}
+	specs := make([]toolSpec, len(tools))
+	for i, tool := range tools {
+		specs[i] = toolSpec{Name: tool.Name, Description: tool.Description, Parameters: tool.Parameters}
+	}
+	encoded, err := json.MarshalIndent(specs, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode tools: %w", err)
+	}
+
+	return "You can call the following tools, described as JSON:\n\n" + string(encoded) + "\n\n" +
+		"To call a tool, reply with only " + toolCallStart + `{"name": "tool name", "arguments": {...}}` + toolCallEnd +
+		", with arguments that match the tool's parameters. You can make several calls in one reply. " +
+		"The results will be sent to you in the next message, as <tool_result> elements. " +
+		"When you have what you need, reply to the user directly, without a tool call.", nil
+}
+
+// parseToolCalls returns the tool calls in a reply, and whether it had any.
+// Calls that can't be decoded have Err set, so the model can be told.
+func parseToolCalls(reply string) ([]ToolCall, bool) {
+	var calls []ToolCall
+	for {
+		_, rest, ok := strings.Cut(reply, toolCallStart)
+		if !ok {
+			break
+		}
+		// The closing tag may be missing if the model stopped after the
+		// call.
+		body, after, _ := strings.Cut(rest, toolCallEnd)
+		reply = after
+
+		var call struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(body)), &call); err != nil {
+			calls = append(calls, ToolCall{Err: fmt.Errorf("invalid tool call: %w", err)})
+			continue
+		}
+		calls = append(calls, ToolCall{Name: call.Name, Arguments: call.Arguments})
+	}
+	return calls, len(calls) > 0
+}
+
+func (c *ToolCall) resultMessage() string {
+	content := c.Result
+	if c.Err != nil {
+		content = "error: " + c.Err.Error()
+	}
+	return fmt.Sprintf("<tool_result name=%q>%s</tool_result>", c.Name, content)
+}
+
+// toolCallFilter passes streamed tokens on, up to the start of a tool call.
+type toolCallFilter struct {
+	onToken func(string)
+	pending string
+	inCall  bool
+}
+
+func (f *toolCallFilter) write(token string) {
+	if f.inCall {
+		return
+	}
+	f.pending += token
+	if i := strings.Index(f.pending, toolCallStart); i >= 0 {
+		f.emit(f.pending[:i])
+		f.pending = ""
+		f.inCall = true
+		return
+	}
+	// Hold back the end of the text while it could be the start of a tool
+	// call.
+	hold := 0
+	for n := min(len(f.pending), len(toolCallStart)-1); n > 0; n-- {
+		if strings.HasSuffix(f.pending, toolCallStart[:n]) {
+			hold = n
+			break
+		}
+	}
+	f.emit(f.pending[:len(f.pending)-hold])
+	f.pending = f.pending[len(f.pending)-hold:]
+}
+
+func (f *toolCallFilter) flush() {
+	if !f.inCall {
+		f.emit(f.pending)
+	}
+	f.pending = ""
+}
+
+func (f *toolCallFilter) emit(text string) {
+	if text != "" {
+		f.onToken(text)
+	}
+}
@@ -0,0 +1,116 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/llm"
+)
+
+func TestChatWithTools(t *testing.T) {
+	replies := [][]string{
+		{"Let me check. <tool", `_call>{"name": "get_weather", "arguments": {"city": "Paris"}}</tool_call>`},
+		{`<tool_call>{"name": "get_weather", "arguments": {"city": "Nowhere"}}</tool_call>`},
+		{"It's sunny ", "in Paris."},
+	}
+	var prompts []string
+	mockServer := httptest.NewUnstartedServer(nil)
+	mockServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		step := len(prompts) - 1
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/models/meta/meta-llama-3-70b-instruct/predictions":
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			prompts = append(prompts, body.Input["prompt"].(string))
+			step = len(prompts) - 1
+			json.NewEncoder(w).Encode(&replicate.Prediction{
+				ID:     fmt.Sprintf("prediction-%d", step),
+				Status: replicate.Starting,
+				URLs:   map[string]string{"stream": mockServer.URL + "/stream"},
+			})
+		case r.URL.Path == "/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, token := range replies[step] {
+				fmt.Fprintf(w, "event: output\ndata: %s\n\n", token)
+			}
+			fmt.Fprint(w, "event: done\ndata: {}\n\n")
+		case r.URL.Path == fmt.Sprintf("/predictions/prediction-%d", step):
+			output := make([]any, len(replies[step]))
+			for i, token := range replies[step] {
+				output[i] = token
+			}
+			json.NewEncoder(w).Encode(&replicate.Prediction{
+				ID:      fmt.Sprintf("prediction-%d", step),
+				Status:  replicate.Succeeded,
+				Output:  output,
+				Metrics: &replicate.PredictionMetrics{InputTokenCount: intPtr(10), OutputTokenCount: intPtr(5)},
+			})
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	mockServer.Start()
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	weather := llm.Tool{
+		Name:        "get_weather",
+		Description: "Returns the weather in a city.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"city": map[string]any{"type": "string"}},
+		},
+		Func: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			var args struct{ City string }
+			require.NoError(t, json.Unmarshal(arguments, &args))
+			if args.City != "Paris" {
+				return "", errors.New("unknown city")
+			}
+			return "sunny", nil
+		},
+	}
+
+	var streamed strings.Builder
+	resp, err := llm.ChatWithTools(context.Background(), client, replicate.ModelRef{Identifier: "meta/meta-llama-3-70b-instruct"}, []llm.Message{
+		{Role: llm.RoleUser, Content: "What's the weather in Paris?"},
+	}, []llm.Tool{weather}, llm.ToolOptions{
+		Chat: llm.ChatOptions{
+			OnToken:         func(token string) { streamed.WriteString(token) },
+			PollingInterval: time.Millisecond,
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "It's sunny in Paris.", resp.Message.Content)
+	assert.Equal(t, "Let me check. It's sunny in Paris.", streamed.String())
+	require.Len(t, resp.Calls, 2)
+	assert.Equal(t, "get_weather", resp.Calls[0].Name)
+	assert.JSONEq(t, `{"city": "Paris"}`, string(resp.Calls[0].Arguments))
+	assert.Equal(t, "sunny", resp.Calls[0].Result)
+	assert.EqualError(t, resp.Calls[1].Err, "unknown city")
+	assert.Equal(t, llm.Usage{InputTokens: 30, OutputTokens: 15}, resp.Usage)
+	assert.Len(t, resp.Predictions, 3)
+	assert.Len(t, resp.Messages, 6)
+
+	require.Len(t, prompts, 3)
+	assert.Contains(t, prompts[1], `<tool_result name="get_weather">sunny</tool_result>`)
+	assert.Contains(t, prompts[2], `<tool_result name="get_weather">error: unknown city</tool_result>`)
+}
@@ -0,0 +1,121 @@
+package replicate
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// dataURLPattern matches data URLs, whose payloads are redacted from logs.
+var dataURLPattern = regexp.MustCompile(`data:([a-zA-Z0-9.+/-]*)((?:;[a-zA-Z0-9=._-]+)*),[^\s"'\\]*`)
+
+// webhookSecretPattern matches webhook signing secrets, such as the one
+// returned by GetDefaultWebhookSecret.
+var webhookSecretPattern = regexp.MustCompile(`whsec_[a-zA-Z0-9+/=]+`)
+
+// cookieHeaders are the headers whose values are redacted in full.
+var cookieHeaders = map[string]bool{"Cookie": true, "Set-Cookie": true}
+
+// WithLogger sets a logger for the client.
+//
+// The client logs each request attempt and response at debug level, and
+// retries and stream reconnections at info level. The API token, webhook
+// signing secrets, and the payloads of data URLs are redacted from all log
+// messages.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(o *clientOptions) error {
+		o.logger = logger
+		return nil
+	}
+}
+
+// redact removes API tokens, webhook signing secrets, and data URL payloads
+// from s.
+func (r *Client) redact(s string) string {
+	return redactSecrets(s, r.recentTokens()...)
+}
+
+// redactHeader returns a copy of header with credentials and cookies
+// redacted.
+func (r *Client) redactHeader(header http.Header) http.Header {
+	redactedHeader := make(http.Header, len(header))
+	for key, values := range header {
+		copied := make([]string, len(values))
+		for i, value := range values {
+			switch key := http.CanonicalHeaderKey(key); {
+			case key == "Authorization":
+				scheme, _, _ := strings.Cut(value, " ")
+				value = scheme + " " + redacted
+			case cookieHeaders[key]:
+				value = redacted
+			}
+			copied[i] = r.redact(value)
+		}
+		redactedHeader[key] = copied
+	}
+	return redactedHeader
+}
+
+func redactSecrets(s string, tokens ...string) string {
+	for _, token := range tokens {
+		if token != "" {
+			s = strings.ReplaceAll(s, token, redacted)
+		}
+	}
+	s = webhookSecretPattern.ReplaceAllString(s, "whsec_"+redacted)
+	return dataURLPattern.ReplaceAllString(s, "data:$1$2,"+redacted)
+}
+
+func (r *Client) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	logger := r.options.logger
+	if logger == nil || !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func (r *Client) errorAttr(err error) slog.Attr {
+	return slog.String("error", r.redact(err.Error()))
+}
+
+func (r *Client) logAttempt(request *http.Request, op operation, attempt int, response *http.Response, err error, duration time.Duration) {
+	ctx := request.Context()
+	attrs := []slog.Attr{
+		slog.String("operation", op.name),
+		slog.String("method", request.Method),
+		slog.String("url", r.redact(request.URL.String())),
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", duration),
+	}
+
+	if err != nil || response == nil {
+		if err != nil {
+			attrs = append(attrs, r.errorAttr(err))
+		}
+		r.log(ctx, slog.LevelDebug, "replicate request failed", attrs...)
+		return
+	}
+
+	attrs = append(attrs, slog.Int("status", response.StatusCode))
+	if id := response.Header.Get(requestIDHeader); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	r.log(ctx, slog.LevelDebug, "replicate request completed", attrs...)
+}
+
+func (r *Client) logRetry(ctx context.Context, op operation, attempt int, delay time.Duration, err error) {
+	attrs := []slog.Attr{
+		slog.String("operation", op.name),
+		slog.Int("attempt", attempt),
+		slog.Duration("delay", delay),
+	}
+	if err != nil {
+		attrs = append(attrs, r.errorAttr(err))
+	}
+	r.log(ctx, slog.LevelInfo, "retrying replicate request", attrs...)
+}
@@ -0,0 +1,70 @@
+package replicate_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestWithLogger(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"detail": "bad input data:image/png;base64,iVBORw0KGgo= for token secret-token"}`))
+			return
+		}
+		w.Header().Set("X-Request-Id", "req-123")
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("secret-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(2, &replicate.ConstantBackoff{}),
+		replicate.WithLogger(logger),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetPrediction(context.Background(), "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.NotContains(t, output, "secret-token")
+	assert.NotContains(t, output, "iVBORw0KGgo")
+
+	var records []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var record map[string]any
+		require.NoError(t, dec.Decode(&record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 3)
+
+	assert.Equal(t, "DEBUG", records[0]["level"])
+	assert.Equal(t, "GetPrediction", records[0]["operation"])
+	assert.EqualValues(t, 500, records[0]["status"])
+
+	assert.Equal(t, "INFO", records[1]["level"])
+	assert.Equal(t, "retrying replicate request", records[1]["msg"])
+	assert.EqualValues(t, 1, records[1]["attempt"])
+	assert.Contains(t, records[1]["error"], "data:image/png;base64,[REDACTED]")
+
+	assert.EqualValues(t, 200, records[2]["status"])
+	assert.Equal(t, "req-123", records[2]["request_id"])
+}
@@ -0,0 +1,69 @@
+package replicate
+
+import (
+	"time"
+)
+
+// Metrics receives measurements from a client, for export to a monitoring
+// system such as Prometheus.
+//
+// Operations are named after the client method that made the request, such
+// as "CreatePrediction" or "GetPrediction". Methods are called synchronously
+// and must be safe for concurrent use. Embed NopMetrics to implement only
+// some of them.
+type Metrics interface {
+	// ObserveRequest is called after each HTTP attempt. statusCode is 0 if
+	// no response was received, in which case err is set.
+	ObserveRequest(operation string, statusCode int, duration time.Duration, err error)
+
+	// ObserveRetry is called before a failed attempt is retried.
+	ObserveRetry(operation string, attempt int, delay time.Duration)
+
+	// ObserveRateLimited is called for each response with status 429.
+	ObserveRateLimited(operation string)
+
+	// StreamStarted and StreamEnded are called when a prediction output
+	// stream connects and disconnects, so their difference is the number of
+	// active streams.
+	StreamStarted()
+	StreamEnded()
+
+	// ObserveWait is called when waiting for a prediction ends, with the
+	// last known status of the prediction and the time spent waiting.
+	ObserveWait(status Status, duration time.Duration)
+}
+
+// UsageMetrics is implemented by Metrics implementations that also record
+// usage, for example per tenant for chargeback. If the client's Metrics
+// implements it, ObserveUsage is called with the usage of each prediction
+// the client sees complete, synchronously, so it should return quickly.
+type UsageMetrics interface {
+	ObserveUsage(record UsageRecord)
+}
+
+// NopMetrics is a Metrics implementation that does nothing.
+type NopMetrics struct{}
+
+var _ Metrics = NopMetrics{}
+
+func (NopMetrics) ObserveRequest(string, int, time.Duration, error) {}
+func (NopMetrics) ObserveRetry(string, int, time.Duration)          {}
+func (NopMetrics) ObserveRateLimited(string)                        {}
+func (NopMetrics) StreamStarted()                                   {}
+func (NopMetrics) StreamEnded()                                     {}
+func (NopMetrics) ObserveWait(Status, time.Duration)                {}
+
+// WithMetrics sets the Metrics implementation the client reports to.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(o *clientOptions) error {
+		o.metrics = metrics
+		return nil
+	}
+}
+
+func (r *Client) metrics() Metrics {
+	if r.options.metrics == nil {
+		return NopMetrics{}
+	}
+	return r.options.metrics
+}
@@ -0,0 +1,83 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+type recordingMetrics struct {
+	replicate.NopMetrics
+
+	mu          sync.Mutex
+	requests    []string
+	retries     int
+	rateLimited []string
+	waits       []replicate.Status
+}
+
+func (m *recordingMetrics) ObserveRequest(operation string, statusCode int, _ time.Duration, _ error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, operation+" "+http.StatusText(statusCode))
+}
+
+func (m *recordingMetrics) ObserveRetry(string, int, time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func (m *recordingMetrics) ObserveRateLimited(operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rateLimited = append(m.rateLimited, operation)
+}
+
+func (m *recordingMetrics) ObserveWait(status replicate.Status, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.waits = append(m.waits, status)
+}
+
+func TestMetrics(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Succeeded})
+	}))
+	defer mockServer.Close()
+
+	metrics := &recordingMetrics{}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(2, &replicate.ConstantBackoff{}),
+		replicate.WithMetrics(metrics),
+	)
+	require.NoError(t, err)
+
+	prediction := &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting}
+	err = client.Wait(context.Background(), prediction, replicate.WithPollingInterval(time.Millisecond))
+	require.NoError(t, err)
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	assert.Equal(t, []string{"GetPrediction Too Many Requests", "GetPrediction OK"}, metrics.requests)
+	assert.Equal(t, 1, metrics.retries)
+	assert.Equal(t, []string{"GetPrediction"}, metrics.rateLimited)
+	assert.Equal(t, []replicate.Status{replicate.Succeeded}, metrics.waits)
+}
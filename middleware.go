@@ -0,0 +1,61 @@
+package replicate
+
+import (
+	"errors"
+	"net/http"
+)
+
+// RoundTripFunc sends an HTTP request and returns its response.
+// It implements http.RoundTripper.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the function that sends a request, to inspect or modify
+// requests and responses.
+//
+// Like an http.RoundTripper, a middleware must not modify the request it's
+// given; clone it with http.Request.Clone first.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds middleware that is applied to every HTTP request made by
+// the client, including retries, streams, and file downloads.
+//
+// Middleware wraps the transport of the client's http.Client, so it runs once
+// for each attempt and redirect. The first middleware added is the outermost.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(o *clientOptions) error {
+		for _, m := range middleware {
+			if m == nil {
+				return errors.New("middleware must not be nil")
+			}
+		}
+		o.middleware = append(o.middleware, middleware...)
+		return nil
+	}
+}
+
+// withMiddleware returns a copy of httpClient whose transport is wrapped by
+// middleware, or httpClient itself if there is none.
+func withMiddleware(httpClient *http.Client, middleware []Middleware) *http.Client {
+	if len(middleware) == 0 {
+		return httpClient
+	}
+
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	next := RoundTripFunc(transport.RoundTrip)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		next = middleware[i](next)
+	}
+
+	wrapped := *httpClient
+	wrapped.Transport = next
+	return &wrapped
+}
@@ -0,0 +1,73 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "outer,inner", r.Header.Get("X-Middleware"))
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	var order []string
+	tag := func(name string) replicate.Middleware {
+		return func(next replicate.RoundTripFunc) replicate.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				req = req.Clone(req.Context())
+				value := name
+				if v := req.Header.Get("X-Middleware"); v != "" {
+					value = v + "," + name
+				}
+				req.Header.Set("X-Middleware", value)
+				resp, err := next(req)
+				order = append(order, value+" done")
+				return resp, err
+			}
+		}
+	}
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithMiddleware(tag("outer"), tag("inner")),
+	)
+	require.NoError(t, err)
+
+	prediction, err := client.GetPrediction(context.Background(), "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, []string{"outer", "inner", "outer,inner done", "outer done"}, order)
+}
+
+func TestWithMiddlewareShortCircuit(t *testing.T) {
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL("http://replicate.invalid"),
+		replicate.WithMiddleware(func(replicate.RoundTripFunc) replicate.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				rec := httptest.NewRecorder()
+				json.NewEncoder(rec).Encode(&replicate.Prediction{ID: "fake"})
+				resp := rec.Result()
+				resp.Request = req
+				return resp, nil
+			}
+		}),
+	)
+	require.NoError(t, err)
+
+	prediction, err := client.GetPrediction(context.Background(), "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", prediction.ID)
+}
@@ -0,0 +1,21 @@
+// Package mocks provides a mock of the replicate.Replicate interface, for
+// tests of code that calls the Replicate API.
+//
+// ReplicateMock has a field for each method of the interface. Set the fields
+// for the methods the code under test calls; calling a method whose field is
+// nil panics. Each call is recorded, and can be inspected with the method's
+// Calls accessor:
+//
+//	mock := &mocks.ReplicateMock{
+//		GetPredictionFunc: func(ctx context.Context, id string) (*replicate.Prediction, error) {
+//			return &replicate.Prediction{ID: id, Status: replicate.Succeeded}, nil
+//		},
+//	}
+//	// ...
+//	if len(mock.GetPredictionCalls()) != 1 { ... }
+//
+// The mock is regenerated whenever the interface changes, so it always
+// implements the interface of the same release.
+package mocks
+
+//go:generate go run github.com/matryer/moq@v0.3.4 -out replicate_mock.go -pkg mocks .. Replicate
@@ -0,0 +1,36 @@
+package mocks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/mocks"
+)
+
+func TestReplicateMock(t *testing.T) {
+	mock := &mocks.ReplicateMock{
+		RunWithOptionsFunc: func(ctx context.Context, identifier string, input replicate.PredictionInput, webhook *replicate.Webhook, opts ...replicate.RunOption) (replicate.PredictionOutput, error) {
+			return "output for " + identifier, nil
+		},
+	}
+
+	var client replicate.Replicate = mock
+	input := replicate.PredictionInput{"prompt": "hello"}
+	output, err := client.RunWithOptions(context.Background(), "owner/model", input, nil, replicate.WithBlockUntilDone())
+	require.NoError(t, err)
+	assert.Equal(t, "output for owner/model", output)
+
+	calls := mock.RunWithOptionsCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "owner/model", calls[0].Identifier)
+	assert.Equal(t, input, calls[0].Input)
+	assert.Len(t, calls[0].Opts, 1)
+
+	assert.PanicsWithValue(t, "ReplicateMock.GetPredictionFunc: method is nil but Replicate.GetPrediction was just called", func() {
+		_, _ = client.GetPrediction(context.Background(), "abc")
+	})
+}
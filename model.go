@@ -71,9 +71,8 @@ func (m *ModelVersion) UnmarshalJSON(data []byte) error {
 }
 
 // ListModels lists public models.
-func (r *Client) ListModels(ctx context.Context) (*Page[Model], error) {
-	response := &Page[Model]{}
-	err := r.fetch(ctx, http.MethodGet, "/models", nil, response)
+func (r *Client) ListModels(ctx context.Context, opts ...ListOption) (*Page[Model], error) {
+	response, err := listPage[Model](ctx, r, "/models", opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list models: %w", err)
 	}
@@ -105,8 +104,17 @@ func (r *Client) GetModel(ctx context.Context, modelOwner string, modelName stri
 	return model, nil
 }
 
-// CreateModel creates a new model.
+// CreateModel creates a new model. If modelOwner is empty, the model is
+// created under the account returned by CurrentOwner.
 func (r *Client) CreateModel(ctx context.Context, modelOwner string, modelName string, options CreateModelOptions) (*Model, error) {
+	modelOwner, err := r.resolveOwner(ctx, modelOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model: %w", err)
+	}
+	if err := r.validateHardware(ctx, options.Hardware); err != nil {
+		return nil, fmt.Errorf("failed to create model: %w", err)
+	}
+
 	model := &Model{}
 
 	body := struct {
@@ -119,7 +127,7 @@ func (r *Client) CreateModel(ctx context.Context, modelOwner string, modelName s
 		CreateModelOptions: options,
 	}
 
-	err := r.fetch(ctx, http.MethodPost, "/models", body, model)
+	err = r.fetch(ctx, http.MethodPost, "/models", body, model)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create model: %w", err)
 	}
@@ -137,9 +145,8 @@ func (r *Client) DeleteModel(ctx context.Context, modelOwner string, modelName s
 }
 
 // ListModelVersions lists the versions of a model.
-func (r *Client) ListModelVersions(ctx context.Context, modelOwner string, modelName string) (*Page[ModelVersion], error) {
-	response := &Page[ModelVersion]{}
-	err := r.fetch(ctx, http.MethodGet, fmt.Sprintf("/models/%s/%s/versions", modelOwner, modelName), nil, response)
+func (r *Client) ListModelVersions(ctx context.Context, modelOwner string, modelName string, opts ...ListOption) (*Page[ModelVersion], error) {
+	response, err := listPage[ModelVersion](ctx, r, fmt.Sprintf("/models/%s/%s/versions", modelOwner, modelName), opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list model versions: %w", err)
 	}
@@ -175,7 +182,7 @@ func (r *Client) CreatePredictionWithModel(ctx context.Context, modelOwner strin
 	}
 
 	prediction := &Prediction{}
-	if err := r.do(req, prediction); err != nil {
+	if err := r.createPrediction(req, prediction); err != nil {
 		return nil, fmt.Errorf("failed to create prediction with model: %w", err)
 	}
 
@@ -0,0 +1,161 @@
+package replicate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ModelQueueMetrics is implemented by Metrics that also observe the
+// per-model concurrency caps set with WithModelConcurrency.
+type ModelQueueMetrics interface {
+	// ObserveModelQueue is called whenever a prediction starts waiting for,
+	// takes, or gives back a slot of a capped model, with the number of
+	// predictions waiting and running afterwards.
+	ObserveModelQueue(model string, queued, running int)
+}
+
+// ModelConcurrencyStats describes the use of a model's concurrency cap.
+type ModelConcurrencyStats struct {
+	// Limit is the maximum number of predictions in flight at once.
+	Limit int
+
+	// Running is the number of predictions in flight.
+	Running int
+
+	// Queued is the number of predictions waiting for a slot.
+	Queued int
+}
+
+// WithModelConcurrency caps the number of predictions of a model in flight
+// at once, for models that queue badly when flooded. model is "owner/name",
+// which covers every version of the model, or "deployments/owner/name" for a
+// deployment.
+//
+// The cap applies to the batch runners, RunBatch, MapPredictions, and
+// Dispatcher.RunBatch, and to Submit and the Scheduler. A prediction holds a
+// slot from when it's created until it finishes, and predictions beyond the
+// cap wait for a slot.
+func WithModelConcurrency(model string, limit int) ClientOption {
+	return func(o *clientOptions) error {
+		if limit <= 0 {
+			return fmt.Errorf("concurrency limit for %s must be positive", model)
+		}
+		key := strings.TrimPrefix(model, "deployments/")
+		if owner, name, ok := strings.Cut(key, "/"); !ok || owner == "" || name == "" || strings.ContainsAny(name, "/:") {
+			return fmt.Errorf("invalid model %q, it must be in the format \"owner/name\" or \"deployments/owner/name\"", model)
+		}
+		if o.modelConcurrency == nil {
+			o.modelConcurrency = make(map[string]int)
+		}
+		o.modelConcurrency[model] = limit
+		return nil
+	}
+}
+
+// ModelConcurrencyStats returns a snapshot of the use of each model's
+// concurrency cap, keyed by model as given to WithModelConcurrency.
+func (r *Client) ModelConcurrencyStats() map[string]ModelConcurrencyStats {
+	stats := make(map[string]ModelConcurrencyStats, len(r.options.modelConcurrency))
+	for model, limit := range r.options.modelConcurrency {
+		stats[model] = ModelConcurrencyStats{Limit: limit}
+	}
+
+	s := &r.state.modelSlots
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, slots := range s.slots {
+		if limit, ok := r.options.modelConcurrency[key.model]; ok && limit == key.limit {
+			stats[key.model] = ModelConcurrencyStats{Limit: limit, Running: slots.running, Queued: slots.queued}
+		}
+	}
+	return stats
+}
+
+// modelKey returns the key a model's concurrency cap is set with.
+func modelKey(model ModelRef) string {
+	if model.Deployment != "" {
+		return "deployments/" + model.Deployment
+	}
+	if id, err := ParseIdentifier(model.Identifier); err == nil {
+		return id.Owner + "/" + id.Name
+	}
+	return model.Identifier
+}
+
+// modelSlotKey identifies a cap. Derived clients that set a different limit
+// for a model get their own slots.
+type modelSlotKey struct {
+	model string
+	limit int
+}
+
+type modelSlotSet struct {
+	mu    sync.Mutex
+	slots map[modelSlotKey]*modelSlots
+}
+
+type modelSlots struct {
+	sem     chan struct{}
+	running int
+	queued  int
+}
+
+// acquireModelSlot waits for a slot of the model's concurrency cap, if it
+// has one. The returned function gives the slot back.
+func (r *Client) acquireModelSlot(ctx context.Context, model ModelRef) (func(), error) {
+	name := modelKey(model)
+	limit, ok := r.options.modelConcurrency[name]
+	if !ok {
+		return func() {}, nil
+	}
+
+	s := &r.state.modelSlots
+	key := modelSlotKey{model: name, limit: limit}
+	s.mu.Lock()
+	if s.slots == nil {
+		s.slots = make(map[modelSlotKey]*modelSlots)
+	}
+	slots := s.slots[key]
+	if slots == nil {
+		slots = &modelSlots{sem: make(chan struct{}, limit)}
+		s.slots[key] = slots
+	}
+	slots.queued++
+	r.observeModelQueue(name, slots)
+	s.mu.Unlock()
+
+	select {
+	case slots.sem <- struct{}{}:
+	case <-ctx.Done():
+		s.mu.Lock()
+		slots.queued--
+		r.observeModelQueue(name, slots)
+		s.mu.Unlock()
+		return nil, contextError(ctx)
+	}
+
+	s.mu.Lock()
+	slots.queued--
+	slots.running++
+	r.observeModelQueue(name, slots)
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			slots.running--
+			r.observeModelQueue(name, slots)
+			s.mu.Unlock()
+			<-slots.sem
+		})
+	}, nil
+}
+
+func (r *Client) observeModelQueue(model string, slots *modelSlots) {
+	if m, ok := r.metrics().(ModelQueueMetrics); ok {
+		m.ObserveModelQueue(model, slots.queued, slots.running)
+	}
+}
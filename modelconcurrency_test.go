@@ -0,0 +1,95 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+type queueMetrics struct {
+	replicate.NopMetrics
+
+	mu         sync.Mutex
+	maxQueued  int
+	maxRunning int
+}
+
+func (m *queueMetrics) ObserveModelQueue(model string, queued, running int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxQueued = max(m.maxQueued, queued)
+	m.maxRunning = max(m.maxRunning, running)
+}
+
+func TestWithModelConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight, created := 0, 0, 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPost:
+			inFlight++
+			created++
+			maxInFlight = max(maxInFlight, inFlight)
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: fmt.Sprint(created), Status: replicate.Starting})
+		case http.MethodGet:
+			inFlight--
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Succeeded})
+		}
+	}))
+	defer mockServer.Close()
+
+	clock := replicatetest.NewFakeClock(time.Now())
+	clock.AutoAdvance(true)
+	metrics := &queueMetrics{}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithClock(clock),
+		replicate.WithMetrics(metrics),
+		replicate.WithModelConcurrency("owner/model", 2),
+	)
+	require.NoError(t, err)
+
+	inputs := make([]replicate.PredictionInput, 8)
+	for i := range inputs {
+		inputs[i] = replicate.PredictionInput{"i": i}
+	}
+	result := client.MapPredictions(context.Background(), replicate.ModelRef{Identifier: "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"}, inputs, 8)
+	require.NoError(t, result.Err())
+
+	future := client.Submit(context.Background(), "owner/model", replicate.PredictionInput{})
+	_, err = future.Result(context.Background())
+	require.NoError(t, err)
+
+	mu.Lock()
+	assert.Equal(t, 9, created)
+	assert.LessOrEqual(t, maxInFlight, 2)
+	mu.Unlock()
+
+	metrics.mu.Lock()
+	assert.Equal(t, 2, metrics.maxRunning)
+	assert.Positive(t, metrics.maxQueued)
+	metrics.mu.Unlock()
+
+	assert.Equal(t, map[string]replicate.ModelConcurrencyStats{"owner/model": {Limit: 2}}, client.ModelConcurrencyStats())
+
+	_, err = replicate.NewClient(replicate.WithToken("test-token"), replicate.WithModelConcurrency("owner/model", 0))
+	assert.ErrorContains(t, err, "must be positive")
+	_, err = replicate.NewClient(replicate.WithToken("test-token"), replicate.WithModelConcurrency("owner/model:version", 1))
+	assert.ErrorContains(t, err, "invalid model")
+}
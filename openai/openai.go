@@ -0,0 +1,282 @@
+// Package openai adapts Replicate language models to the shape of the
+// OpenAI chat completions API, as used by popular Go OpenAI clients, so an
+// application can switch providers by swapping the client constructor.
+//
+// The request's Model is a Replicate model identifier, such as
+// "meta/meta-llama-3-70b-instruct", or a deployment in the form
+// "deployments/owner/name".
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/llm"
+)
+
+const (
+	ChatMessageRoleSystem    = "system"
+	ChatMessageRoleUser      = "user"
+	ChatMessageRoleAssistant = "assistant"
+)
+
+// FinishReasonStop is the finish reason of every completion, since
+// Replicate doesn't report why generation stopped.
+const FinishReasonStop = "stop"
+
+// ChatCompletionMessage is a message in a conversation.
+type ChatCompletionMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatCompletionRequest is a request to complete a conversation.
+type ChatCompletionRequest struct {
+	Model       string                  `json:"model"`
+	Messages    []ChatCompletionMessage `json:"messages"`
+	MaxTokens   int                     `json:"max_tokens,omitempty"`
+	Temperature float32                 `json:"temperature,omitempty"`
+	TopP        float32                 `json:"top_p,omitempty"`
+	Stop        []string                `json:"stop,omitempty"`
+	Stream      bool                    `json:"stream,omitempty"`
+}
+
+// Usage is the number of tokens a completion used.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatCompletionChoice is a completion of a conversation.
+type ChatCompletionChoice struct {
+	Index        int                   `json:"index"`
+	Message      ChatCompletionMessage `json:"message"`
+	FinishReason string                `json:"finish_reason"`
+}
+
+// ChatCompletionResponse is the response to a ChatCompletionRequest. Its
+// ID is the ID of the Replicate prediction.
+type ChatCompletionResponse struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []ChatCompletionChoice `json:"choices"`
+	Usage   Usage                  `json:"usage"`
+}
+
+// ChatCompletionStreamChoiceDelta is the part of a message in a streamed
+// chunk.
+type ChatCompletionStreamChoiceDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// ChatCompletionStreamChoice is a chunk of a streamed completion.
+type ChatCompletionStreamChoice struct {
+	Index        int                             `json:"index"`
+	Delta        ChatCompletionStreamChoiceDelta `json:"delta"`
+	FinishReason string                          `json:"finish_reason,omitempty"`
+}
+
+// ChatCompletionStreamResponse is a chunk of a streamed completion.
+type ChatCompletionStreamResponse struct {
+	ID      string                       `json:"id"`
+	Object  string                       `json:"object"`
+	Created int64                        `json:"created"`
+	Model   string                       `json:"model"`
+	Choices []ChatCompletionStreamChoice `json:"choices"`
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithPromptFormat sets how conversations are rendered as model input. The
+// default is llm.DefaultFormat.
+func WithPromptFormat(format llm.PromptFormat) Option {
+	return func(c *Client) {
+		c.format = format
+	}
+}
+
+// Client creates chat completions with Replicate predictions.
+type Client struct {
+	client *replicate.Client
+	format llm.PromptFormat
+}
+
+// NewClient returns a client that runs completions with client.
+func NewClient(client *replicate.Client, opts ...Option) *Client {
+	c := &Client{client: client}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// CreateChatCompletion completes a conversation.
+func (c *Client) CreateChatCompletion(ctx context.Context, request ChatCompletionRequest) (ChatCompletionResponse, error) {
+	if request.Stream {
+		return ChatCompletionResponse{}, errors.New("streaming requests must use CreateChatCompletionStream")
+	}
+
+	resp, err := c.chat(ctx, request, nil)
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+
+	usage := Usage{PromptTokens: resp.Usage.InputTokens, CompletionTokens: resp.Usage.OutputTokens}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return ChatCompletionResponse{
+		ID:      resp.Prediction.ID,
+		Object:  "chat.completion",
+		Created: created(resp.Prediction),
+		Model:   request.Model,
+		Choices: []ChatCompletionChoice{{
+			Message:      ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: resp.Message.Content},
+			FinishReason: FinishReasonStop,
+		}},
+		Usage: usage,
+	}, nil
+}
+
+// CreateChatCompletionStream completes a conversation, streaming the reply
+// as it's generated. The stream must be closed.
+func (c *Client) CreateChatCompletionStream(ctx context.Context, request ChatCompletionRequest) (*ChatCompletionStream, error) {
+	if _, err := parseModel(request.Model); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &ChatCompletionStream{
+		model:  request.Model,
+		chunks: make(chan string, 64),
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	go func() {
+		defer close(s.done)
+		defer close(s.chunks)
+		resp, err := c.chat(ctx, request, func(token string) {
+			select {
+			case s.chunks <- token:
+			case <-ctx.Done():
+			}
+		})
+		s.resp, s.err = resp, err
+	}()
+	return s, nil
+}
+
+func (c *Client) chat(ctx context.Context, request ChatCompletionRequest, onToken func(string)) (*llm.ChatResponse, error) {
+	model, err := parseModel(request.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]llm.Message, len(request.Messages))
+	for i, m := range request.Messages {
+		messages[i] = llm.Message{Role: llm.Role(m.Role), Content: m.Content}
+	}
+	return llm.Chat(ctx, c.client, model, messages, llm.ChatOptions{
+		Temperature:   float64(request.Temperature),
+		MaxTokens:     request.MaxTokens,
+		TopP:          float64(request.TopP),
+		StopSequences: request.Stop,
+		Format:        c.format,
+		OnToken:       onToken,
+	})
+}
+
+func parseModel(model string) (replicate.ModelRef, error) {
+	if model == "" {
+		return replicate.ModelRef{}, errors.New("model must be set")
+	}
+	if deployment, ok := strings.CutPrefix(model, "deployments/"); ok {
+		return replicate.ModelRef{Deployment: deployment}, nil
+	}
+	if _, err := replicate.ParseIdentifier(model); err != nil {
+		return replicate.ModelRef{}, fmt.Errorf("invalid model %q: %w", model, err)
+	}
+	return replicate.ModelRef{Identifier: model}, nil
+}
+
+func created(prediction *replicate.Prediction) int64 {
+	if t, err := time.Parse(time.RFC3339Nano, prediction.CreatedAt); err == nil {
+		return t.Unix()
+	}
+	return time.Now().Unix()
+}
+
+// ChatCompletionStream is a streamed chat completion.
+type ChatCompletionStream struct {
+	model  string
+	chunks chan string
+	done   chan struct{}
+	cancel context.CancelFunc
+
+	// resp and err are set when done is closed.
+	resp *llm.ChatResponse
+	err  error
+
+	mu       sync.Mutex
+	sentRole bool
+	finished bool
+}
+
+// Recv returns the next chunk of the completion. After the last chunk,
+// which has a finish reason, it returns io.EOF.
+func (s *ChatCompletionStream) Recv() (ChatCompletionStreamResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finished {
+		return ChatCompletionStreamResponse{}, io.EOF
+	}
+
+	token, ok := <-s.chunks
+	if ok {
+		delta := ChatCompletionStreamChoiceDelta{Content: token}
+		if !s.sentRole {
+			delta.Role = ChatMessageRoleAssistant
+			s.sentRole = true
+		}
+		return s.chunk(ChatCompletionStreamChoice{Delta: delta}, nil), nil
+	}
+
+	<-s.done
+	s.finished = true
+	if s.err != nil {
+		return ChatCompletionStreamResponse{}, s.err
+	}
+	return s.chunk(ChatCompletionStreamChoice{FinishReason: FinishReasonStop}, s.resp.Prediction), nil
+}
+
+// chunk returns a chunk with choice. Only the last chunk has the ID of the
+// prediction, which isn't known until the completion has finished.
+func (s *ChatCompletionStream) chunk(choice ChatCompletionStreamChoice, prediction *replicate.Prediction) ChatCompletionStreamResponse {
+	resp := ChatCompletionStreamResponse{
+		Object:  "chat.completion.chunk",
+		Model:   s.model,
+		Choices: []ChatCompletionStreamChoice{choice},
+	}
+	if prediction != nil {
+		resp.ID = prediction.ID
+		resp.Created = created(prediction)
+	}
+	return resp
+}
+
+// Close stops the stream. It doesn't cancel the prediction.
+func (s *ChatCompletionStream) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
@@ -0,0 +1,108 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/openai"
+)
+
+func newTestClient(t *testing.T) *openai.Client {
+	t.Helper()
+
+	inputTokens, outputTokens := 9, 2
+	mockServer := httptest.NewUnstartedServer(nil)
+	mockServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prediction := &replicate.Prediction{
+			ID:        "ufawqhfynnddngldkgtslldrkq",
+			Status:    replicate.Starting,
+			CreatedAt: "2024-05-01T12:00:00Z",
+			URLs:      map[string]string{"stream": mockServer.URL + "/stream"},
+		}
+		switch r.URL.Path {
+		case "/deployments/acme/llama/predictions":
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "Hi", body.Input["prompt"])
+			assert.Equal(t, float64(16), body.Input["max_tokens"])
+			// Finish immediately, so the client doesn't poll.
+			prediction.Status = replicate.Succeeded
+			prediction.Output = []any{"Hello", "!"}
+			prediction.Metrics = &replicate.PredictionMetrics{InputTokenCount: &inputTokens, OutputTokenCount: &outputTokens}
+		case "/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: output\ndata: Hello\n\nevent: output\ndata: !\n\nevent: done\ndata: {}\n\n")
+			return
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(prediction)
+	})
+	mockServer.Start()
+	t.Cleanup(mockServer.Close)
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+	return openai.NewClient(client)
+}
+
+func TestCreateChatCompletion(t *testing.T) {
+	client := newTestClient(t)
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:     "deployments/acme/llama",
+		Messages:  []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+		MaxTokens: 16,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", resp.ID)
+	assert.Equal(t, int64(1714564800), resp.Created)
+	require.Len(t, resp.Choices, 1)
+	assert.Equal(t, openai.ChatCompletionMessage{Role: "assistant", Content: "Hello!"}, resp.Choices[0].Message)
+	assert.Equal(t, openai.Usage{PromptTokens: 9, CompletionTokens: 2, TotalTokens: 11}, resp.Usage)
+
+	_, err = client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "gpt-4"})
+	assert.ErrorContains(t, err, "invalid model")
+}
+
+func TestCreateChatCompletionStream(t *testing.T) {
+	client := newTestClient(t)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:     "deployments/acme/llama",
+		Messages:  []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+		MaxTokens: 16,
+		Stream:    true,
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var content string
+	var last openai.ChatCompletionStreamResponse
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content += chunk.Choices[0].Delta.Content
+		last = chunk
+	}
+	assert.Equal(t, "Hello!", content)
+	assert.Equal(t, openai.FinishReasonStop, last.Choices[0].FinishReason)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", last.ID)
+}
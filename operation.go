@@ -0,0 +1,114 @@
+package replicate
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// operationClass groups API operations with similar latency profiles, which
+// share timeout settings.
+type operationClass int
+
+const (
+	operationClassDefault operationClass = iota
+	operationClassCreate
+	operationClassPoll
+	operationClassUpload
+)
+
+// operation identifies the API operation a request belongs to.
+type operation struct {
+	// name is the name of the client method that makes the request.
+	name  string
+	class operationClass
+}
+
+type route struct {
+	method  string
+	pattern []string
+	op      operation
+}
+
+func newRoute(method, pattern, name string, class operationClass) route {
+	return route{
+		method:  method,
+		pattern: strings.Split(pattern, "/"),
+		op:      operation{name: name, class: class},
+	}
+}
+
+// routes maps API paths, relative to the base URL, to operations.
+// A "*" segment matches any single path segment.
+var routes = []route{
+	newRoute(http.MethodGet, "account", "GetCurrentAccount", operationClassDefault),
+
+	newRoute(http.MethodGet, "collections", "ListCollections", operationClassDefault),
+	newRoute(http.MethodGet, "collections/*", "GetCollection", operationClassDefault),
+
+	newRoute(http.MethodGet, "deployments", "ListDeployments", operationClassDefault),
+	newRoute(http.MethodPost, "deployments", "CreateDeployment", operationClassCreate),
+	newRoute(http.MethodGet, "deployments/*/*", "GetDeployment", operationClassDefault),
+	newRoute(http.MethodPatch, "deployments/*/*", "UpdateDeployment", operationClassDefault),
+	newRoute(http.MethodDelete, "deployments/*/*", "DeleteDeployment", operationClassDefault),
+	newRoute(http.MethodPost, "deployments/*/*/predictions", "CreatePredictionWithDeployment", operationClassCreate),
+
+	newRoute(http.MethodGet, "files", "ListFiles", operationClassDefault),
+	newRoute(http.MethodPost, "files", "CreateFile", operationClassUpload),
+	newRoute(http.MethodGet, "files/*", "GetFile", operationClassDefault),
+	newRoute(http.MethodDelete, "files/*", "DeleteFile", operationClassDefault),
+
+	newRoute(http.MethodGet, "hardware", "ListHardware", operationClassDefault),
+
+	newRoute(http.MethodGet, "models", "ListModels", operationClassDefault),
+	newRoute("QUERY", "models", "SearchModels", operationClassDefault),
+	newRoute(http.MethodPost, "models", "CreateModel", operationClassCreate),
+	newRoute(http.MethodGet, "models/*/*", "GetModel", operationClassDefault),
+	newRoute(http.MethodDelete, "models/*/*", "DeleteModel", operationClassDefault),
+	newRoute(http.MethodPost, "models/*/*/predictions", "CreatePredictionWithModel", operationClassCreate),
+	newRoute(http.MethodGet, "models/*/*/versions", "ListModelVersions", operationClassDefault),
+	newRoute(http.MethodGet, "models/*/*/versions/*", "GetModelVersion", operationClassDefault),
+	newRoute(http.MethodDelete, "models/*/*/versions/*", "DeleteModelVersion", operationClassDefault),
+	newRoute(http.MethodPost, "models/*/*/versions/*/trainings", "CreateTraining", operationClassCreate),
+
+	newRoute(http.MethodGet, "predictions", "ListPredictions", operationClassDefault),
+	newRoute(http.MethodPost, "predictions", "CreatePrediction", operationClassCreate),
+	newRoute(http.MethodGet, "predictions/*", "GetPrediction", operationClassPoll),
+	newRoute(http.MethodPost, "predictions/*/cancel", "CancelPrediction", operationClassDefault),
+
+	newRoute(http.MethodGet, "trainings", "ListTrainings", operationClassDefault),
+	newRoute(http.MethodGet, "trainings/*", "GetTraining", operationClassPoll),
+	newRoute(http.MethodPost, "trainings/*/cancel", "CancelTraining", operationClassDefault),
+
+	newRoute(http.MethodGet, "webhooks/default/secret", "GetDefaultWebhookSecret", operationClassDefault),
+}
+
+// operationFor returns the operation for a request to u. Requests that don't
+// match a known route are named after their method and path.
+func (r *Client) operationFor(baseURL, method string, u *url.URL) operation {
+	path := strings.Trim(u.Path, "/")
+	if base, err := url.Parse(baseURL); err == nil {
+		path = strings.Trim(strings.TrimPrefix(path, strings.Trim(base.Path, "/")), "/")
+	}
+
+	segments := strings.Split(path, "/")
+	for _, route := range routes {
+		if route.method == method && matchSegments(route.pattern, segments) {
+			return route.op
+		}
+	}
+
+	return operation{name: method + " /" + path, class: operationClassDefault}
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i, p := range pattern {
+		if p != "*" && p != segments[i] {
+			return false
+		}
+	}
+	return true
+}
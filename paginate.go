@@ -3,15 +3,244 @@ package replicate
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 )
 
+var ErrPaginationLimit = errors.New("pagination limit reached")
+
+// totalCountHeader is the response header that may carry the total number of
+// items of a list endpoint.
+const totalCountHeader = "X-Total-Count"
+
+type listOptions struct {
+	pageSize int
+	cursor   string
+
+	maxItems int
+	maxPages int
+	prefetch bool
+	dedupe   bool
+	where    []func(any) (bool, error)
+	until    []func(any) (bool, error)
+
+	streamDecode bool
+	onPage       func(PageInfo)
+}
+
+// PageInfo describes a page fetched by a paginator.
+type PageInfo struct {
+	// Number is the position of the page in the sweep, starting at 1.
+	Number int
+
+	// Items is the number of items on the page.
+	Items int
+
+	// Total is the total number of items across all pages, if known.
+	Total *int
+
+	// FetchDuration is how long the client took to fetch the page.
+	FetchDuration time.Duration
+
+	// Previous and Next are the URLs of the adjacent pages, if any.
+	Previous *string
+	Next     *string
+}
+
+// ListOption is a function that modifies the options of a list request or
+// paginator.
+type ListOption func(*listOptions) error
+
+// WithPageSize asks for pages of n items. The API may return fewer, and
+// ignores the option on endpoints with a fixed page size.
+func WithPageSize(n int) ListOption {
+	return func(o *listOptions) error {
+		if n <= 0 {
+			return fmt.Errorf("page size must be positive, got %d", n)
+		}
+		o.pageSize = n
+		return nil
+	}
+}
+
+// WithCursor starts listing at the page identified by cursor, as returned
+// by Page.NextCursor or Page.PreviousCursor.
+func WithCursor(cursor string) ListOption {
+	return func(o *listOptions) error {
+		if cursor == "" {
+			return errors.New("cursor must not be empty")
+		}
+		o.cursor = cursor
+		return nil
+	}
+}
+
+// WithMaxItems limits the number of items a paginator returns. Reaching the
+// limit while more items remain is reported as an error matching
+// ErrPaginationLimit. It has no effect on a single list request.
+func WithMaxItems(n int) ListOption {
+	return func(o *listOptions) error {
+		if n <= 0 {
+			return fmt.Errorf("max items must be positive, got %d", n)
+		}
+		o.maxItems = n
+		return nil
+	}
+}
+
+// WithMaxPages limits the number of pages a paginator fetches. Reaching the
+// limit while more pages remain is reported as an error matching
+// ErrPaginationLimit. It has no effect on a single list request.
+func WithMaxPages(n int) ListOption {
+	return func(o *listOptions) error {
+		if n <= 0 {
+			return fmt.Errorf("max pages must be positive, got %d", n)
+		}
+		o.maxPages = n
+		return nil
+	}
+}
+
+// WithPrefetch makes a paginator fetch the next page in the background while
+// the items of the current page are being consumed. It has no effect on a
+// single list request.
+func WithPrefetch() ListOption {
+	return func(o *listOptions) error {
+		o.prefetch = true
+		return nil
+	}
+}
+
+// WithDeduplication makes a paginator skip items it has already returned.
+//
+// Items can shift across pages when new ones are created during pagination,
+// which would otherwise return an item twice. Items are identified by their
+// ID, or by owner and name for models and deployments, and by slug for
+// collections. It has no effect on a single list request.
+func WithDeduplication() ListOption {
+	return func(o *listOptions) error {
+		o.dedupe = true
+		return nil
+	}
+}
+
+// Where makes a paginator return only the items for which keep returns
+// true. Items that are skipped don't count toward WithMaxItems. It has no
+// effect on a single list request.
+//
+// T must match the type of the listed items.
+func Where[T any](keep func(T) bool) ListOption {
+	return func(o *listOptions) error {
+		o.where = append(o.where, typedPredicate("Where", keep))
+		return nil
+	}
+}
+
+// Until makes a paginator stop at the first item for which stop returns
+// true, without returning that item. Since items are listed newest first,
+// this can end a sweep once items are older than a point in time. It has no
+// effect on a single list request.
+//
+// T must match the type of the listed items.
+func Until[T any](stop func(T) bool) ListOption {
+	return func(o *listOptions) error {
+		o.until = append(o.until, typedPredicate("Until", stop))
+		return nil
+	}
+}
+
+func typedPredicate[T any](name string, fn func(T) bool) func(any) (bool, error) {
+	return func(item any) (bool, error) {
+		v, ok := item.(T)
+		if !ok {
+			var want T
+			return false, fmt.Errorf("%s predicate takes %T, but the items are %T", name, want, item)
+		}
+		return fn(v), nil
+	}
+}
+
+// WithStreamingDecode decodes pages item by item as the response arrives,
+// instead of buffering the whole response first. This reduces peak memory
+// use for pages of predictions with large logs or outputs.
+//
+// Pages decoded this way don't keep their raw JSON, so Page.RawJSON returns
+// nil. The raw JSON of each item is still available.
+func WithStreamingDecode() ListOption {
+	return func(o *listOptions) error {
+		o.streamDecode = true
+		return nil
+	}
+}
+
+// WithPageObserver makes a paginator call observe after each page it
+// fetches, for example to report progress. It has no effect on a single
+// list request.
+func WithPageObserver(observe func(PageInfo)) ListOption {
+	return func(o *listOptions) error {
+		o.onPage = observe
+		return nil
+	}
+}
+
+func newListOptions(opts []ListOption) (*listOptions, error) {
+	options := &listOptions{}
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+// listPage fetches the first page of a list endpoint.
+func listPage[T any](ctx context.Context, client *Client, path string, opts []ListOption) (*Page[T], error) {
+	options, err := newListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return getPage[T](ctx, client, options.path(path), options.streamDecode)
+}
+
+// path returns path with the query parameters for the options.
+func (options *listOptions) path(path string) string {
+	query := url.Values{}
+	if options.cursor != "" {
+		query.Set("cursor", options.cursor)
+	}
+	if options.pageSize > 0 {
+		query.Set("page_size", strconv.Itoa(options.pageSize))
+	}
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
 // Page represents a paginated response from Replicate's API.
 type Page[T any] struct {
+	// Previous and Next are the URLs of the adjacent pages, if any.
 	Previous *string `json:"previous,omitempty"`
 	Next     *string `json:"next,omitempty"`
 	Results  []T     `json:"results"`
 
+	// Total is the total number of items across all pages, when the API
+	// reports it in the body or the X-Total-Count header.
+	Total *int `json:"total,omitempty"`
+
+	// FetchDuration is how long the client took to fetch the page,
+	// including retries. It is zero for pages that weren't fetched by the
+	// client.
+	FetchDuration time.Duration `json:"-"`
+
+	// RequestID is the ID the API assigned to the request for the page.
+	RequestID string `json:"-"`
+
 	rawJSON json.RawMessage `json:"-"`
 }
 
@@ -28,6 +257,160 @@ func (p *Page[T]) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+// NextCursor returns the cursor for the page after p, or an empty string if
+// there is none. Pass it to WithCursor to resume listing from that page,
+// for example after a restart.
+func (p *Page[T]) NextCursor() string {
+	if !p.HasNext() {
+		return ""
+	}
+	return cursorFromURL(*p.Next)
+}
+
+// PreviousCursor returns the cursor for the page before p, or an empty
+// string if there is none.
+func (p *Page[T]) PreviousCursor() string {
+	if !p.HasPrevious() {
+		return ""
+	}
+	return cursorFromURL(*p.Previous)
+}
+
+func cursorFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("cursor")
+}
+
+// HasNext reports whether there is a page after p.
+func (p *Page[T]) HasNext() bool {
+	return p != nil && p.Next != nil && *p.Next != ""
+}
+
+// HasPrevious reports whether there is a page before p.
+func (p *Page[T]) HasPrevious() bool {
+	return p != nil && p.Previous != nil && *p.Previous != ""
+}
+
+// GetNextPage fetches the page after p. It returns nil and no error if there
+// is no next page, including when p is nil.
+func (p *Page[T]) GetNextPage(ctx context.Context, client *Client) (*Page[T], error) {
+	if !p.HasNext() {
+		return nil, nil
+	}
+	return getPage[T](ctx, client, *p.Next, false)
+}
+
+// GetPreviousPage fetches the page before p. It returns nil and no error if
+// there is no previous page, including when p is nil.
+func (p *Page[T]) GetPreviousPage(ctx context.Context, client *Client) (*Page[T], error) {
+	if !p.HasPrevious() {
+		return nil, nil
+	}
+	return getPage[T](ctx, client, *p.Previous, false)
+}
+
+func getPage[T any](ctx context.Context, client *Client, url string, streamDecode bool) (*Page[T], error) {
+	page := &Page[T]{}
+	var out interface{} = page
+	if streamDecode {
+		out = (*pageDecoder[T])(page)
+	}
+
+	md := &CallMetadata{}
+	err := client.fetch(WithCallMetadata(ctx, md), http.MethodGet, url, nil, out)
+	if outer, ok := ctx.Value(callMetadataContextKey{}).(*CallMetadata); ok && outer != nil {
+		*outer = *md
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+
+	page.FetchDuration = md.Duration
+	page.RequestID = md.RequestID
+	if page.Total == nil {
+		if total, err := strconv.Atoi(md.Header.Get(totalCountHeader)); err == nil {
+			page.Total = &total
+		}
+	}
+
+	return page, nil
+}
+
+// pageDecoder decodes a page from a response body one item at a time.
+type pageDecoder[T any] Page[T]
+
+func (p *pageDecoder[T]) decodeBody(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+
+		switch key {
+		case "results":
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			p.Results = []T{}
+			for dec.More() {
+				// Items keep their raw JSON, so decode each one from its own
+				// copy rather than from the decoder's reused buffer.
+				var raw json.RawMessage
+				if err := dec.Decode(&raw); err != nil {
+					return err
+				}
+				var item T
+				if err := json.Unmarshal(raw, &item); err != nil {
+					return err
+				}
+				p.Results = append(p.Results, item)
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return err
+			}
+		case "next":
+			if err := dec.Decode(&p.Next); err != nil {
+				return err
+			}
+		case "previous":
+			if err := dec.Decode(&p.Previous); err != nil {
+				return err
+			}
+		case "total":
+			if err := dec.Decode(&p.Total); err != nil {
+				return err
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != want {
+		return fmt.Errorf("expected %q at offset %d, got %v", want, dec.InputOffset(), token)
+	}
+	return nil
+}
+
 // Paginate takes a Page and the Client request method, and iterates through pages of results.
 func Paginate[T any](ctx context.Context, client *Client, initialPage *Page[T]) (<-chan []T, <-chan error) {
 	resultsChan := make(chan []T)
@@ -56,3 +439,328 @@ func Paginate[T any](ctx context.Context, client *Client, initialPage *Page[T])
 
 	return resultsChan, errChan
 }
+
+// pager fetches the pages of a list endpoint in order, following next
+// cursors.
+type pager[T any] struct {
+	client       *Client
+	path         string
+	done         bool
+	streamDecode bool
+}
+
+func newPager[T any](client *Client, path string, streamDecode bool) *pager[T] {
+	return &pager[T]{client: client, path: path, done: path == "", streamDecode: streamDecode}
+}
+
+// fetch returns the next page, or nil once there are no more pages.
+func (p *pager[T]) fetch(ctx context.Context) (*Page[T], error) {
+	if p.done {
+		return nil, nil
+	}
+
+	page, err := getPage[T](ctx, p.client, p.path, p.streamDecode)
+	if err != nil {
+		p.done = true
+		return nil, err
+	}
+
+	if !page.HasNext() {
+		p.done = true
+	} else {
+		p.path = *page.Next
+	}
+
+	return page, nil
+}
+
+// sweep yields the items of successive pages one at a time, applying the
+// pagination options.
+type sweep[T any] struct {
+	pager   *pager[T]
+	options *listOptions
+	pending *Page[T]
+	items   []T
+	yielded int
+	pages   int
+	seen    map[string]struct{}
+	stopped bool
+
+	// prefetched receives the next page when it is being fetched in the
+	// background. Only the prefetching goroutine uses the pager until then.
+	prefetched     chan pageResult[T]
+	cancelPrefetch context.CancelFunc
+}
+
+type pageResult[T any] struct {
+	page *Page[T]
+	err  error
+}
+
+// newSweep returns a sweep that starts with initialPage, if not nil, and
+// continues from path.
+func newSweep[T any](client *Client, options *listOptions, initialPage *Page[T], path string) *sweep[T] {
+	return &sweep[T]{
+		pager:   newPager[T](client, path, options.streamDecode),
+		options: options,
+		pending: initialPage,
+	}
+}
+
+// next returns the next item. It returns false once there are no more items.
+func (s *sweep[T]) next(ctx context.Context) (T, bool, error) {
+	var zero T
+	for {
+		if s.stopped {
+			return zero, false, nil
+		}
+
+		for len(s.items) == 0 {
+			page, err := s.nextPage(ctx)
+			if err != nil || page == nil {
+				return zero, false, err
+			}
+			s.items = page.Results
+		}
+
+		if s.duplicate(s.items[0]) {
+			s.items = s.items[1:]
+			continue
+		}
+
+		stop, err := matchAny(s.options.until, s.items[0])
+		if err != nil {
+			return zero, false, err
+		}
+		if stop {
+			s.stopped = true
+			s.stop()
+			continue
+		}
+
+		keep, err := matchAll(s.options.where, s.items[0])
+		if err != nil {
+			return zero, false, err
+		}
+		if !keep {
+			s.items = s.items[1:]
+			continue
+		}
+
+		if limit := s.options.maxItems; limit > 0 && s.yielded >= limit {
+			return zero, false, fmt.Errorf("%w: more than %d items", ErrPaginationLimit, limit)
+		}
+
+		item := s.items[0]
+		s.items = s.items[1:]
+		s.yielded++
+		return item, true, nil
+	}
+}
+
+// duplicate reports whether item was already returned, if deduplication is
+// enabled, and otherwise remembers it.
+func (s *sweep[T]) duplicate(item T) bool {
+	if !s.options.dedupe {
+		return false
+	}
+	key, ok := itemKey(item)
+	if !ok {
+		return false
+	}
+	if s.seen == nil {
+		s.seen = make(map[string]struct{})
+	}
+	if _, ok := s.seen[key]; ok {
+		return true
+	}
+	s.seen[key] = struct{}{}
+	return false
+}
+
+func matchAny(predicates []func(any) (bool, error), item any) (bool, error) {
+	for _, match := range predicates {
+		if ok, err := match(item); err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func matchAll(predicates []func(any) (bool, error), item any) (bool, error) {
+	for _, match := range predicates {
+		if ok, err := match(item); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// itemKey returns the identity of a list item.
+func itemKey(item any) (string, bool) {
+	switch v := item.(type) {
+	case Prediction:
+		return v.ID, v.ID != ""
+	case Training:
+		return v.ID, v.ID != ""
+	case ModelVersion:
+		return v.ID, v.ID != ""
+	case File:
+		return v.ID, v.ID != ""
+	case Model:
+		return v.Owner + "/" + v.Name, v.Name != ""
+	case Deployment:
+		return v.Owner + "/" + v.Name, v.Name != ""
+	case Collection:
+		return v.Slug, v.Slug != ""
+	}
+	return "", false
+}
+
+func (s *sweep[T]) nextPage(ctx context.Context) (*Page[T], error) {
+	if page := s.pending; page != nil {
+		s.pending = nil
+		s.pages++
+		s.observe(page)
+		if page.HasNext() {
+			s.pager = newPager[T](s.pager.client, *page.Next, s.options.streamDecode)
+		}
+		s.startPrefetch(ctx)
+		return page, nil
+	}
+
+	var page *Page[T]
+	var err error
+	if s.prefetched != nil {
+		result := <-s.prefetched
+		s.prefetched = nil
+		s.cancelPrefetch()
+		page, err = result.page, result.err
+	} else {
+		if s.pager.done {
+			return nil, nil
+		}
+		if s.limitReached() {
+			return nil, fmt.Errorf("%w: more than %d pages", ErrPaginationLimit, s.options.maxPages)
+		}
+		page, err = s.pager.fetch(ctx)
+	}
+	if page != nil {
+		s.pages++
+		s.observe(page)
+		s.startPrefetch(ctx)
+	}
+	return page, err
+}
+
+func (s *sweep[T]) observe(page *Page[T]) {
+	if s.options.onPage == nil {
+		return
+	}
+	s.options.onPage(PageInfo{
+		Number:        s.pages,
+		Items:         len(page.Results),
+		Total:         page.Total,
+		FetchDuration: page.FetchDuration,
+		Previous:      page.Previous,
+		Next:          page.Next,
+	})
+}
+
+func (s *sweep[T]) limitReached() bool {
+	return s.options.maxPages > 0 && s.pages >= s.options.maxPages
+}
+
+// startPrefetch starts fetching the page after the current one, if
+// prefetching is enabled.
+func (s *sweep[T]) startPrefetch(ctx context.Context) {
+	if !s.options.prefetch || s.pager.done || s.limitReached() {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancelPrefetch = cancel
+	s.prefetched = make(chan pageResult[T], 1)
+	go func(ch chan<- pageResult[T]) {
+		page, err := s.pager.fetch(ctx)
+		ch <- pageResult[T]{page: page, err: err}
+	}(s.prefetched)
+}
+
+// stop cancels a page fetch in progress in the background.
+func (s *sweep[T]) stop() {
+	if s.cancelPrefetch != nil {
+		s.cancelPrefetch()
+	}
+}
+
+// PaginateItems takes a Page and streams its items, followed by the items of
+// every subsequent page.
+//
+// Pages are fetched as the items are received, so a slow consumer doesn't
+// cause pages to pile up in memory. If fetching a page fails or ctx is done,
+// the error is sent on the error channel and both channels are closed.
+func PaginateItems[T any](ctx context.Context, client *Client, initialPage *Page[T], opts ...ListOption) (<-chan T, <-chan error) {
+	itemsChan := make(chan T)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(itemsChan)
+		defer close(errChan)
+
+		options, err := newListOptions(opts)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		s := newSweep(client, options, initialPage, "")
+		defer s.stop()
+		for {
+			item, ok, err := s.next(ctx)
+			if err != nil {
+				errChan <- err
+				return
+			}
+			if !ok {
+				return
+			}
+
+			select {
+			case itemsChan <- item:
+			case <-ctx.Done():
+				errChan <- classifyError(ctx.Err())
+				return
+			}
+		}
+	}()
+
+	return itemsChan, errChan
+}
+
+// AllPages takes a Page and returns its items together with the items of
+// every subsequent page.
+//
+// Use WithMaxItems or WithMaxPages to guard against unexpectedly large
+// result sets. If a limit is reached, AllPages returns the items collected so
+// far with an error matching ErrPaginationLimit.
+func AllPages[T any](ctx context.Context, client *Client, initialPage *Page[T], opts ...ListOption) ([]T, error) {
+	options, err := newListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+	s := newSweep(client, options, initialPage, "")
+	defer s.stop()
+	for {
+		item, ok, err := s.next(ctx)
+		if err != nil {
+			return items, err
+		}
+		if !ok {
+			return items, nil
+		}
+		items = append(items, item)
+	}
+}
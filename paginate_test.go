@@ -0,0 +1,437 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+// newPaginatedServer serves predictions in pages of pageSize, following a
+// numeric cursor.
+func newPaginatedServer(t *testing.T, ids []string, pageSize int) *httptest.Server {
+	t.Helper()
+	return newObservedPaginatedServer(t, ids, pageSize, nil)
+}
+
+// newObservedPaginatedServer is like newPaginatedServer, and calls observe
+// with each request.
+func newObservedPaginatedServer(t *testing.T, ids []string, pageSize int, observe func(*http.Request)) *httptest.Server {
+	t.Helper()
+
+	var mockServer *httptest.Server
+	mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if observe != nil {
+			observe(r)
+		}
+
+		start := 0
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			require.NoError(t, json.Unmarshal([]byte(cursor), &start))
+		}
+		size := pageSize
+		if n := r.URL.Query().Get("page_size"); n != "" {
+			require.NoError(t, json.Unmarshal([]byte(n), &size))
+		}
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		page := replicate.Page[replicate.Prediction]{Results: []replicate.Prediction{}}
+		for _, id := range ids[start:end] {
+			page.Results = append(page.Results, replicate.Prediction{ID: id})
+		}
+		if end < len(ids) {
+			next, _ := json.Marshal(end)
+			nextURL := mockServer.URL + r.URL.Path + "?cursor=" + string(next)
+			page.Next = &nextURL
+		}
+
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(mockServer.Close)
+
+	return mockServer
+}
+
+func TestPaginateItems(t *testing.T) {
+	mockServer := newPaginatedServer(t, []string{"a", "b", "c", "d", "e"}, 2)
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	initialPage, err := client.ListPredictions(ctx)
+	require.NoError(t, err)
+
+	itemsChan, errChan := replicate.PaginateItems(ctx, client, initialPage)
+
+	var ids []string
+	for prediction := range itemsChan {
+		ids = append(ids, prediction.ID)
+	}
+	require.NoError(t, <-errChan)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, ids)
+}
+
+func TestPaginateItemsCanceled(t *testing.T) {
+	mockServer := newPaginatedServer(t, []string{"a", "b", "c", "d", "e"}, 2)
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	initialPage, err := client.ListPredictions(ctx)
+	require.NoError(t, err)
+
+	itemsChan, errChan := replicate.PaginateItems(ctx, client, initialPage)
+	first := <-itemsChan
+	assert.Equal(t, "a", first.ID)
+	cancel()
+
+	for range itemsChan {
+	}
+	assert.ErrorIs(t, <-errChan, replicate.ErrCanceled)
+}
+
+func TestGetNextAndPreviousPage(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		first, second := "/predictions", "/predictions?cursor=2"
+		page := replicate.Page[replicate.Prediction]{}
+		if r.URL.Query().Get("cursor") == "2" {
+			page.Previous = &first
+			page.Results = []replicate.Prediction{{ID: "b"}}
+		} else {
+			page.Next = &second
+			page.Results = []replicate.Prediction{{ID: "a"}}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	page, err := client.ListPredictions(ctx)
+	require.NoError(t, err)
+	assert.False(t, page.HasPrevious())
+
+	previous, err := page.GetPreviousPage(ctx, client)
+	require.NoError(t, err)
+	assert.Nil(t, previous)
+
+	next, err := page.GetNextPage(ctx, client)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "b", next.Results[0].ID)
+	assert.False(t, next.HasNext())
+
+	last, err := next.GetNextPage(ctx, client)
+	require.NoError(t, err)
+	assert.Nil(t, last)
+
+	previous, err = next.GetPreviousPage(ctx, client)
+	require.NoError(t, err)
+	assert.Equal(t, "a", previous.Results[0].ID)
+
+	var nilPage *replicate.Page[replicate.Prediction]
+	next, err = nilPage.GetNextPage(ctx, client)
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestListWithPageSizeAndCursor(t *testing.T) {
+	mockServer := newPaginatedServer(t, []string{"a", "b", "c", "d", "e"}, 10)
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	page, err := client.ListPredictions(ctx, replicate.WithPageSize(2))
+	require.NoError(t, err)
+	require.Len(t, page.Results, 2)
+
+	cursor := page.NextCursor()
+	assert.Equal(t, "2", cursor)
+	assert.Equal(t, "", page.PreviousCursor())
+
+	// Resume from the saved cursor, as after a restart.
+	page, err = client.ListPredictions(ctx, replicate.WithCursor(cursor), replicate.WithPageSize(2))
+	require.NoError(t, err)
+	require.Len(t, page.Results, 2)
+	assert.Equal(t, "c", page.Results[0].ID)
+
+	_, err = client.ListPredictions(ctx, replicate.WithPageSize(0))
+	assert.ErrorContains(t, err, "page size must be positive")
+}
+
+func TestAllPages(t *testing.T) {
+	mockServer := newPaginatedServer(t, []string{"a", "b", "c", "d", "e"}, 2)
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	initialPage, err := client.ListPredictions(ctx)
+	require.NoError(t, err)
+
+	ids := func(predictions []replicate.Prediction) []string {
+		var ids []string
+		for _, p := range predictions {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	predictions, err := replicate.AllPages(ctx, client, initialPage)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, ids(predictions))
+
+	predictions, err = replicate.AllPages(ctx, client, initialPage, replicate.WithMaxItems(5))
+	require.NoError(t, err)
+	assert.Len(t, predictions, 5)
+
+	predictions, err = replicate.AllPages(ctx, client, initialPage, replicate.WithMaxItems(3))
+	assert.ErrorIs(t, err, replicate.ErrPaginationLimit)
+	assert.Equal(t, []string{"a", "b", "c"}, ids(predictions))
+
+	predictions, err = replicate.AllPages(ctx, client, initialPage, replicate.WithMaxPages(2))
+	assert.ErrorIs(t, err, replicate.ErrPaginationLimit)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, ids(predictions))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = replicate.AllPages(canceled, client, initialPage)
+	assert.ErrorIs(t, err, replicate.ErrCanceled)
+}
+
+func TestPaginateItemsWithPrefetch(t *testing.T) {
+	requested := make(chan string, 10)
+	mockServer := newObservedPaginatedServer(t, []string{"a", "b", "c", "d"}, 2, func(r *http.Request) {
+		requested <- r.URL.Query().Get("cursor")
+	})
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	initialPage, err := client.ListPredictions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "", <-requested)
+
+	itemsChan, errChan := replicate.PaginateItems(ctx, client, initialPage, replicate.WithPrefetch())
+	first := <-itemsChan
+	assert.Equal(t, "a", first.ID)
+
+	// The second page is requested before the consumer has finished the first.
+	select {
+	case cursor := <-requested:
+		assert.Equal(t, "2", cursor)
+	case <-time.After(time.Second):
+		t.Fatal("next page was not prefetched")
+	}
+
+	ids := []string{first.ID}
+	for prediction := range itemsChan {
+		ids = append(ids, prediction.ID)
+	}
+	require.NoError(t, <-errChan)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, ids)
+}
+
+func TestAllPagesWithDeduplication(t *testing.T) {
+	// "b" shifts onto the second page, as when a prediction is created
+	// between requests.
+	mockServer := newPaginatedServer(t, []string{"a", "b", "b", "c"}, 2)
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	initialPage, err := client.ListPredictions(ctx)
+	require.NoError(t, err)
+
+	predictions, err := replicate.AllPages(ctx, client, initialPage)
+	require.NoError(t, err)
+	assert.Len(t, predictions, 4)
+
+	predictions, err = replicate.AllPages(ctx, client, initialPage, replicate.WithDeduplication(), replicate.WithMaxItems(3))
+	require.NoError(t, err)
+	require.Len(t, predictions, 3)
+	assert.Equal(t, "c", predictions[2].ID)
+}
+
+func TestAllPagesWhereUntil(t *testing.T) {
+	mockServer := newPaginatedServer(t, []string{"a1", "b1", "a2", "b2", "stop", "a3"}, 2)
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	initialPage, err := client.ListPredictions(ctx)
+	require.NoError(t, err)
+
+	predictions, err := replicate.AllPages(ctx, client, initialPage,
+		replicate.Where(func(p replicate.Prediction) bool { return p.ID[0] == 'a' }),
+		replicate.Until(func(p replicate.Prediction) bool { return p.ID == "stop" }),
+	)
+	require.NoError(t, err)
+	require.Len(t, predictions, 2)
+	assert.Equal(t, "a1", predictions[0].ID)
+	assert.Equal(t, "a2", predictions[1].ID)
+
+	_, err = replicate.AllPages(ctx, client, initialPage,
+		replicate.Where(func(m replicate.Model) bool { return true }),
+	)
+	assert.ErrorContains(t, err, "Where predicate takes replicate.Model, but the items are replicate.Prediction")
+}
+
+func TestListWithStreamingDecode(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "2" {
+			w.Write([]byte(`{"next": null, "previous": "/predictions", "results": [{"id": "c", "logs": "done"}]}`))
+			return
+		}
+		w.Write([]byte(`{"previous": null, "extra": {"ignored": [1, 2]}, "results": [{"id": "a"}, {"id": "b", "status": "succeeded"}], "next": "/predictions?cursor=2"}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	page, err := client.ListPredictions(ctx, replicate.WithStreamingDecode())
+	require.NoError(t, err)
+	require.Len(t, page.Results, 2)
+	assert.Equal(t, replicate.Succeeded, page.Results[1].Status)
+	assert.JSONEq(t, `{"id": "b", "status": "succeeded"}`, string(page.Results[1].RawJSON()))
+	assert.Nil(t, page.Previous)
+	assert.Equal(t, "2", page.NextCursor())
+	assert.Nil(t, page.RawJSON())
+
+	predictions, err := replicate.AllPages(ctx, client, page, replicate.WithStreamingDecode())
+	require.NoError(t, err)
+	require.Len(t, predictions, 3)
+	require.NotNil(t, predictions[2].Logs)
+	assert.Equal(t, "done", *predictions[2].Logs)
+}
+
+func TestListWithStreamingDecodeError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"results": [{"id": "a"}, {"id": 42}]}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	_, err = client.ListPredictions(context.Background(), replicate.WithStreamingDecode())
+	var decodeErr *replicate.DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, http.StatusOK, decodeErr.StatusCode)
+}
+
+func TestPageMetadata(t *testing.T) {
+	mockServer := newObservedPaginatedServer(t, []string{"a", "b", "c"}, 2, func(r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	})
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	page, err := client.ListPredictions(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, page.FetchDuration, 5*time.Millisecond)
+	assert.Nil(t, page.Total)
+	require.NotNil(t, page.Next)
+	assert.Equal(t, mockServer.URL+"/predictions?cursor=2", *page.Next)
+
+	var pages []replicate.PageInfo
+	_, err = replicate.AllPages(ctx, client, page, replicate.WithPageObserver(func(info replicate.PageInfo) {
+		pages = append(pages, info)
+	}))
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, 1, pages[0].Number)
+	assert.Equal(t, 2, pages[0].Items)
+	assert.Equal(t, 2, pages[1].Number)
+	assert.Equal(t, 1, pages[1].Items)
+	assert.Nil(t, pages[1].Next)
+	assert.Positive(t, pages[1].FetchDuration)
+}
+
+func TestPageTotal(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/files" {
+			w.Header().Set("X-Total-Count", "12")
+			w.Write([]byte(`{"results": []}`))
+			return
+		}
+		w.Write([]byte(`{"results": [], "total": 7}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	files, err := client.ListFiles(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, files.Total)
+	assert.Equal(t, 12, *files.Total)
+
+	predictions, err := client.ListPredictions(ctx, replicate.WithStreamingDecode())
+	require.NoError(t, err)
+	require.NotNil(t, predictions.Total)
+	assert.Equal(t, 7, *predictions.Total)
+}
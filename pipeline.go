@@ -0,0 +1,220 @@
+package replicate
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StageOption is a function that modifies a pipeline stage.
+type StageOption func(*stageOptions)
+
+type stageOptions struct {
+	retries int
+	onError func(ctx context.Context, err error, input PredictionOutput) (PredictionOutput, error)
+}
+
+// WithStageRetries runs a model stage up to n more times if its prediction
+// fails, or the API is rate limiting requests or unavailable.
+func WithStageRetries(n int) StageOption {
+	return func(o *stageOptions) {
+		o.retries = n
+	}
+}
+
+// WithStageErrorHandler sets a function called when a stage fails, after
+// any retries, with the error and the stage's input. It can recover by
+// returning an output for the stage, such as a default value, or return an
+// error to stop the pipeline.
+func WithStageErrorHandler(handle func(ctx context.Context, err error, input PredictionOutput) (PredictionOutput, error)) StageOption {
+	return func(o *stageOptions) {
+		o.onError = handle
+	}
+}
+
+type pipelineStage struct {
+	name string
+
+	// Model stages set identifier and input; transform stages set
+	// transform.
+	identifier string
+	input      func(prev PredictionOutput) (PredictionInput, error)
+	transform  func(ctx context.Context, prev PredictionOutput) (PredictionOutput, error)
+
+	options stageOptions
+}
+
+// Pipeline chains models and transforms, feeding the output of each stage
+// to the next, such as speech recognition followed by a language model and
+// text to speech.
+//
+// Build a pipeline with Client.NewPipeline, Then, and Transform, and run it
+// with Run or RunStream. A pipeline can be run any number of times, but
+// shouldn't be changed while it's running.
+type Pipeline struct {
+	client  *Client
+	options []WaitOption
+	stages  []pipelineStage
+}
+
+// StageResult is the outcome of a pipeline stage.
+type StageResult struct {
+	// Stage is the name of the stage, and Index its position.
+	Stage string
+	Index int
+
+	// Output is the output of the stage, which is the input of the next.
+	Output PredictionOutput
+
+	// Prediction is the prediction run by a model stage. It's nil for
+	// transform stages, and for stages recovered by an error handler.
+	Prediction *Prediction
+
+	// Duration is how long the stage took, including retries.
+	Duration time.Duration
+}
+
+// PipelineResult is the outcome of a pipeline run.
+type PipelineResult struct {
+	// Output is the output of the last stage.
+	Output PredictionOutput
+
+	// Stages holds the result of each stage, in order.
+	Stages []StageResult
+}
+
+// PipelineError is returned when a pipeline stage fails.
+type PipelineError struct {
+	// Stage is the name of the failed stage, and Index its position.
+	Stage string
+	Index int
+
+	// Err is the error the stage failed with.
+	Err error
+}
+
+func (e *PipelineError) Error() string {
+	return fmt.Sprintf("pipeline stage %d (%s) failed: %v", e.Index, e.Stage, e.Err)
+}
+
+func (e *PipelineError) Unwrap() error {
+	return e.Err
+}
+
+// NewPipeline returns an empty pipeline that runs its model stages with r.
+// opts configure how each stage's prediction is waited for, as for Wait.
+func (r *Client) NewPipeline(opts ...WaitOption) *Pipeline {
+	return &Pipeline{client: r, options: opts}
+}
+
+// Then adds a stage that runs a model. input builds the model's input from
+// the output of the previous stage, or from the pipeline's input for the
+// first stage.
+func (p *Pipeline) Then(name, identifier string, input func(prev PredictionOutput) (PredictionInput, error), opts ...StageOption) *Pipeline {
+	stage := pipelineStage{name: name, identifier: identifier, input: input}
+	for _, opt := range opts {
+		opt(&stage.options)
+	}
+	p.stages = append(p.stages, stage)
+	return p
+}
+
+// Transform adds a stage that runs fn on the output of the previous stage,
+// such as extracting a field or uploading a file.
+func (p *Pipeline) Transform(name string, fn func(ctx context.Context, prev PredictionOutput) (PredictionOutput, error), opts ...StageOption) *Pipeline {
+	stage := pipelineStage{name: name, transform: fn}
+	for _, opt := range opts {
+		opt(&stage.options)
+	}
+	p.stages = append(p.stages, stage)
+	return p
+}
+
+// Run runs the stages in order, starting with input, and returns the output
+// of the last stage. If a stage fails, the error is a *PipelineError, and the
+// result holds the stages that completed.
+func (p *Pipeline) Run(ctx context.Context, input PredictionOutput) (*PipelineResult, error) {
+	result := &PipelineResult{}
+	err := p.run(ctx, input, func(stage StageResult) {
+		result.Stages = append(result.Stages, stage)
+		result.Output = stage.Output
+	})
+	return result, err
+}
+
+// RunStream runs the pipeline like Run, sending the result of each stage as
+// it completes. Both channels are closed when the pipeline finishes; the
+// error channel receives the error of a failed run first.
+func (p *Pipeline) RunStream(ctx context.Context, input PredictionOutput) (<-chan StageResult, <-chan error) {
+	stageChan := make(chan StageResult)
+	errChan := make(chan error, 1)
+
+	ctx, done := p.client.background(ctx)
+	go func() {
+		defer done()
+		defer close(stageChan)
+		defer close(errChan)
+
+		err := p.run(ctx, input, func(stage StageResult) {
+			select {
+			case stageChan <- stage:
+			case <-ctx.Done():
+			}
+		})
+		if err != nil {
+			errChan <- err
+		}
+	}()
+
+	return stageChan, errChan
+}
+
+func (p *Pipeline) run(ctx context.Context, input PredictionOutput, emit func(StageResult)) error {
+	for i, stage := range p.stages {
+		start := time.Now()
+		output, prediction, err := p.runStage(ctx, stage, input)
+		if err != nil && stage.options.onError != nil && ctx.Err() == nil {
+			output, err = stage.options.onError(ctx, err, input)
+			prediction = nil
+		}
+		if err != nil {
+			return &PipelineError{Stage: stage.name, Index: i, Err: err}
+		}
+
+		emit(StageResult{
+			Stage:      stage.name,
+			Index:      i,
+			Output:     output,
+			Prediction: prediction,
+			Duration:   time.Since(start),
+		})
+		input = output
+	}
+	return nil
+}
+
+func (p *Pipeline) runStage(ctx context.Context, stage pipelineStage, input PredictionOutput) (PredictionOutput, *Prediction, error) {
+	if stage.transform != nil {
+		output, err := stage.transform(ctx, input)
+		return output, nil, err
+	}
+
+	modelInput, err := stage.input(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build input: %w", err)
+	}
+
+	r := p.client
+	for attempt := 0; ; attempt++ {
+		prediction, err := r.runToCompletion(ctx, stage.identifier, modelInput, p.options...)
+		if err == nil {
+			return prediction.Output, prediction, nil
+		}
+		if attempt >= stage.options.retries || !isTransientRunError(err) {
+			return nil, nil, err
+		}
+		if sleepErr := r.sleep(ctx, defaultBackoff.NextDelay(attempt)); sleepErr != nil {
+			return nil, nil, err
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestPipeline(t *testing.T) {
+	var whisperAttempts atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			model := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/models/"), "/predictions")
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			id := strings.ReplaceAll(model, "/", "-")
+			if model == "meta/llama" {
+				assert.Equal(t, "hello", body.Input["prompt"])
+			}
+			if model == "openai/whisper" {
+				id = fmt.Sprintf("%s-%d", id, whisperAttempts.Add(1))
+			}
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Starting})
+		case http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			prediction := &replicate.Prediction{ID: id, Status: replicate.Succeeded}
+			switch id {
+			case "openai-whisper-1":
+				prediction.Status = replicate.Failed
+				prediction.Error = "CUDA out of memory"
+			case "openai-whisper-2":
+				prediction.Output = map[string]any{"transcription": "hello"}
+			case "meta-llama":
+				prediction.Output = []any{"HELLO", " THERE"}
+			case "acme-tts":
+				prediction.Status = replicate.Failed
+				prediction.Error = "voice unavailable"
+			}
+			json.NewEncoder(w).Encode(prediction)
+		}
+	}))
+	defer mockServer.Close()
+
+	clock := replicatetest.NewFakeClock(time.Now())
+	clock.AutoAdvance(true)
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithClock(clock),
+	)
+	require.NoError(t, err)
+
+	pipeline := client.NewPipeline().
+		Then("transcribe", "openai/whisper", func(prev replicate.PredictionOutput) (replicate.PredictionInput, error) {
+			return replicate.PredictionInput{"audio": prev}, nil
+		}, replicate.WithStageRetries(1)).
+		Transform("extract", func(_ context.Context, prev replicate.PredictionOutput) (replicate.PredictionOutput, error) {
+			return prev.(map[string]any)["transcription"], nil
+		}).
+		Then("answer", "meta/llama", func(prev replicate.PredictionOutput) (replicate.PredictionInput, error) {
+			return replicate.PredictionInput{"prompt": prev}, nil
+		}).
+		Transform("join", func(_ context.Context, prev replicate.PredictionOutput) (replicate.PredictionOutput, error) {
+			var b strings.Builder
+			for _, token := range prev.([]any) {
+				b.WriteString(token.(string))
+			}
+			return b.String(), nil
+		})
+
+	ctx := context.Background()
+	result, err := pipeline.Run(ctx, "https://example.com/audio.wav")
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO THERE", result.Output)
+	require.Len(t, result.Stages, 4)
+	assert.Equal(t, "openai-whisper-2", result.Stages[0].Prediction.ID)
+	assert.Equal(t, "hello", result.Stages[1].Output)
+	assert.Nil(t, result.Stages[1].Prediction)
+	assert.Equal(t, "meta-llama", result.Stages[2].Prediction.ID)
+
+	speak := func(prev replicate.PredictionOutput) (replicate.PredictionInput, error) {
+		return replicate.PredictionInput{"text": prev}, nil
+	}
+
+	t.Run("stage error", func(t *testing.T) {
+		failing := client.NewPipeline().Then("speak", "acme/tts", speak)
+		result, err := failing.Run(ctx, "hi")
+		var pipelineErr *replicate.PipelineError
+		require.ErrorAs(t, err, &pipelineErr)
+		assert.Equal(t, "speak", pipelineErr.Stage)
+		assert.Equal(t, 0, pipelineErr.Index)
+		var modelErr *replicate.ModelError
+		assert.ErrorAs(t, err, &modelErr)
+		assert.Empty(t, result.Stages)
+	})
+
+	t.Run("error handler", func(t *testing.T) {
+		recovered := client.NewPipeline().Then("speak", "acme/tts", speak,
+			replicate.WithStageErrorHandler(func(_ context.Context, err error, input replicate.PredictionOutput) (replicate.PredictionOutput, error) {
+				assert.ErrorContains(t, err, "voice unavailable")
+				return "silence for " + input.(string), nil
+			}))
+		result, err := recovered.Run(ctx, "hi")
+		require.NoError(t, err)
+		assert.Equal(t, "silence for hi", result.Output)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		streamed := client.NewPipeline().
+			Transform("upper", func(_ context.Context, prev replicate.PredictionOutput) (replicate.PredictionOutput, error) {
+				return strings.ToUpper(prev.(string)), nil
+			}).
+			Transform("fail", func(context.Context, replicate.PredictionOutput) (replicate.PredictionOutput, error) {
+				return nil, errors.New("no more")
+			})
+		stages, errs := streamed.RunStream(ctx, "hi")
+
+		var names []string
+		for stage := range stages {
+			names = append(names, stage.Stage)
+			assert.Equal(t, "HI", stage.Output)
+		}
+		assert.Equal(t, []string{"upper"}, names)
+		assert.ErrorContains(t, <-errs, "pipeline stage 1 (fail) failed: no more")
+	})
+}
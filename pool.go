@@ -0,0 +1,160 @@
+package replicate
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// TenantTokens returns the token provider for a tenant's Replicate account.
+type TenantTokens func(ctx context.Context, tenant string) (TokenProvider, error)
+
+// ClientPool holds a client for each tenant, for platforms that run
+// predictions on behalf of many customers' Replicate accounts.
+//
+// The clients share the pool's options, so they share the HTTP client and
+// its transport, even when transport options like WithMaxIdleConnsPerHost
+// are set, and the limit set with WithMaxConcurrentRequests applies
+// to the pool as a whole. Each client uses its tenant's token and tracks its
+// own rate limit, since the API limits each account separately.
+//
+// The pool keeps at most maxClients clients, evicting the least recently
+// used one when it's full.
+type ClientPool struct {
+	tokens     TenantTokens
+	maxClients int
+	opts       []ClientOption
+
+	// inflight is shared by the clients when the concurrency is limited.
+	inflight chan struct{}
+
+	// httpClient is the HTTP client configured from the options, shared by
+	// the clients so they reuse connections.
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	lru     *list.List
+	clients map[string]*list.Element
+}
+
+type pooledClient struct {
+	tenant string
+	client *Client
+}
+
+// NewClientPool returns a pool that creates clients with opts and the
+// tokens returned by tokens. The options are validated immediately.
+func NewClientPool(tokens TenantTokens, maxClients int, opts ...ClientOption) (*ClientPool, error) {
+	if tokens == nil {
+		return nil, errors.New("tenant tokens must not be nil")
+	}
+	if maxClients <= 0 {
+		return nil, fmt.Errorf("max clients must be positive, got %d", maxClients)
+	}
+
+	p := &ClientPool{
+		tokens:     tokens,
+		maxClients: maxClients,
+		opts:       append([]ClientOption(nil), opts...),
+		lru:        list.New(),
+		clients:    make(map[string]*list.Element),
+	}
+
+	// Check the options with a placeholder token.
+	probe, err := p.newClient(StaticToken("pool"))
+	if err != nil {
+		return nil, err
+	}
+	p.httpClient = probe.options.httpClient
+	if probe.options.maxConcurrent > 0 {
+		p.inflight = make(chan struct{}, probe.options.maxConcurrent)
+	}
+
+	return p, nil
+}
+
+// Client returns the client for tenant, creating it if the pool doesn't
+// have one.
+func (p *ClientPool) Client(ctx context.Context, tenant string) (*Client, error) {
+	if client := p.lookup(tenant); client != nil {
+		return client, nil
+	}
+
+	provider, err := p.tokens(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token for tenant %q: %w", tenant, err)
+	}
+	client, err := p.newClient(provider)
+	if err != nil {
+		return nil, err
+	}
+	if p.inflight != nil {
+		client.state.inflight = p.inflight
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Another call may have created a client for the tenant meanwhile.
+	if e, ok := p.clients[tenant]; ok {
+		p.lru.MoveToFront(e)
+		return e.Value.(*pooledClient).client, nil
+	}
+
+	p.clients[tenant] = p.lru.PushFront(&pooledClient{tenant: tenant, client: client})
+	for p.lru.Len() > p.maxClients {
+		oldest := p.lru.Back()
+		p.lru.Remove(oldest)
+		delete(p.clients, oldest.Value.(*pooledClient).tenant)
+	}
+
+	return client, nil
+}
+
+// Evict removes the client for tenant, such as when the tenant's token is
+// revoked. The next call to Client creates a new one.
+func (p *ClientPool) Evict(tenant string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.clients[tenant]; ok {
+		p.lru.Remove(e)
+		delete(p.clients, tenant)
+	}
+}
+
+// Len returns the number of clients in the pool.
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lru.Len()
+}
+
+func (p *ClientPool) lookup(tenant string) *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.clients[tenant]
+	if !ok {
+		return nil
+	}
+	p.lru.MoveToFront(e)
+	return e.Value.(*pooledClient).client
+}
+
+func (p *ClientPool) newClient(provider TokenProvider) (*Client, error) {
+	opts := append(append([]ClientOption(nil), p.opts...), WithTokenProvider(provider))
+	if p.httpClient != nil {
+		// Transport options are already applied to the shared client, so
+		// they mustn't give this client a copy of the transport.
+		opts = append(opts, func(o *clientOptions) error {
+			o.httpClient = p.httpClient
+			o.transport = transportOptions{}
+			return nil
+		})
+	}
+	return NewClient(opts...)
+}
@@ -0,0 +1,139 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestClientPool(t *testing.T) {
+	var inflight, peak int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		username := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer r8_")
+		json.NewEncoder(w).Encode(&replicate.Account{Type: "user", Username: username})
+	}))
+	defer mockServer.Close()
+
+	var lookups sync.Map
+	tokens := func(_ context.Context, tenant string) (replicate.TokenProvider, error) {
+		if tenant == "revoked" {
+			return nil, errors.New("no token")
+		}
+		count, _ := lookups.LoadOrStore(tenant, new(int32))
+		atomic.AddInt32(count.(*int32), 1)
+		return replicate.StaticToken("r8_" + tenant), nil
+	}
+
+	pool, err := replicate.NewClientPool(tokens, 2,
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithMaxConcurrentRequests(1),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for _, tenant := range []string{"alice", "bob", "alice", "bob"} {
+		wg.Add(1)
+		go func(tenant string) {
+			defer wg.Done()
+			client, err := pool.Client(ctx, tenant)
+			require.NoError(t, err)
+			account, err := client.GetCurrentAccount(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tenant, account.Username)
+		}(tenant)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&peak), "the concurrency limit is shared")
+	assert.Equal(t, 2, pool.Len())
+
+	// Using alice makes bob the least recently used.
+	alice, err := pool.Client(ctx, "alice")
+	require.NoError(t, err)
+	_, err = pool.Client(ctx, "carol")
+	require.NoError(t, err)
+	assert.Equal(t, 2, pool.Len())
+
+	again, err := pool.Client(ctx, "alice")
+	require.NoError(t, err)
+	assert.Same(t, alice, again)
+
+	_, err = pool.Client(ctx, "bob")
+	require.NoError(t, err)
+	count, _ := lookups.Load("bob")
+	assert.GreaterOrEqual(t, atomic.LoadInt32(count.(*int32)), int32(2), "bob was evicted and recreated")
+
+	pool.Evict("bob")
+	assert.Equal(t, 1, pool.Len())
+
+	_, err = pool.Client(ctx, "revoked")
+	assert.ErrorContains(t, err, `failed to get token for tenant "revoked": no token`)
+}
+
+func TestClientPoolOptions(t *testing.T) {
+	tokens := func(context.Context, string) (replicate.TokenProvider, error) {
+		return replicate.StaticToken("r8_token"), nil
+	}
+
+	_, err := replicate.NewClientPool(tokens, 0)
+	assert.ErrorContains(t, err, "max clients must be positive")
+
+	_, err = replicate.NewClientPool(tokens, 1, replicate.WithBaseURL("not a url"))
+	assert.ErrorContains(t, err, "invalid base URL")
+}
+
+func TestClientPoolSharesTransport(t *testing.T) {
+	var connections atomic.Int32
+	mockServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&replicate.Account{Username: strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")})
+	}))
+	mockServer.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	mockServer.Start()
+	defer mockServer.Close()
+
+	tokens := func(_ context.Context, tenant string) (replicate.TokenProvider, error) {
+		return replicate.StaticToken(tenant), nil
+	}
+	pool, err := replicate.NewClientPool(tokens, 4,
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithMaxIdleConnsPerHost(4),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, tenant := range []string{"acme", "globex", "initech"} {
+		client, err := pool.Client(ctx, tenant)
+		require.NoError(t, err)
+		account, err := client.GetCurrentAccount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, tenant, account.Username)
+	}
+	assert.EqualValues(t, 1, connections.Load(), "clients should reuse the shared transport's connection")
+}
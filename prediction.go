@@ -105,11 +105,13 @@ func (p Prediction) Progress() *PredictionProgress {
 
 // createPredictionRequest creates a prediction request.
 func (r *Client) createPredictionRequest(ctx context.Context, path string, data map[string]interface{}, input PredictionInput, webhook *Webhook, stream bool) (*http.Request, error) {
-	// Convert File objects in input to their "get" URL value
-	for key, value := range input {
-		if file, ok := value.(*File); ok {
-			input[key] = file.URLs["get"]
-		}
+	input, err := r.resolveFileInputs(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := input.Validate(); err != nil {
+		return nil, err
 	}
 
 	if data == nil {
@@ -118,11 +120,8 @@ func (r *Client) createPredictionRequest(ctx context.Context, path string, data
 
 	data["input"] = input
 
-	if webhook != nil {
-		data["webhook"] = webhook.URL
-		if len(webhook.Events) > 0 {
-			data["webhook_events_filter"] = webhook.Events
-		}
+	if err := r.setWebhook(data, webhook); err != nil {
+		return nil, err
 	}
 
 	if stream {
@@ -166,7 +165,7 @@ func (r *Client) CreatePrediction(ctx context.Context, identifier string, input
 	}
 
 	prediction := &Prediction{}
-	if err := r.do(req, prediction); err != nil {
+	if err := r.createPrediction(req, prediction); err != nil {
 		return nil, fmt.Errorf("failed to create prediction: %w", err)
 	}
 
@@ -174,9 +173,8 @@ func (r *Client) CreatePrediction(ctx context.Context, identifier string, input
 }
 
 // ListPredictions returns a paginated list of predictions.
-func (r *Client) ListPredictions(ctx context.Context) (*Page[Prediction], error) {
-	response := &Page[Prediction]{}
-	err := r.fetch(ctx, http.MethodGet, "/predictions", nil, response)
+func (r *Client) ListPredictions(ctx context.Context, opts ...ListOption) (*Page[Prediction], error) {
+	response, err := listPage[Prediction](ctx, r, "/predictions", opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list predictions: %w", err)
 	}
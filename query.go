@@ -0,0 +1,63 @@
+package replicate
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// WithQueryParams adds q to the query of every API request the client makes,
+// such as routing parameters required by a gateway. It can be used more than
+// once; later values for a key replace earlier ones.
+//
+// Parameters the client sets itself, like pagination cursors, and
+// parameters set with WithRequestQuery take precedence.
+func WithQueryParams(q url.Values) ClientOption {
+	return func(o *clientOptions) error {
+		o.queryParams = mergeValues(o.queryParams, q)
+		return nil
+	}
+}
+
+// setQueryParams adds the parameters set with WithRequestQuery and
+// WithQueryParams, without replacing parameters already in the request URL.
+func (r *Client) setQueryParams(ctx context.Context, request *http.Request) {
+	contextQuery := requestQuery(ctx)
+	if len(contextQuery) == 0 && len(r.options.queryParams) == 0 {
+		return
+	}
+
+	query := request.URL.Query()
+	for _, extra := range []url.Values{contextQuery, r.options.queryParams} {
+		for key, values := range extra {
+			if _, ok := query[key]; ok {
+				continue
+			}
+			query[key] = append([]string(nil), values...)
+		}
+	}
+	request.URL.RawQuery = query.Encode()
+}
+
+// mergeValues returns a copy of base with the keys of extra replaced.
+func mergeValues(base, extra url.Values) url.Values {
+	merged := cloneValues(base)
+	if merged == nil {
+		merged = make(url.Values, len(extra))
+	}
+	for key, values := range extra {
+		merged[key] = append([]string(nil), values...)
+	}
+	return merged
+}
+
+func cloneValues(v url.Values) url.Values {
+	if v == nil {
+		return nil
+	}
+	c := make(url.Values, len(v))
+	for key, values := range v {
+		c[key] = append([]string(nil), values...)
+	}
+	return c
+}
@@ -0,0 +1,108 @@
+package replicate
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitState describes the rate limit reported by the API.
+type RateLimitState struct {
+	// Limit is the number of requests allowed in the current window.
+	Limit int
+
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+
+	// Reset is when the current window ends. It is zero if the API didn't
+	// report it.
+	Reset time.Time
+
+	// ObservedAt is when the response carrying the state was received.
+	ObservedAt time.Time
+}
+
+var rateLimitHeaderPrefixes = []string{"X-RateLimit-", "RateLimit-"}
+
+// parseRateLimit reads the rate limit headers of a response. It returns nil
+// if the response has none.
+func parseRateLimit(header http.Header, now time.Time) *RateLimitState {
+	for _, prefix := range rateLimitHeaderPrefixes {
+		remaining, err := strconv.Atoi(header.Get(prefix + "Remaining"))
+		if err != nil {
+			continue
+		}
+
+		state := &RateLimitState{
+			Remaining:  remaining,
+			ObservedAt: now,
+		}
+		if limit, err := strconv.Atoi(header.Get(prefix + "Limit")); err == nil {
+			state.Limit = limit
+		}
+		if reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64); err == nil {
+			// Some servers send a Unix timestamp, others the seconds left.
+			if reset > 1_000_000_000 {
+				state.Reset = time.Unix(reset, 0)
+			} else {
+				state.Reset = now.Add(time.Duration(reset) * time.Second)
+			}
+		}
+
+		return state
+	}
+
+	return nil
+}
+
+// WithAutoThrottle makes the client wait for the rate limit window to reset
+// before sending a request once the API reports minRemaining or fewer
+// requests left.
+func WithAutoThrottle(minRemaining int) ClientOption {
+	return func(o *clientOptions) error {
+		o.throttleBelow = &minRemaining
+		return nil
+	}
+}
+
+// LastRateLimit returns the rate limit state from the most recent response
+// that reported one, or nil if none has.
+func (r *Client) LastRateLimit() *RateLimitState {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	if r.state.rateLimit == nil {
+		return nil
+	}
+	state := *r.state.rateLimit
+	return &state
+}
+
+func (r *Client) recordRateLimit(state *RateLimitState) {
+	if state == nil {
+		return
+	}
+
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	if r.state.rateLimit == nil || !state.ObservedAt.Before(r.state.rateLimit.ObservedAt) {
+		r.state.rateLimit = state
+	}
+}
+
+// throttle waits until the rate limit window resets if auto-throttling is
+// enabled and the remaining quota is low.
+func (r *Client) throttle(ctx context.Context) error {
+	if r.options.throttleBelow == nil {
+		return nil
+	}
+
+	state := r.LastRateLimit()
+	if state == nil || state.Remaining > *r.options.throttleBelow || state.Reset.IsZero() {
+		return nil
+	}
+
+	return r.sleep(ctx, state.Reset.Sub(r.clock().Now()))
+}
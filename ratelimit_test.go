@@ -0,0 +1,123 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestLastRateLimit(t *testing.T) {
+	remaining := 10
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "10")
+		w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(remaining))
+		w.Header().Set("X-RateLimit-Reset", "30")
+		if remaining == 0 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		remaining--
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+	assert.Nil(t, client.LastRateLimit())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+
+	state := client.LastRateLimit()
+	require.NotNil(t, state)
+	assert.Equal(t, 10, state.Limit)
+	assert.Equal(t, 10, state.Remaining)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), state.Reset, 5*time.Second)
+
+	remaining = 0
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.ErrorIs(t, err, replicate.ErrRateLimited)
+
+	var apiErr *replicate.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.NotNil(t, apiErr.RateLimit)
+	assert.Equal(t, 0, apiErr.RateLimit.Remaining)
+	assert.Equal(t, 0, client.LastRateLimit().Remaining)
+}
+
+func TestAutoThrottle(t *testing.T) {
+	var requestTimes []time.Time
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requestTimes = append(requestTimes, time.Now())
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1")
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithAutoThrottle(0),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := 0; i < 2; i++ {
+		_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+		require.NoError(t, err)
+	}
+
+	require.Len(t, requestTimes, 2)
+	assert.GreaterOrEqual(t, requestTimes[1].Sub(requestTimes[0]), 900*time.Millisecond)
+}
+
+func TestGetLimits(t *testing.T) {
+	reportLimit := true
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/account", r.URL.Path)
+		if reportLimit {
+			w.Header().Set("X-RateLimit-Limit", "600")
+			w.Header().Set("X-RateLimit-Remaining", "599")
+		}
+		w.Write([]byte(`{"type": "organization", "username": "acme"}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	limits, err := client.GetLimits(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, limits.RateLimit)
+	assert.Equal(t, 600, limits.RateLimit.Limit)
+	assert.Equal(t, 599, limits.RateLimit.Remaining)
+	assert.Equal(t, "600", limits.Header.Get("X-RateLimit-Limit"))
+
+	reportLimit = false
+	limits, err = client.GetLimits(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, limits.RateLimit)
+}
@@ -0,0 +1,87 @@
+package replicate
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// defaultMaxRedirects matches the limit of http.Client.
+const defaultMaxRedirects = 10
+
+// RedirectPolicy decides whether the client follows a redirect, like
+// http.Client.CheckRedirect. req is the upcoming request and via holds the
+// requests made so far, oldest first. Returning http.ErrUseLastResponse
+// stops following redirects and returns the redirect response itself; other
+// errors fail the call.
+type RedirectPolicy func(req *http.Request, via []*http.Request) error
+
+// WithRedirectPolicy sets how the client follows redirects, such as those
+// from output download URLs to signed storage URLs. By default, the client
+// uses the CheckRedirect of its HTTP client, or follows up to 10 redirects.
+//
+// Whatever the policy, credentials are never sent to another host: the
+// Authorization and Cookie headers are removed from redirects that leave
+// the host of the original request, or downgrade from https to http.
+func WithRedirectPolicy(policy RedirectPolicy) ClientOption {
+	return func(o *clientOptions) error {
+		o.redirectPolicy = policy
+		return nil
+	}
+}
+
+// WithMaxRedirects sets how many redirects the client follows for a request.
+// With 0, redirects aren't followed, and calls fail with an *APIError for
+// the redirect response, whose Header holds its Location.
+func WithMaxRedirects(n int) ClientOption {
+	return func(o *clientOptions) error {
+		if n < 0 {
+			return fmt.Errorf("max redirects must not be negative, got %d", n)
+		}
+		o.redirectPolicy = maxRedirects(n)
+		return nil
+	}
+}
+
+func maxRedirects(n int) RedirectPolicy {
+	return func(_ *http.Request, via []*http.Request) error {
+		if n == 0 {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= n {
+			return fmt.Errorf("stopped after %d redirects", n)
+		}
+		return nil
+	}
+}
+
+// withRedirectPolicy returns a copy of httpClient that follows redirects
+// according to policy, falling back to the client's own CheckRedirect, and
+// that doesn't leak credentials across hosts.
+func withRedirectPolicy(httpClient *http.Client, policy RedirectPolicy) *http.Client {
+	if policy == nil {
+		policy = httpClient.CheckRedirect
+	}
+	if policy == nil {
+		policy = maxRedirects(defaultMaxRedirects)
+	}
+
+	configured := *httpClient
+	configured.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		stripCredentialsOnRedirect(req, via[0])
+		return policy(req, via)
+	}
+	return &configured
+}
+
+// stripCredentialsOnRedirect removes credentials from a redirected request
+// unless it goes to the same host as the original request, over a connection
+// at least as secure.
+func stripCredentialsOnRedirect(req, original *http.Request) {
+	sameHost := req.URL.Host == original.URL.Host
+	downgrade := original.URL.Scheme == "https" && req.URL.Scheme != "https"
+	if sameHost && !downgrade {
+		return
+	}
+	req.Header.Del("Authorization")
+	req.Header.Del("Cookie")
+}
@@ -0,0 +1,88 @@
+package replicate_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestRedirectPolicy(t *testing.T) {
+	var authorization atomic.Value
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		w.Write([]byte(`{"type": "user", "username": "alice"}`))
+	}))
+	defer target.Close()
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/account":
+			http.Redirect(w, r, "/moved/account", http.StatusFound)
+		case "/moved/account":
+			authorization.Store(r.Header.Get("Authorization"))
+			http.Redirect(w, r, target.URL+"/account", http.StatusFound)
+		}
+	}))
+	defer origin.Close()
+
+	newClient := func(t *testing.T, opts ...replicate.ClientOption) *replicate.Client {
+		opts = append([]replicate.ClientOption{
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(origin.URL),
+			replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+		}, opts...)
+		client, err := replicate.NewClient(opts...)
+		require.NoError(t, err)
+		return client
+	}
+	ctx := context.Background()
+
+	t.Run("strips credentials across hosts", func(t *testing.T) {
+		account, err := newClient(t).GetCurrentAccount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", account.Username)
+		assert.Equal(t, "", authorization.Load())
+	})
+
+	t.Run("keeps credentials on the same host", func(t *testing.T) {
+		var seen []string
+		client := newClient(t, replicate.WithRedirectPolicy(func(req *http.Request, _ []*http.Request) error {
+			seen = append(seen, req.Header.Get("Authorization"))
+			return nil
+		}))
+		_, err := client.GetCurrentAccount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bearer test-token", ""}, seen)
+	})
+
+	t.Run("max redirects", func(t *testing.T) {
+		_, err := newClient(t, replicate.WithMaxRedirects(1)).GetCurrentAccount(ctx)
+		assert.ErrorContains(t, err, "stopped after 1 redirects")
+
+		_, err = newClient(t, replicate.WithMaxRedirects(0)).GetCurrentAccount(ctx)
+		apiErr := &replicate.APIError{}
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusFound, apiErr.Status)
+
+		_, err = replicate.NewClient(replicate.WithToken("test-token"), replicate.WithMaxRedirects(-1))
+		assert.ErrorContains(t, err, "max redirects must not be negative")
+	})
+
+	t.Run("HTTP client policy", func(t *testing.T) {
+		client := newClient(t, replicate.WithHTTPClient(&http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return errors.New("no redirects here")
+			},
+		}))
+		_, err := client.GetCurrentAccount(ctx)
+		assert.ErrorContains(t, err, "no redirects here")
+	})
+}
@@ -0,0 +1,80 @@
+package replicate
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/replicate/replicate-go/streaming"
+)
+
+// Replicate is the set of API calls made by Client.
+//
+// Accept a Replicate instead of a *Client in code that should be testable
+// without the API, and substitute a fake in tests. Methods may be added to
+// this interface in minor releases, so fakes should embed a Replicate to
+// stay compatible.
+type Replicate interface {
+	// Running models
+	Run(ctx context.Context, identifier string, input PredictionInput, webhook *Webhook) (PredictionOutput, error)
+	RunWithOptions(ctx context.Context, identifier string, input PredictionInput, webhook *Webhook, opts ...RunOption) (PredictionOutput, error)
+	Stream(ctx context.Context, identifier string, input PredictionInput, webhook *Webhook) (<-chan SSEEvent, <-chan error)
+
+	// Predictions
+	CreatePrediction(ctx context.Context, identifier string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error)
+	CreatePredictionWithModel(ctx context.Context, modelOwner string, modelName string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error)
+	CreatePredictionWithDeployment(ctx context.Context, deploymentOwner string, deploymentName string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error)
+	ListPredictions(ctx context.Context, opts ...ListOption) (*Page[Prediction], error)
+	GetPrediction(ctx context.Context, id string) (*Prediction, error)
+	CancelPrediction(ctx context.Context, id string) (*Prediction, error)
+	Wait(ctx context.Context, prediction *Prediction, opts ...WaitOption) error
+	WaitAsync(ctx context.Context, prediction *Prediction, opts ...WaitOption) (<-chan *Prediction, <-chan error)
+	StreamPrediction(ctx context.Context, prediction *Prediction) (<-chan SSEEvent, <-chan error)
+	StreamPredictionText(ctx context.Context, prediction *Prediction) (io.ReadCloser, error)
+	StreamPredictionFiles(prediction *Prediction) (streaming.FileStreamer, error)
+
+	// Models
+	ListModels(ctx context.Context, opts ...ListOption) (*Page[Model], error)
+	SearchModels(ctx context.Context, query string) (*Page[Model], error)
+	GetModel(ctx context.Context, modelOwner string, modelName string) (*Model, error)
+	CreateModel(ctx context.Context, modelOwner string, modelName string, options CreateModelOptions) (*Model, error)
+	DeleteModel(ctx context.Context, modelOwner string, modelName string) error
+	ListModelVersions(ctx context.Context, modelOwner string, modelName string, opts ...ListOption) (*Page[ModelVersion], error)
+	GetModelVersion(ctx context.Context, modelOwner string, modelName string, versionID string) (*ModelVersion, error)
+	DeleteModelVersion(ctx context.Context, modelOwner string, modelName string, versionID string) error
+
+	// Trainings
+	CreateTraining(ctx context.Context, modelOwner string, modelName string, version string, destination string, input TrainingInput, webhook *Webhook) (*Training, error)
+	ListTrainings(ctx context.Context, opts ...ListOption) (*Page[Training], error)
+	GetTraining(ctx context.Context, trainingID string) (*Training, error)
+	CancelTraining(ctx context.Context, trainingID string) (*Training, error)
+
+	// Deployments
+	ListDeployments(ctx context.Context, opts ...ListOption) (*Page[Deployment], error)
+	GetDeployment(ctx context.Context, deploymentOwner string, deploymentName string) (*Deployment, error)
+	CreateDeployment(ctx context.Context, options CreateDeploymentOptions) (*Deployment, error)
+	UpdateDeployment(ctx context.Context, deploymentOwner string, deploymentName string, options UpdateDeploymentOptions) (*Deployment, error)
+	DeleteDeployment(ctx context.Context, deploymentOwner string, deploymentName string) error
+
+	// Files
+	CreateFileFromPath(ctx context.Context, filePath string, options *CreateFileOptions) (*File, error)
+	CreateFileFromBytes(ctx context.Context, data []byte, options *CreateFileOptions) (*File, error)
+	CreateFileFromBuffer(ctx context.Context, buf *bytes.Buffer, options *CreateFileOptions) (*File, error)
+	ListFiles(ctx context.Context, opts ...ListOption) (*Page[File], error)
+	GetFile(ctx context.Context, fileID string) (*File, error)
+	DeleteFile(ctx context.Context, fileID string) error
+
+	// Collections, hardware, and accounts
+	ListCollections(ctx context.Context, opts ...ListOption) (*Page[Collection], error)
+	GetCollection(ctx context.Context, slug string) (*Collection, error)
+	ListHardware(ctx context.Context) (*[]Hardware, error)
+	GetCurrentAccount(ctx context.Context) (*Account, error)
+	CurrentOwner(ctx context.Context) (*Account, error)
+	GetLimits(ctx context.Context) (*Limits, error)
+	UsageReport(ctx context.Context, since time.Time, until time.Time) (*AccountUsageReport, error)
+	GetDefaultWebhookSecret(ctx context.Context) (*WebhookSigningSecret, error)
+	Verify(ctx context.Context) error
+}
+
+var _ Replicate = (*Client)(nil)
@@ -0,0 +1,40 @@
+package replicate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+// fakeReplicate overrides one method and leaves the rest unimplemented.
+type fakeReplicate struct {
+	replicate.Replicate
+}
+
+func (fakeReplicate) GetPrediction(_ context.Context, id string) (*replicate.Prediction, error) {
+	return &replicate.Prediction{ID: id, Status: replicate.Succeeded}, nil
+}
+
+func predictionStatus(ctx context.Context, r replicate.Replicate, id string) (replicate.Status, error) {
+	prediction, err := r.GetPrediction(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return prediction.Status, nil
+}
+
+func TestReplicateInterface(t *testing.T) {
+	status, err := predictionStatus(context.Background(), fakeReplicate{}, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, status)
+
+	client, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	var r replicate.Replicate = client
+	assert.NotNil(t, r)
+}
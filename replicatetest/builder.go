@@ -0,0 +1,178 @@
+package replicatetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+const (
+	// DefaultPredictionID is the ID of predictions built without WithID.
+	DefaultPredictionID = "ufawqhfynnddngldkgtslldrkq"
+
+	// DefaultVersion is the version of predictions built without
+	// WithVersion.
+	DefaultVersion = "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"
+)
+
+// buildTime is the creation time of built predictions, so that their JSON
+// is the same on every run.
+var buildTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// PredictionOption is a function that modifies a prediction built with
+// NewPrediction.
+type PredictionOption func(*replicate.Prediction)
+
+// WithID sets the ID of the prediction, and the URLs derived from it.
+func WithID(id string) PredictionOption {
+	return func(p *replicate.Prediction) {
+		p.ID = id
+	}
+}
+
+// WithStatus sets the status of the prediction. Timestamps and metrics are
+// set to match.
+func WithStatus(status replicate.Status) PredictionOption {
+	return func(p *replicate.Prediction) {
+		p.Status = status
+	}
+}
+
+// WithModel sets the model of the prediction, as "owner/name".
+func WithModel(model string) PredictionOption {
+	return func(p *replicate.Prediction) {
+		p.Model = model
+	}
+}
+
+// WithVersion sets the version ID of the prediction.
+func WithVersion(version string) PredictionOption {
+	return func(p *replicate.Prediction) {
+		p.Version = version
+	}
+}
+
+// WithInput sets the input of the prediction.
+func WithInput(input replicate.PredictionInput) PredictionOption {
+	return func(p *replicate.Prediction) {
+		p.Input = input
+	}
+}
+
+// WithOutput sets the output of the prediction.
+func WithOutput(output replicate.PredictionOutput) PredictionOption {
+	return func(p *replicate.Prediction) {
+		p.Output = output
+	}
+}
+
+// WithLogs sets the logs of the prediction.
+func WithLogs(logs string) PredictionOption {
+	return func(p *replicate.Prediction) {
+		p.Logs = &logs
+	}
+}
+
+// WithPredictionError sets the error of the prediction. It doesn't change
+// the status; combine it with WithStatus(replicate.Failed).
+func WithPredictionError(err string) PredictionOption {
+	return func(p *replicate.Prediction) {
+		p.Error = err
+	}
+}
+
+// WithMetrics sets the metrics of the prediction.
+func WithMetrics(metrics replicate.PredictionMetrics) PredictionOption {
+	return func(p *replicate.Prediction) {
+		p.Metrics = &metrics
+	}
+}
+
+// WithStream adds a stream URL to the prediction, as if it was created with
+// streaming enabled.
+func WithStream() PredictionOption {
+	return func(p *replicate.Prediction) {
+		// The URL is set once the ID is known.
+		p.URLs["stream"] = ""
+	}
+}
+
+// WithWebhook sets the webhook of the prediction.
+func WithWebhook(url string, events ...replicate.WebhookEventType) PredictionOption {
+	return func(p *replicate.Prediction) {
+		p.Webhook = &url
+		p.WebhookEventsFilter = events
+	}
+}
+
+// NewPrediction returns a prediction as the API would return it, with fixed
+// defaults so tests are deterministic.
+//
+// The default is a succeeded prediction of DefaultVersion with ID
+// DefaultPredictionID. Timestamps and metrics are set to match the status,
+// and RawJSON returns the prediction's JSON.
+func NewPrediction(opts ...PredictionOption) *replicate.Prediction {
+	p := &replicate.Prediction{
+		ID:        DefaultPredictionID,
+		Status:    replicate.Succeeded,
+		Version:   DefaultVersion,
+		Input:     replicate.PredictionInput{},
+		Source:    replicate.SourceAPI,
+		CreatedAt: buildTime.Format(time.RFC3339Nano),
+		URLs:      map[string]string{},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+	urls := predictionURLs(p.ID)
+	if _, ok := p.URLs["stream"]; ok {
+		urls["stream"] = "https://streaming-api.svc.us.c.replicate.net/v1/streams/" + p.ID
+	}
+	p.URLs = urls
+
+	if p.Status != replicate.Starting && p.StartedAt == nil {
+		startedAt := buildTime.Add(time.Second).Format(time.RFC3339Nano)
+		p.StartedAt = &startedAt
+	}
+	if p.Status.Terminated() {
+		if p.CompletedAt == nil {
+			completedAt := buildTime.Add(3 * time.Second).Format(time.RFC3339Nano)
+			p.CompletedAt = &completedAt
+		}
+		if p.Metrics == nil {
+			predictTime := 2.0
+			totalTime := 3.0
+			p.Metrics = &replicate.PredictionMetrics{PredictTime: &predictTime, TotalTime: &totalTime}
+		}
+	}
+
+	// Round trip through JSON, so the prediction looks exactly like one
+	// decoded from a response.
+	decoded := &replicate.Prediction{}
+	if err := json.Unmarshal(PredictionJSON(p), decoded); err != nil {
+		panic(fmt.Sprintf("replicatetest: failed to decode built prediction: %v", err))
+	}
+	return decoded
+}
+
+// PredictionJSON returns the JSON encoding of a prediction, for use as a
+// response body.
+func PredictionJSON(p *replicate.Prediction) []byte {
+	data, err := json.Marshal(p)
+	if err != nil {
+		panic(fmt.Sprintf("replicatetest: failed to encode prediction: %v", err))
+	}
+	return data
+}
+
+func predictionURLs(id string) map[string]string {
+	base := "https://api.replicate.com/v1/predictions/" + id
+	return map[string]string{
+		"get":    base,
+		"cancel": base + "/cancel",
+		"web":    "https://replicate.com/p/" + id,
+	}
+}
@@ -0,0 +1,59 @@
+package replicatetest_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestNewPrediction(t *testing.T) {
+	p := replicatetest.NewPrediction()
+	assert.Equal(t, replicatetest.DefaultPredictionID, p.ID)
+	assert.Equal(t, replicate.Succeeded, p.Status)
+	assert.Equal(t, "https://api.replicate.com/v1/predictions/"+p.ID, p.URLs["get"])
+	assert.NotNil(t, p.CompletedAt)
+	require.NotNil(t, p.Metrics)
+	assert.NotNil(t, p.Metrics.PredictTime)
+	assert.NotEmpty(t, p.RawJSON())
+
+	assert.Equal(t, p, replicatetest.NewPrediction(), "predictions are deterministic")
+}
+
+func TestNewPredictionOptions(t *testing.T) {
+	p := replicatetest.NewPrediction(
+		replicatetest.WithStream(),
+		replicatetest.WithID("abc"),
+		replicatetest.WithStatus(replicate.Processing),
+		replicatetest.WithModel("meta/llama"),
+		replicatetest.WithInput(replicate.PredictionInput{"prompt": "hi"}),
+		replicatetest.WithLogs("loading"),
+		replicatetest.WithOutput([]any{"Hel", "lo"}),
+	)
+
+	assert.Equal(t, "abc", p.ID)
+	assert.Equal(t, replicate.Processing, p.Status)
+	assert.Equal(t, "https://streaming-api.svc.us.c.replicate.net/v1/streams/abc", p.URLs["stream"])
+	assert.Equal(t, "https://api.replicate.com/v1/predictions/abc/cancel", p.URLs["cancel"])
+	assert.NotNil(t, p.StartedAt)
+	assert.Nil(t, p.CompletedAt)
+	assert.Nil(t, p.Metrics)
+	assert.Equal(t, "loading", *p.Logs)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(replicatetest.PredictionJSON(p), &decoded))
+	assert.Equal(t, "meta/llama", decoded["model"])
+	assert.Equal(t, []any{"Hel", "lo"}, decoded["output"])
+	assert.Equal(t, map[string]any{"prompt": "hi"}, decoded["input"])
+
+	failed := replicatetest.NewPrediction(
+		replicatetest.WithStatus(replicate.Failed),
+		replicatetest.WithPredictionError("boom"),
+	)
+	assert.Equal(t, "boom", failed.Error)
+	assert.NotNil(t, failed.CompletedAt)
+}
@@ -0,0 +1,227 @@
+package replicatetest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// FakeClock is a simulated replicate.Clock, for use with
+// replicate.WithClock. Time only passes when Advance is called, or, with
+// auto-advance enabled, whenever the client waits.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	auto    bool
+	waiters []*fakeTimer
+
+	// changed is closed and replaced whenever a waiter is added.
+	changed chan struct{}
+}
+
+var _ replicate.Clock = (*FakeClock)(nil)
+
+// NewFakeClock returns a fake clock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, changed: make(chan struct{})}
+}
+
+// AutoAdvance sets whether the clock advances on its own. When enabled, the
+// clock skips ahead to the deadline of a timer or ticker as soon as the
+// client waits on it, so polling, retry delays, and reconnect delays take no
+// real time. Timers set with AfterFunc, such as stream stall timeouts, only
+// fire if the clock passes them on the way to another deadline.
+func (c *FakeClock) AutoAdvance(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.auto = enabled
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer that fires once the clock reaches d from now.
+func (c *FakeClock) NewTimer(d time.Duration) replicate.Timer {
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	c.schedule(t, d)
+	return t
+}
+
+// NewTicker returns a ticker that fires every d of clock time.
+func (c *FakeClock) NewTicker(d time.Duration) replicate.Ticker {
+	if d <= 0 {
+		panic("replicatetest: non-positive interval for NewTicker")
+	}
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1), period: d}
+	c.schedule(t, d)
+	return fakeTicker{t}
+}
+
+// AfterFunc calls f in its own goroutine once the clock reaches d from now.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) replicate.Timer {
+	t := &fakeTimer{clock: c, f: f}
+	c.schedule(t, d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing timers and tickers that are
+// due, in order of their deadlines.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanceLocked(c.now.Add(d))
+}
+
+// Waiters returns the number of timers and tickers that haven't fired or
+// been stopped.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until at least n timers and tickers are pending, which
+// means the code under test is waiting on the clock and it's safe to call
+// Advance. It returns the context's error if it's done first.
+func (c *FakeClock) BlockUntil(ctx context.Context, n int) error {
+	for {
+		c.mu.Lock()
+		count, changed := len(c.waiters), c.changed
+		c.mu.Unlock()
+		if count >= n {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (c *FakeClock) schedule(t *fakeTimer, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t.deadline = c.now.Add(d)
+	c.waiters = append(c.waiters, t)
+	close(c.changed)
+	c.changed = make(chan struct{})
+
+	// A timer that's already due fires right away, as with the time package.
+	if d <= 0 {
+		c.advanceLocked(c.now)
+	}
+}
+
+func (c *FakeClock) advanceLocked(target time.Time) {
+	for {
+		sort.SliceStable(c.waiters, func(i, j int) bool {
+			return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+		})
+		if len(c.waiters) == 0 || c.waiters[0].deadline.After(target) {
+			break
+		}
+
+		t := c.waiters[0]
+		if t.deadline.After(c.now) {
+			c.now = t.deadline
+		}
+		if t.period > 0 {
+			t.deadline = t.deadline.Add(t.period)
+		} else {
+			c.waiters = c.waiters[1:]
+		}
+		t.fire(c.now)
+	}
+	if target.After(c.now) {
+		c.now = target
+	}
+}
+
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, w := range c.waiters {
+		if w == t {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTimer is a timer, ticker, or AfterFunc timer of a FakeClock.
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+	f        func()
+}
+
+func (t *fakeTimer) fire(now time.Time) {
+	if t.f != nil {
+		go t.f()
+		return
+	}
+	// Like time.Ticker, drop ticks the receiver isn't keeping up with.
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
+
+// C returns the timer's channel. With auto-advance enabled, receiving from
+// the channel is about to block, so the clock skips ahead to the deadline.
+func (t *fakeTimer) C() <-chan time.Time {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.auto && len(t.ch) == 0 {
+		for _, w := range c.waiters {
+			if w == t {
+				c.advanceLocked(t.deadline)
+				break
+			}
+		}
+	}
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	active := c.remove(t)
+	c.mu.Unlock()
+
+	// As with the time package since Go 1.23, a stale value isn't received
+	// after Reset.
+	if t.ch != nil {
+		select {
+		case <-t.ch:
+		default:
+		}
+	}
+	c.schedule(t, d)
+	return active
+}
+
+// fakeTicker adapts a periodic fakeTimer to replicate.Ticker.
+type fakeTicker struct{ *fakeTimer }
+
+func (t fakeTicker) Stop() { t.fakeTimer.Stop() }
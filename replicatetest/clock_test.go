@@ -0,0 +1,106 @@
+package replicatetest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+var clockStart = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClockAutoAdvance(t *testing.T) {
+	server := replicatetest.NewServer()
+	defer server.Close()
+	server.Script("owner/slow", replicatetest.PredictionScript{
+		Statuses: []replicate.Status{replicate.Processing, replicate.Processing, replicate.Succeeded},
+	})
+
+	clock := replicatetest.NewFakeClock(clockStart)
+	clock.AutoAdvance(true)
+	client, err := server.Client(
+		replicate.WithClock(clock),
+		replicate.WithRetryPolicy(3, &replicate.ConstantBackoff{Base: time.Hour}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction, err := client.CreatePrediction(ctx, "owner/slow", nil, nil, false)
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = client.Wait(ctx, prediction, replicate.WithPollingInterval(10*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+	assert.Equal(t, clockStart.Add(30*time.Minute), clock.Now())
+
+	server.InjectFault(replicatetest.ServerErrors(2))
+	_, err = client.GetPrediction(ctx, prediction.ID)
+	require.NoError(t, err)
+	assert.Equal(t, clockStart.Add(30*time.Minute+2*time.Hour), clock.Now())
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestFakeClockAdvance(t *testing.T) {
+	server := replicatetest.NewServer()
+	defer server.Close()
+
+	clock := replicatetest.NewFakeClock(clockStart)
+	client, err := server.Client(replicate.WithClock(clock))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction, err := client.CreatePrediction(ctx, "owner/model", nil, nil, false)
+	require.NoError(t, err)
+
+	predictions, errs := client.WaitAsync(ctx, prediction, replicate.WithPollingInterval(time.Minute))
+	require.NoError(t, clock.BlockUntil(ctx, 1))
+
+	clock.Advance(59 * time.Second)
+	select {
+	case <-predictions:
+		t.Fatal("polled before the interval elapsed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+	polled := <-predictions
+	assert.Equal(t, replicate.Processing, polled.Status)
+
+	clock.Advance(time.Minute)
+	polled = <-predictions
+	assert.Equal(t, replicate.Succeeded, polled.Status)
+	require.NoError(t, <-errs)
+	assert.Equal(t, 0, clock.Waiters())
+}
+
+func TestFakeClockTimers(t *testing.T) {
+	clock := replicatetest.NewFakeClock(clockStart)
+
+	timer := clock.NewTimer(time.Second)
+	fired := make(chan struct{})
+	clock.AfterFunc(2*time.Second, func() { close(fired) })
+	stopped := clock.NewTimer(time.Second)
+	assert.True(t, stopped.Stop())
+	assert.Equal(t, 2, clock.Waiters())
+
+	clock.Advance(3 * time.Second)
+	assert.Equal(t, clockStart.Add(time.Second), <-timer.C())
+	<-fired
+	assert.False(t, timer.Stop())
+	assert.Empty(t, stopped.C())
+
+	assert.False(t, timer.Reset(time.Second))
+	clock.Advance(time.Second)
+	assert.Equal(t, clockStart.Add(4*time.Second), <-timer.C())
+}
@@ -0,0 +1,113 @@
+package replicatetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// ConformanceCheck is the result of one call made by Conformance.
+type ConformanceCheck struct {
+	// Name describes what the call checks, such as "account".
+	Name string
+
+	// Err is the error the call failed with, or nil if it succeeded.
+	Err error
+
+	// Duration is how long the call took.
+	Duration time.Duration
+}
+
+// ConformanceReport is the result of Conformance.
+type ConformanceReport struct {
+	Checks []ConformanceCheck
+}
+
+// Err returns the errors of the failed checks joined together, or nil if
+// every check passed.
+func (r *ConformanceReport) Err() error {
+	var errs []error
+	for _, check := range r.Checks {
+		if check.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", check.Name, check.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// conformanceModel is a public model that's fetched to check model reads.
+const (
+	conformanceModelOwner = "replicate"
+	conformanceModelName  = "hello-world"
+)
+
+// Conformance makes cheap, read-only calls with client to check that it
+// can reach the API: that its token is valid, and that its base URL,
+// proxy, and TLS settings work. It doesn't create predictions or change
+// anything on the account, so it's safe to run against production, for
+// example when setting up a new environment.
+//
+// Every check runs even if an earlier one fails, so the report shows which
+// calls work.
+func Conformance(ctx context.Context, client replicate.Replicate) *ConformanceReport {
+	report := &ConformanceReport{}
+	check := func(name string, call func() error) {
+		start := time.Now()
+		err := call()
+		report.Checks = append(report.Checks, ConformanceCheck{Name: name, Err: err, Duration: time.Since(start)})
+	}
+
+	check("account", func() error {
+		account, err := client.GetCurrentAccount(ctx)
+		if err != nil {
+			return err
+		}
+		if account.Username == "" {
+			return errors.New("account has no username")
+		}
+		return nil
+	})
+
+	check("model", func() error {
+		model, err := client.GetModel(ctx, conformanceModelOwner, conformanceModelName)
+		if err != nil {
+			return err
+		}
+		if model.LatestVersion == nil || model.LatestVersion.ID == "" {
+			return fmt.Errorf("model %s/%s has no latest version", conformanceModelOwner, conformanceModelName)
+		}
+		return nil
+	})
+
+	check("hardware", func() error {
+		hardware, err := client.ListHardware(ctx)
+		if err != nil {
+			return err
+		}
+		if hardware == nil || len(*hardware) == 0 {
+			return errors.New("no hardware listed")
+		}
+		return nil
+	})
+
+	check("predictions", func() error {
+		_, err := client.ListPredictions(ctx)
+		return err
+	})
+
+	check("webhook secret", func() error {
+		secret, err := client.GetDefaultWebhookSecret(ctx)
+		if err != nil {
+			return err
+		}
+		if secret.Key == "" {
+			return errors.New("webhook signing secret is empty")
+		}
+		return nil
+	})
+
+	return report
+}
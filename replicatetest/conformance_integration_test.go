@@ -0,0 +1,37 @@
+//go:build integration
+
+package replicatetest_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+// TestConformanceLive runs the conformance checks against the real API with
+// the token in REPLICATE_API_TOKEN. Run it with:
+//
+//	go test -tags integration -run TestConformanceLive ./replicatetest
+func TestConformanceLive(t *testing.T) {
+	if os.Getenv("REPLICATE_API_TOKEN") == "" {
+		t.Skip("REPLICATE_API_TOKEN is not set")
+	}
+
+	client, err := replicate.NewClient(replicate.WithTokenFromEnv())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	report := replicatetest.Conformance(ctx, client)
+	for _, check := range report.Checks {
+		t.Logf("%s: %v (%s)", check.Name, check.Err, check.Duration)
+	}
+	require.NoError(t, report.Err())
+}
@@ -0,0 +1,46 @@
+package replicatetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/mocks"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestConformance(t *testing.T) {
+	errUnauthorized := &replicate.APIError{Status: 401, Detail: "Invalid token."}
+	mock := &mocks.ReplicateMock{
+		GetCurrentAccountFunc: func(ctx context.Context) (*replicate.Account, error) {
+			return nil, errUnauthorized
+		},
+		GetModelFunc: func(ctx context.Context, modelOwner string, modelName string) (*replicate.Model, error) {
+			return &replicate.Model{Owner: modelOwner, Name: modelName, LatestVersion: &replicate.ModelVersion{ID: "abc"}}, nil
+		},
+		ListHardwareFunc: func(ctx context.Context) (*[]replicate.Hardware, error) {
+			return &[]replicate.Hardware{{SKU: "cpu", Name: "CPU"}}, nil
+		},
+		ListPredictionsFunc: func(ctx context.Context, opts ...replicate.ListOption) (*replicate.Page[replicate.Prediction], error) {
+			return &replicate.Page[replicate.Prediction]{}, nil
+		},
+		GetDefaultWebhookSecretFunc: func(ctx context.Context) (*replicate.WebhookSigningSecret, error) {
+			return &replicate.WebhookSigningSecret{Key: "whsec_abc"}, nil
+		},
+	}
+
+	report := replicatetest.Conformance(context.Background(), mock)
+	require.Len(t, report.Checks, 5)
+	assert.ErrorIs(t, report.Checks[0].Err, errUnauthorized)
+	for _, check := range report.Checks[1:] {
+		assert.NoError(t, check.Err, check.Name)
+	}
+
+	err := report.Err()
+	assert.ErrorContains(t, err, "account: ")
+	assert.True(t, errors.Is(err, errUnauthorized))
+}
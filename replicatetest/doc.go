@@ -0,0 +1,3 @@
+// Package replicatetest provides utilities for testing code that uses the
+// Replicate client.
+package replicatetest
@@ -0,0 +1,165 @@
+package replicatetest
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// Fault describes a failure the fake Server injects into its responses.
+type Fault struct {
+	// Method and Path limit the fault to matching requests. Path matches
+	// the request path exactly, or as a prefix if it ends in "/". Empty
+	// values match any request.
+	Method string
+	Path   string
+
+	// Times is how many requests the fault affects. Zero means one.
+	Times int
+
+	// Delay holds the response back, to simulate a slow API. It's applied
+	// before any other effect of the fault.
+	Delay time.Duration
+
+	// Status, if set, makes the server respond with this status code and
+	// an API error instead of handling the request.
+	Status int
+
+	// RetryAfter sets the Retry-After header of a Status response.
+	RetryAfter time.Duration
+
+	// MalformedJSON cuts the response body in half, so it can't be decoded.
+	MalformedJSON bool
+
+	// TruncateStream drops the connection of a prediction stream before
+	// its "done" event, after sending the events before it.
+	TruncateStream bool
+}
+
+// RateLimited returns a fault that responds with status 429 and a
+// Retry-After header.
+func RateLimited(retryAfter time.Duration) Fault {
+	return Fault{Status: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+// ServerErrors returns a fault that responds to the next n requests with
+// status 503.
+func ServerErrors(n int) Fault {
+	return Fault{Status: http.StatusServiceUnavailable, Times: n}
+}
+
+// SlowResponses returns a fault that delays the next n responses by d.
+func SlowResponses(n int, d time.Duration) Fault {
+	return Fault{Delay: d, Times: n}
+}
+
+// MalformedJSON returns a fault that truncates the next response body.
+func MalformedJSON() Fault {
+	return Fault{MalformedJSON: true}
+}
+
+// TruncatedStream returns a fault that drops the next prediction stream
+// before it's done.
+func TruncatedStream() Fault {
+	return Fault{Path: "/stream/", TruncateStream: true}
+}
+
+// InjectFault adds a fault to the server. Faults affect matching requests in
+// the order they were added, until each has been used Times times.
+func (s *Server) InjectFault(fault Fault) {
+	if fault.Times <= 0 {
+		fault.Times = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	if f.Method != "" && f.Method != r.Method {
+		return false
+	}
+	if f.Path == "" {
+		return true
+	}
+	if strings.HasSuffix(f.Path, "/") {
+		return strings.HasPrefix(r.URL.Path, f.Path)
+	}
+	return r.URL.Path == f.Path
+}
+
+// takeFault returns the first fault matching r, using it up.
+func (s *Server) takeFault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+		f.Times--
+		if f.Times == 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+		fault := *f
+		return &fault
+	}
+	return nil
+}
+
+func (s *Server) serveFault(w http.ResponseWriter, r *http.Request, fault *Fault) {
+	if fault.Delay > 0 {
+		timer := time.NewTimer(fault.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault.Status != 0 {
+		if fault.RetryAfter > 0 {
+			seconds := int(math.Ceil(fault.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		}
+		writeError(w, &replicate.APIError{Status: fault.Status, Detail: "Injected fault."})
+		return
+	}
+
+	if !fault.MalformedJSON && !fault.TruncateStream {
+		s.route(w, r)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	s.route(rec, r)
+	body := rec.Body.Bytes()
+	switch {
+	case fault.TruncateStream:
+		if i := bytes.Index(body, []byte("event: done")); i >= 0 {
+			// Cut at the start of the event, including its id line.
+			if j := bytes.LastIndex(body[:i], []byte("\n\n")); j >= 0 {
+				body = body[:j+2]
+			} else {
+				body = nil
+			}
+		}
+	case fault.MalformedJSON:
+		body = body[:len(body)/2]
+	}
+
+	for key, values := range rec.Header() {
+		w.Header()[key] = values
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(rec.Code)
+	_, _ = w.Write(body)
+}
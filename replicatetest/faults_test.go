@@ -0,0 +1,108 @@
+package replicatetest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestFaultRateLimited(t *testing.T) {
+	server := replicatetest.NewServer()
+	defer server.Close()
+	server.InjectFault(replicatetest.RateLimited(2 * time.Second))
+
+	client, err := server.Client(replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}))
+	require.NoError(t, err)
+
+	_, err = client.CreatePrediction(context.Background(), "owner/model", nil, nil, false)
+	assert.ErrorIs(t, err, replicate.ErrRateLimited)
+	var apiErr *replicate.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "2", apiErr.Header.Get("Retry-After"))
+
+	_, err = client.CreatePrediction(context.Background(), "owner/model", nil, nil, false)
+	assert.NoError(t, err, "the fault only affects one request")
+}
+
+func TestFaultServerErrors(t *testing.T) {
+	server := replicatetest.NewServer()
+	defer server.Close()
+
+	client, err := server.Client(replicate.WithRetryPolicy(3, &replicate.ConstantBackoff{}))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	prediction, err := client.CreatePrediction(ctx, "owner/model", nil, nil, false)
+	require.NoError(t, err)
+
+	server.InjectFault(replicatetest.Fault{Method: http.MethodGet, Path: "/predictions/" + prediction.ID, Status: http.StatusBadGateway, Times: 2})
+	_, err = client.GetPrediction(ctx, prediction.ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, client.Stats().Retries)
+
+	server.InjectFault(replicatetest.ServerErrors(4))
+	_, err = client.GetPrediction(ctx, prediction.ID)
+	var apiErr *replicate.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.Status)
+}
+
+func TestFaultSlowAndMalformed(t *testing.T) {
+	server := replicatetest.NewServer()
+	defer server.Close()
+
+	client, err := server.Client()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	server.InjectFault(replicatetest.SlowResponses(1, 50*time.Millisecond))
+	start := time.Now()
+	_, err = client.CreatePrediction(ctx, "owner/model", nil, nil, false)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	server.InjectFault(replicatetest.MalformedJSON())
+	_, err = client.CreatePrediction(ctx, "owner/model", nil, nil, false)
+	var decodeErr *replicate.DecodeError
+	assert.True(t, errors.As(err, &decodeErr))
+}
+
+func TestFaultTruncatedStream(t *testing.T) {
+	server := replicatetest.NewServer()
+	defer server.Close()
+	server.Script("owner/llm", replicatetest.PredictionScript{Output: []string{"a", "b", "c"}})
+
+	client, err := server.Client()
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction, err := client.CreatePrediction(ctx, "owner/llm", nil, nil, true)
+	require.NoError(t, err)
+
+	server.InjectFault(replicatetest.TruncatedStream())
+	events, errs := client.StreamPrediction(ctx, prediction)
+
+	var output string
+	for event := range events {
+		if event.Type == replicate.SSETypeDone {
+			break
+		}
+		output += event.Data
+	}
+	select {
+	case err := <-errs:
+		require.NoError(t, err)
+	default:
+	}
+	assert.Equal(t, "abc", output)
+	assert.EqualValues(t, 1, client.StreamStats().Reconnects)
+}
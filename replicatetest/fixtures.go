@@ -0,0 +1,163 @@
+package replicatetest
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/replicate/replicate-go"
+)
+
+// Kinds of API objects with fixtures.
+const (
+	FixturePrediction   = "prediction"
+	FixtureTraining     = "training"
+	FixtureModel        = "model"
+	FixtureModelVersion = "model_version"
+	FixtureDeployment   = "deployment"
+	FixtureFile         = "file"
+	FixtureCollection   = "collection"
+	FixtureAccount      = "account"
+	FixtureHardware     = "hardware"
+)
+
+//go:embed fixtures
+var fixtureFS embed.FS
+
+// fixtureTypes returns a new value of the client's type for each kind.
+var fixtureTypes = map[string]func() any{
+	FixturePrediction:   func() any { return &replicate.Prediction{} },
+	FixtureTraining:     func() any { return &replicate.Training{} },
+	FixtureModel:        func() any { return &replicate.Model{} },
+	FixtureModelVersion: func() any { return &replicate.ModelVersion{} },
+	FixtureDeployment:   func() any { return &replicate.Deployment{} },
+	FixtureFile:         func() any { return &replicate.File{} },
+	FixtureCollection:   func() any { return &replicate.Collection{} },
+	FixtureAccount:      func() any { return &replicate.Account{} },
+	FixtureHardware:     func() any { return &replicate.Hardware{} },
+}
+
+// Fixture is an API response body that the client is tested against.
+type Fixture struct {
+	// Kind is the kind of object, such as FixturePrediction.
+	Kind string
+
+	// Name describes the object, such as "succeeded".
+	Name string
+
+	// Data is the JSON of the object.
+	Data []byte
+}
+
+// Fixtures returns the fixtures of the given kind, sorted by name. The
+// fixtures are updated as the API evolves, so tests that use them catch
+// incompatible changes when the module is upgraded.
+func Fixtures(kind string) []Fixture {
+	entries, err := fixtureFS.ReadDir(path.Join("fixtures", kind))
+	if err != nil {
+		return nil
+	}
+
+	fixtures := make([]Fixture, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		data, err := fixtureFS.ReadFile(path.Join("fixtures", kind, entry.Name()))
+		if err != nil {
+			continue
+		}
+		fixtures = append(fixtures, Fixture{Kind: kind, Name: name, Data: data})
+	}
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].Name < fixtures[j].Name })
+	return fixtures
+}
+
+// FixtureKinds returns the kinds of objects with fixtures.
+func FixtureKinds() []string {
+	kinds := make([]string, 0, len(fixtureTypes))
+	for kind := range fixtureTypes {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// ValidateDecoder decodes each fixture of the given kind with decode, and
+// fails the test for each fixture it returns an error for. Use it to check
+// that a custom decoder accepts everything the API returns.
+func ValidateDecoder(t testing.TB, kind string, decode func(data []byte) error) {
+	t.Helper()
+
+	fixtures := Fixtures(kind)
+	if len(fixtures) == 0 {
+		t.Fatalf("replicatetest: no fixtures of kind %q", kind)
+	}
+	for _, fixture := range fixtures {
+		if err := decode(fixture.Data); err != nil {
+			t.Errorf("failed to decode %s fixture %q: %v", kind, fixture.Name, err)
+		}
+	}
+}
+
+// ValidateRoundTrip unmarshals each fixture of the given kind into the value
+// returned by newValue, marshals it again, and fails the test if the result
+// doesn't decode to the same object as the fixture.
+//
+// Use it to check that a custom type, such as a prediction with typed
+// output, keeps every field the client knows about. Fields the client
+// doesn't model are ignored.
+func ValidateRoundTrip(t testing.TB, kind string, newValue func() any) {
+	t.Helper()
+
+	newReference, ok := fixtureTypes[kind]
+	if !ok {
+		t.Fatalf("replicatetest: unknown fixture kind %q", kind)
+	}
+
+	for _, fixture := range Fixtures(kind) {
+		value := newValue()
+		if err := json.Unmarshal(fixture.Data, value); err != nil {
+			t.Errorf("failed to decode %s fixture %q: %v", kind, fixture.Name, err)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			t.Errorf("failed to encode %s fixture %q: %v", kind, fixture.Name, err)
+			continue
+		}
+
+		want, err := canonicalJSON(fixture.Data, newReference())
+		if err != nil {
+			t.Errorf("failed to decode %s fixture %q: %v", kind, fixture.Name, err)
+			continue
+		}
+		got, err := canonicalJSON(encoded, newReference())
+		if err != nil {
+			t.Errorf("failed to decode re-encoded %s fixture %q: %v", kind, fixture.Name, err)
+			continue
+		}
+		if !bytes.Equal(want, got) {
+			t.Errorf("%s fixture %q changed in a round trip:\nwant: %s\n got: %s", kind, fixture.Name, want, got)
+		}
+	}
+}
+
+// canonicalJSON decodes data into v and encodes it again, dropping fields v
+// doesn't have.
+func canonicalJSON(data []byte, v any) ([]byte, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode: %w", err)
+	}
+	return encoded, nil
+}
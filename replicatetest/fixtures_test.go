@@ -0,0 +1,85 @@
+package replicatetest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestFixturesDecode(t *testing.T) {
+	for _, kind := range replicatetest.FixtureKinds() {
+		fixtures := replicatetest.Fixtures(kind)
+		require.NotEmpty(t, fixtures, kind)
+		for _, fixture := range fixtures {
+			assert.True(t, json.Valid(fixture.Data), "%s/%s", kind, fixture.Name)
+		}
+	}
+
+	replicatetest.ValidateDecoder(t, replicatetest.FixturePrediction, func(data []byte) error {
+		var p replicate.Prediction
+		if err := json.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		if p.ID == "" || p.Status == "" {
+			return errors.New("missing id or status")
+		}
+		return nil
+	})
+}
+
+// typedPrediction is a prediction whose output is decoded to a concrete type.
+type typedPrediction struct {
+	replicate.Prediction
+	TypedOutput json.RawMessage `json:"-"`
+}
+
+func (p *typedPrediction) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &p.Prediction); err != nil {
+		return err
+	}
+	var fields struct {
+		Output json.RawMessage `json:"output"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	p.TypedOutput = fields.Output
+	return nil
+}
+
+func (p typedPrediction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Prediction)
+}
+
+func TestValidateRoundTrip(t *testing.T) {
+	replicatetest.ValidateRoundTrip(t, replicatetest.FixturePrediction, func() any { return &typedPrediction{} })
+	replicatetest.ValidateRoundTrip(t, replicatetest.FixtureModel, func() any { return &replicate.Model{} })
+
+	// A type that drops a field the client knows about fails validation.
+	type lossyPrediction struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	rec := &recordingT{TB: t}
+	replicatetest.ValidateRoundTrip(rec, replicatetest.FixturePrediction, func() any { return &lossyPrediction{} })
+	assert.Len(t, rec.errors, len(replicatetest.Fixtures(replicatetest.FixturePrediction)))
+}
+
+// recordingT records errors instead of failing the test.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
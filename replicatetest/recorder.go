@@ -0,0 +1,355 @@
+package replicatetest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const scrubbed = "[SCRUBBED]"
+
+// webhookSecretPattern matches webhook signing secrets, such as the one
+// returned by GetDefaultWebhookSecret.
+var webhookSecretPattern = regexp.MustCompile(`whsec_[a-zA-Z0-9+/=]+`)
+
+// sensitiveHeaders are replaced with a placeholder in fixtures.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// RecorderMode selects whether a Recorder records or replays interactions.
+type RecorderMode int
+
+const (
+	// ModeReplay serves responses from the fixture file and fails requests
+	// that don't match a recorded interaction.
+	ModeReplay RecorderMode = iota
+
+	// ModeRecord sends requests to the real API and records them, for Save
+	// to write to the fixture file.
+	ModeRecord
+)
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request in a fixture file.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response in a fixture file.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper that records API interactions to a
+// fixture file and replays them, so tests can run against real responses
+// without network access.
+//
+// Requests match a recorded interaction with the same method, path, query,
+// and body, with JSON bodies compared by value. Each interaction is replayed
+// once, in the order it was recorded. Credentials are scrubbed from
+// recorded headers, and the API token and webhook signing secrets are
+// scrubbed wherever they appear.
+type Recorder struct {
+	path string
+	mode RecorderMode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+	tokens       []string
+}
+
+// NewRecorder returns a recorder for the fixture file at path. In replay
+// mode the file is loaded immediately. In record mode, requests are sent
+// with next, or http.DefaultTransport if next is nil.
+func NewRecorder(path string, mode RecorderMode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{path: path, mode: mode, next: next}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+		var f fixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+		}
+		r.interactions = f.Interactions
+		r.used = make([]bool, len(f.Interactions))
+	}
+
+	return r, nil
+}
+
+// Client returns an HTTP client that uses the recorder, for use with
+// replicate.WithHTTPClient.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip records or replays a request.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.used[i] || !matches(interaction.Request, req, body) {
+			continue
+		}
+		r.used[i] = true
+
+		recorded := interaction.Response
+		header := recorded.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction matches %s %s", req.Method, req.URL.RequestURI())
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readResponseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token := bearerToken(req.Header.Get("Authorization")); token != "" {
+		r.addToken(token)
+	}
+	r.interactions = append(r.interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: scrubHeader(req.Header),
+			Body:   string(body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header),
+			Body:       string(respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+func (r *Recorder) addToken(token string) {
+	for _, t := range r.tokens {
+		if t == token {
+			return
+		}
+	}
+	r.tokens = append(r.tokens, token)
+}
+
+// Save writes the recorded interactions to the fixture file, scrubbing the
+// API tokens used to make them and any webhook signing secrets. It does
+// nothing in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(fixture{Interactions: r.interactions}, "", "  ")
+	tokens := r.tokens
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+
+	for _, token := range tokens {
+		data = bytes.ReplaceAll(data, []byte(token), []byte(scrubbed))
+	}
+	data = webhookSecretPattern.ReplaceAll(data, []byte("whsec_"+scrubbed))
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// Unused returns the recorded interactions that haven't been replayed, which
+// usually means the code under test made fewer requests than expected.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for i, interaction := range r.interactions {
+		if i < len(r.used) && !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	var body io.ReadCloser
+	if req.GetBody != nil {
+		b, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		body = b
+	} else {
+		// The body can only be read once, so put a copy back for the real
+		// transport.
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		return data, nil
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return data, nil
+}
+
+// readResponseBody reads and closes the body of a response. Compressed
+// bodies are decompressed, so fixtures are readable.
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+
+	var body io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		body = zr
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return data, nil
+}
+
+func matches(recorded RecordedRequest, req *http.Request, body []byte) bool {
+	if recorded.Method != req.Method {
+		return false
+	}
+
+	recordedURL, err := req.URL.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	if recordedURL.Path != req.URL.Path || recordedURL.Query().Encode() != req.URL.Query().Encode() {
+		return false
+	}
+
+	return equalBodies([]byte(recorded.Body), body)
+}
+
+// equalBodies compares JSON bodies by value, and other bodies byte by byte.
+func equalBodies(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	var av, bv any
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	aj, errA := json.Marshal(av)
+	bj, errB := json.Marshal(bv)
+	return errA == nil && errB == nil && bytes.Equal(aj, bj)
+}
+
+func scrubHeader(header http.Header) http.Header {
+	scrubbedHeader := header.Clone()
+	for _, key := range sensitiveHeaders {
+		if _, ok := scrubbedHeader[key]; ok {
+			scrubbedHeader.Set(key, scrubbed)
+		}
+	}
+	return scrubbedHeader
+}
+
+func bearerToken(authorization string) string {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return token
+}
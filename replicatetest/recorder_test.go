@@ -0,0 +1,102 @@
+package replicatetest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures", "predictions.json")
+	ctx := context.Background()
+	input := replicate.PredictionInput{"prompt": "a cat", "steps": 20}
+
+	server := replicatetest.NewServer()
+	server.Script("owner/model", replicatetest.PredictionScript{Output: "meow"})
+
+	recorder, err := replicatetest.NewRecorder(path, replicatetest.ModeRecord, nil)
+	require.NoError(t, err)
+	client, err := replicate.NewClient(
+		replicate.WithToken("r8_secret-token"),
+		replicate.WithBaseURL(server.URL),
+		replicate.WithHTTPClient(recorder.Client()),
+	)
+	require.NoError(t, err)
+
+	created, err := client.CreatePrediction(ctx, "owner/model", input, nil, false)
+	require.NoError(t, err)
+	_, err = client.GetPrediction(ctx, created.ID)
+	require.NoError(t, err)
+	completed, err := client.GetPrediction(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "meow", completed.Output)
+
+	require.NoError(t, recorder.Save())
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "r8_secret-token")
+
+	replayer, err := replicatetest.NewRecorder(path, replicatetest.ModeReplay, nil)
+	require.NoError(t, err)
+	client, err = replicate.NewClient(
+		replicate.WithToken("another-token"),
+		replicate.WithBaseURL("http://replicate.invalid"),
+		replicate.WithHTTPClient(replayer.Client()),
+	)
+	require.NoError(t, err)
+
+	// Keys are sent in a different order, but the bodies match as JSON.
+	replayed, err := client.CreatePrediction(ctx, "owner/model", replicate.PredictionInput{"steps": 20, "prompt": "a cat"}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, replayed.ID)
+
+	_, err = client.GetPrediction(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Len(t, replayer.Unused(), 1)
+
+	replayedCompleted, err := client.GetPrediction(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "meow", replayedCompleted.Output)
+	assert.Empty(t, replayer.Unused())
+
+	_, err = client.GetPrediction(ctx, created.ID)
+	assert.ErrorContains(t, err, "no recorded interaction matches")
+}
+
+func TestRecorderScrubsWebhookSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"key": "whsec_5WbX5kEWLlfzsGNjH64I8lOOqUB6e8FH"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "webhook.json")
+	recorder, err := replicatetest.NewRecorder(path, replicatetest.ModeRecord, nil)
+	require.NoError(t, err)
+	client, err := replicate.NewClient(
+		replicate.WithToken("r8_secret-token"),
+		replicate.WithBaseURL(server.URL),
+		replicate.WithHTTPClient(recorder.Client()),
+	)
+	require.NoError(t, err)
+
+	secret, err := client.GetDefaultWebhookSecret(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "whsec_5WbX5kEWLlfzsGNjH64I8lOOqUB6e8FH", secret.Key)
+	require.NoError(t, recorder.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "5WbX5kEWLlfzsGNjH64I8lOOqUB6e8FH")
+	assert.Contains(t, string(data), "whsec_[SCRUBBED]")
+}
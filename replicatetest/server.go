@@ -0,0 +1,597 @@
+package replicatetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+const (
+	defaultServerPageSize = 100
+
+	// defaultWebhookSecretKey is the signing key of servers created without
+	// WithWebhookSecret. It isn't secret.
+	defaultWebhookSecretKey = "whsec_C2FVsBQIhrscChlQIMV+b5sSYspob7oD"
+)
+
+// PredictionScript describes how the predictions of a model progress on a
+// fake Server.
+type PredictionScript struct {
+	// Statuses are the statuses a prediction moves through after it's
+	// created with status "starting", one each time it's fetched or
+	// advanced. The default is "processing" then "succeeded". If the last
+	// status isn't terminal, the prediction stays in it.
+	Statuses []replicate.Status
+
+	// Output is set on the prediction once it succeeds.
+	Output replicate.PredictionOutput
+
+	// Logs are set on the prediction once it starts processing.
+	Logs string
+
+	// Error is set on the prediction if it fails.
+	Error string
+
+	// Metrics are set on the prediction once it terminates.
+	Metrics *replicate.PredictionMetrics
+
+	// CreateError, if set, makes creating a prediction fail with this
+	// error, as the API does for invalid inputs or unknown models.
+	CreateError *replicate.APIError
+}
+
+func (s PredictionScript) statuses() []replicate.Status {
+	if len(s.Statuses) == 0 {
+		return []replicate.Status{replicate.Processing, replicate.Succeeded}
+	}
+	return s.Statuses
+}
+
+// ServerOption is a function that modifies a Server.
+type ServerOption func(*Server)
+
+// WithDefaultScript sets the script of models without one set with
+// Server.Script.
+func WithDefaultScript(script PredictionScript) ServerOption {
+	return func(s *Server) {
+		s.defaultScript = script
+	}
+}
+
+// WithServerPageSize sets how many items the server returns per page of a
+// list. The default is 100.
+func WithServerPageSize(n int) ServerOption {
+	return func(s *Server) {
+		s.pageSize = n
+	}
+}
+
+// WithWebhookSecret sets the secret the server signs webhooks with.
+func WithWebhookSecret(secret replicate.WebhookSigningSecret) ServerOption {
+	return func(s *Server) {
+		s.secret = secret
+	}
+}
+
+// Server is an in-process fake of the Replicate API, for tests that exercise
+// a client without network access.
+//
+// The server supports creating, getting, listing, canceling, and streaming
+// predictions. Predictions progress through the statuses of their model's
+// PredictionScript each time they're fetched, or when advanced with
+// Server.Advance, and webhooks are delivered as the real API does.
+type Server struct {
+	// URL is the base URL of the server, for use with replicate.WithBaseURL.
+	URL string
+
+	server *httptest.Server
+
+	mu            sync.Mutex
+	secret        replicate.WebhookSigningSecret
+	defaultScript PredictionScript
+	scripts       map[string]PredictionScript
+	pageSize      int
+	nextID        int
+	predictions   []*fakePrediction
+	byID          map[string]*fakePrediction
+	faults        []*Fault
+}
+
+type fakePrediction struct {
+	prediction replicate.Prediction
+	script     PredictionScript
+	step       int
+	webhook    string
+	events     []replicate.WebhookEventType
+}
+
+// NewServer starts a fake Replicate API server. Close it when done.
+func NewServer(opts ...ServerOption) *Server {
+	s := &Server{
+		secret:   replicate.WebhookSigningSecret{Key: defaultWebhookSecretKey},
+		scripts:  make(map[string]PredictionScript),
+		pageSize: defaultServerPageSize,
+		byID:     make(map[string]*fakePrediction),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a client for the server. opts are applied after the options
+// that point the client at the server.
+func (s *Server) Client(opts ...replicate.ClientOption) (*replicate.Client, error) {
+	opts = append([]replicate.ClientOption{
+		replicate.WithToken("r8_fake-token"),
+		replicate.WithBaseURL(s.URL),
+	}, opts...)
+	return replicate.NewClient(opts...)
+}
+
+// WebhookSecret returns the secret the server signs webhooks with.
+func (s *Server) WebhookSecret() replicate.WebhookSigningSecret {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.secret
+}
+
+// Script sets the script for predictions of a model. identifier is a model
+// ("owner/name"), a version ID, or a deployment ("owner/name"), matching how
+// the prediction is created.
+func (s *Server) Script(identifier string, script PredictionScript) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts[identifier] = script
+}
+
+// Prediction returns the current state of a prediction.
+func (s *Server) Prediction(id string) (*replicate.Prediction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.byID[id]
+	if !ok {
+		return nil, false
+	}
+	prediction := p.prediction
+	return &prediction, true
+}
+
+// Predictions returns all predictions created on the server, oldest first.
+func (s *Server) Predictions() []*replicate.Prediction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	predictions := make([]*replicate.Prediction, len(s.predictions))
+	for i, p := range s.predictions {
+		prediction := p.prediction
+		predictions[i] = &prediction
+	}
+	return predictions
+}
+
+// Advance moves a prediction to the next status in its script, delivering
+// any webhooks, and returns its new state.
+func (s *Server) Advance(ctx context.Context, id string) (*replicate.Prediction, error) {
+	s.mu.Lock()
+	p, ok := s.byID[id]
+	if !ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("prediction %s not found", id)
+	}
+	delivery := s.advanceLocked(p)
+	prediction := p.prediction
+	s.mu.Unlock()
+
+	if delivery != nil {
+		if err := s.deliver(ctx, delivery); err != nil {
+			return &prediction, err
+		}
+	}
+	return &prediction, nil
+}
+
+// webhookDelivery is a webhook to send once the server's lock is released.
+type webhookDelivery struct {
+	url        string
+	prediction replicate.Prediction
+}
+
+// advanceLocked moves p to its next status and returns the webhook to
+// deliver for the transition, if any.
+func (s *Server) advanceLocked(p *fakePrediction) *webhookDelivery {
+	if p.prediction.Status.Terminated() {
+		return nil
+	}
+	statuses := p.script.statuses()
+	if p.step >= len(statuses) {
+		return nil
+	}
+
+	status := statuses[p.step]
+	p.step++
+	s.setStatusLocked(p, status)
+
+	return s.webhookFor(p, eventFor(status))
+}
+
+func (s *Server) setStatusLocked(p *fakePrediction, status replicate.Status) {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	prediction := &p.prediction
+	prediction.Status = status
+
+	if status != replicate.Starting && prediction.StartedAt == nil {
+		prediction.StartedAt = &now
+	}
+	if status == replicate.Processing && p.script.Logs != "" {
+		logs := p.script.Logs
+		prediction.Logs = &logs
+	}
+	if status.Terminated() {
+		prediction.CompletedAt = &now
+		prediction.Metrics = p.script.Metrics
+		switch status {
+		case replicate.Succeeded:
+			prediction.Output = p.script.Output
+		case replicate.Failed:
+			prediction.Error = p.script.Error
+		case replicate.Starting, replicate.Processing, replicate.Canceled:
+		}
+	}
+}
+
+func eventFor(status replicate.Status) replicate.WebhookEventType {
+	switch status {
+	case replicate.Starting:
+		return replicate.WebhookEventStart
+	case replicate.Processing:
+		return replicate.WebhookEventLogs
+	case replicate.Succeeded, replicate.Failed, replicate.Canceled:
+		return replicate.WebhookEventCompleted
+	}
+	return replicate.WebhookEventOutput
+}
+
+func (s *Server) webhookFor(p *fakePrediction, event replicate.WebhookEventType) *webhookDelivery {
+	if p.webhook == "" {
+		return nil
+	}
+	if len(p.events) > 0 {
+		wanted := false
+		for _, e := range p.events {
+			if e == event {
+				wanted = true
+			}
+		}
+		if !wanted {
+			return nil
+		}
+	}
+	return &webhookDelivery{url: p.webhook, prediction: p.prediction}
+}
+
+func (s *Server) deliver(ctx context.Context, d *webhookDelivery) error {
+	payload, err := json.Marshal(&d.prediction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prediction: %w", err)
+	}
+	return sendWebhook(ctx, d.url, s.WebhookSecret(), payload)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if fault := s.takeFault(r); fault != nil {
+		s.serveFault(w, r, fault)
+		return
+	}
+	s.route(w, r)
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	route := func(method string, pattern ...string) bool {
+		if r.Method != method || len(segments) != len(pattern) {
+			return false
+		}
+		for i, p := range pattern {
+			if p != "*" && p != segments[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case route(http.MethodPost, "predictions"):
+		s.createPrediction(w, r, "")
+	case route(http.MethodPost, "models", "*", "*", "predictions"),
+		route(http.MethodPost, "deployments", "*", "*", "predictions"):
+		s.createPrediction(w, r, segments[1]+"/"+segments[2])
+	case route(http.MethodGet, "predictions"):
+		s.listPredictions(w, r)
+	case route(http.MethodGet, "predictions", "*"):
+		s.getPrediction(w, r, segments[1])
+	case route(http.MethodPost, "predictions", "*", "cancel"):
+		s.cancelPrediction(w, r, segments[1])
+	case route(http.MethodGet, "stream", "*"):
+		s.streamPrediction(w, r, segments[1])
+	case route(http.MethodGet, "webhooks", "default", "secret"):
+		writeJSON(w, http.StatusOK, s.WebhookSecret())
+	default:
+		writeError(w, &replicate.APIError{Status: http.StatusNotFound, Detail: "Not found."})
+	}
+}
+
+type createPredictionRequest struct {
+	Version             string                       `json:"version"`
+	Input               replicate.PredictionInput    `json:"input"`
+	Webhook             string                       `json:"webhook"`
+	WebhookEventsFilter []replicate.WebhookEventType `json:"webhook_events_filter"`
+	Stream              bool                         `json:"stream"`
+}
+
+func (s *Server) createPrediction(w http.ResponseWriter, r *http.Request, model string) {
+	var body createPredictionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, &replicate.APIError{Status: http.StatusBadRequest, Detail: "Invalid JSON: " + err.Error()})
+		return
+	}
+	if model == "" && body.Version == "" {
+		writeError(w, &replicate.APIError{
+			Status: http.StatusUnprocessableEntity,
+			Detail: "version is required",
+			InvalidFields: []replicate.InvalidField{
+				{Type: "required", Field: "version", Description: "version is required"},
+			},
+		})
+		return
+	}
+
+	s.mu.Lock()
+	identifier := model
+	if identifier == "" {
+		identifier = body.Version
+	}
+	script, ok := s.scripts[identifier]
+	if !ok {
+		script = s.defaultScript
+	}
+	if script.CreateError != nil {
+		s.mu.Unlock()
+		writeError(w, script.CreateError)
+		return
+	}
+
+	s.nextID++
+	id := fmt.Sprintf("fake%022d", s.nextID)
+	baseURL := "http://" + r.Host
+	p := &fakePrediction{
+		prediction: replicate.Prediction{
+			ID:        id,
+			Status:    replicate.Starting,
+			Model:     model,
+			Version:   body.Version,
+			Input:     body.Input,
+			Source:    replicate.SourceAPI,
+			CreatedAt: time.Now().UTC().Format(time.RFC3339Nano),
+			URLs: map[string]string{
+				"get":    baseURL + "/predictions/" + id,
+				"cancel": baseURL + "/predictions/" + id + "/cancel",
+			},
+		},
+		script:  script,
+		webhook: body.Webhook,
+		events:  body.WebhookEventsFilter,
+	}
+	if body.Stream {
+		p.prediction.URLs["stream"] = baseURL + "/stream/" + id
+	}
+	if body.Webhook != "" {
+		webhook := body.Webhook
+		p.prediction.Webhook = &webhook
+		p.prediction.WebhookEventsFilter = body.WebhookEventsFilter
+	}
+	s.predictions = append(s.predictions, p)
+	s.byID[id] = p
+	delivery := s.webhookFor(p, replicate.WebhookEventStart)
+	prediction := p.prediction
+	s.mu.Unlock()
+
+	if delivery != nil {
+		_ = s.deliver(r.Context(), delivery)
+	}
+	writeJSON(w, http.StatusCreated, &prediction)
+}
+
+func (s *Server) getPrediction(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	p, ok := s.byID[id]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, &replicate.APIError{Status: http.StatusNotFound, Detail: "Not found."})
+		return
+	}
+	delivery := s.advanceLocked(p)
+	prediction := p.prediction
+	s.mu.Unlock()
+
+	if delivery != nil {
+		_ = s.deliver(r.Context(), delivery)
+	}
+	writeJSON(w, http.StatusOK, &prediction)
+}
+
+func (s *Server) cancelPrediction(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	p, ok := s.byID[id]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, &replicate.APIError{Status: http.StatusNotFound, Detail: "Not found."})
+		return
+	}
+	var delivery *webhookDelivery
+	if !p.prediction.Status.Terminated() {
+		s.setStatusLocked(p, replicate.Canceled)
+		delivery = s.webhookFor(p, replicate.WebhookEventCompleted)
+	}
+	prediction := p.prediction
+	s.mu.Unlock()
+
+	if delivery != nil {
+		_ = s.deliver(r.Context(), delivery)
+	}
+	writeJSON(w, http.StatusOK, &prediction)
+}
+
+func (s *Server) listPredictions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	pageSize := s.pageSize
+	if n, err := strconv.Atoi(r.URL.Query().Get("page_size")); err == nil && n > 0 {
+		pageSize = n
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Like the API, list the newest predictions first.
+	var results []replicate.Prediction
+	for i := len(s.predictions) - 1 - offset; i >= 0 && len(results) < pageSize; i-- {
+		results = append(results, s.predictions[i].prediction)
+	}
+	total := len(s.predictions)
+	s.mu.Unlock()
+
+	page := replicate.Page[replicate.Prediction]{Results: results}
+	if results == nil {
+		page.Results = []replicate.Prediction{}
+	}
+	if next := offset + pageSize; next < total {
+		u := pageURL(r, next)
+		page.Next = &u
+	}
+	if offset > 0 {
+		previous := offset - pageSize
+		if previous < 0 {
+			previous = 0
+		}
+		u := pageURL(r, previous)
+		page.Previous = &u
+	}
+	writeJSON(w, http.StatusOK, &page)
+}
+
+func pageURL(r *http.Request, cursor int) string {
+	query := r.URL.Query()
+	query.Set("cursor", strconv.Itoa(cursor))
+	return "http://" + r.Host + r.URL.Path + "?" + query.Encode()
+}
+
+// streamPrediction sends the prediction's output as server-sent events,
+// advancing it to completion.
+func (s *Server) streamPrediction(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	p, ok := s.byID[id]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, &replicate.APIError{Status: http.StatusNotFound, Detail: "Not found."})
+		return
+	}
+	var deliveries []*webhookDelivery
+	for !p.prediction.Status.Terminated() && p.step < len(p.script.statuses()) {
+		if d := s.advanceLocked(p); d != nil {
+			deliveries = append(deliveries, d)
+		}
+	}
+	prediction := p.prediction
+	s.mu.Unlock()
+
+	for _, d := range deliveries {
+		_ = s.deliver(r.Context(), d)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	// Resume after the last event the client received, as the API does.
+	lastEventID, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+	event := 0
+	writeEvent := func(eventType, data string) {
+		event++
+		if event <= lastEventID {
+			return
+		}
+		fmt.Fprintf(w, "id: %d\nevent: %s\n", event, eventType)
+		for _, line := range strings.Split(data, "\n") {
+			fmt.Fprintf(w, "data: %s\n", line)
+		}
+		fmt.Fprint(w, "\n")
+	}
+
+	switch prediction.Status {
+	case replicate.Succeeded:
+		for _, chunk := range outputChunks(prediction.Output) {
+			writeEvent(replicate.SSETypeOutput, chunk)
+		}
+	case replicate.Failed:
+		writeEvent(replicate.SSETypeError, fmt.Sprint(prediction.Error))
+	case replicate.Starting, replicate.Processing, replicate.Canceled:
+	}
+	writeEvent(replicate.SSETypeDone, "{}")
+}
+
+// outputChunks splits output into the events a streaming model would send.
+func outputChunks(output replicate.PredictionOutput) []string {
+	switch v := output.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		chunks := make([]string, len(v))
+		for i, item := range v {
+			chunks[i] = fmt.Sprint(item)
+		}
+		return chunks
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, apiError *replicate.APIError) {
+	status := apiError.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	body := *apiError
+	body.Status = status
+	if body.Title == "" {
+		body.Title = http.StatusText(status)
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&body)
+}
@@ -0,0 +1,151 @@
+package replicatetest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestServerPredictionLifecycle(t *testing.T) {
+	server := replicatetest.NewServer()
+	defer server.Close()
+
+	server.Script("owner/model", replicatetest.PredictionScript{
+		Statuses: []replicate.Status{replicate.Processing, replicate.Processing, replicate.Succeeded},
+		Output:   "hello",
+		Logs:     "working",
+	})
+
+	client, err := server.Client()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	prediction, err := client.CreatePrediction(ctx, "owner/model", replicate.PredictionInput{"prompt": "hi"}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, replicate.Starting, prediction.Status)
+	assert.Equal(t, "owner/model", prediction.Model)
+
+	err = client.Wait(ctx, prediction, replicate.WithPollingInterval(time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+	assert.Equal(t, "hello", prediction.Output)
+	require.NotNil(t, prediction.Logs)
+	assert.Equal(t, "working", *prediction.Logs)
+	assert.NotNil(t, prediction.CompletedAt)
+}
+
+func TestServerErrors(t *testing.T) {
+	server := replicatetest.NewServer()
+	defer server.Close()
+
+	server.Script("owner/broken", replicatetest.PredictionScript{
+		Statuses: []replicate.Status{replicate.Failed},
+		Error:    "out of memory",
+	})
+	server.Script("owner/missing", replicatetest.PredictionScript{
+		CreateError: &replicate.APIError{Status: http.StatusNotFound, Detail: "model not found"},
+	})
+
+	client, err := server.Client()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.CreatePrediction(ctx, "owner/missing", nil, nil, false)
+	assert.ErrorIs(t, err, replicate.ErrNotFound)
+
+	_, err = client.GetPrediction(ctx, "nonexistent")
+	assert.ErrorIs(t, err, replicate.ErrNotFound)
+
+	prediction, err := client.CreatePrediction(ctx, "owner/broken", nil, nil, false)
+	require.NoError(t, err)
+	err = client.Wait(ctx, prediction, replicate.WithPollingInterval(time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, replicate.Failed, prediction.Status)
+	assert.Equal(t, "out of memory", prediction.Error)
+}
+
+func TestServerPagination(t *testing.T) {
+	server := replicatetest.NewServer(replicatetest.WithServerPageSize(2))
+	defer server.Close()
+
+	client, err := server.Client()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := client.CreatePrediction(ctx, "owner/model", nil, nil, false)
+		require.NoError(t, err)
+	}
+
+	page, err := client.ListPredictions(ctx)
+	require.NoError(t, err)
+	all, err := replicate.AllPages(ctx, client, page)
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+
+	created := server.Predictions()
+	assert.Equal(t, created[4].ID, all[0].ID, "newest first")
+	assert.Equal(t, created[0].ID, all[4].ID)
+}
+
+func TestServerWebhooks(t *testing.T) {
+	server := replicatetest.NewServer()
+	defer server.Close()
+
+	bridge := replicate.NewWebhookBridge(server.WebhookSecret())
+	defer bridge.Close()
+	receiver := httptest.NewServer(bridge)
+	defer receiver.Close()
+
+	client, err := server.Client()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	webhook := &replicate.Webhook{URL: receiver.URL, Events: []replicate.WebhookEventType{replicate.WebhookEventCompleted}}
+	prediction, err := client.CreatePrediction(ctx, "owner/model", nil, webhook, false)
+	require.NoError(t, err)
+	events := bridge.Await(prediction.ID)
+
+	_, err = server.Advance(ctx, prediction.ID)
+	require.NoError(t, err)
+	_, err = server.Advance(ctx, prediction.ID)
+	require.NoError(t, err)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, replicate.Succeeded, event.Prediction.Status)
+	case <-time.After(time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
+
+func TestServerStream(t *testing.T) {
+	server := replicatetest.NewServer()
+	defer server.Close()
+
+	server.Script("owner/llm", replicatetest.PredictionScript{Output: []string{"Hello", ", ", "world"}})
+
+	client, err := server.Client()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	prediction, err := client.CreatePrediction(ctx, "owner/llm", nil, nil, true)
+	require.NoError(t, err)
+
+	reader, err := client.StreamPredictionText(ctx, prediction)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	text, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, world", string(text))
+}
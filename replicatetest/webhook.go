@@ -0,0 +1,136 @@
+package replicatetest
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// NewWebhookRequest returns a POST request to target with payload as its
+// body, signed with secret the same way Replicate signs webhook deliveries.
+// The request passes replicate.ValidateWebhookRequest.
+func NewWebhookRequest(target string, secret replicate.WebhookSigningSecret, payload []byte) (*http.Request, error) {
+	return newWebhookRequest(context.Background(), target, secret, payload)
+}
+
+// SendCompletedWebhook delivers a signed webhook for prediction to target, as
+// if the prediction had just completed. Predictions that haven't terminated
+// are marked as succeeded.
+func SendCompletedWebhook(ctx context.Context, target string, secret replicate.WebhookSigningSecret, prediction *replicate.Prediction) error {
+	completed := *prediction
+	if !completed.Status.Terminated() {
+		completed.Status = replicate.Succeeded
+	}
+	if completed.CompletedAt == nil {
+		now := time.Now().UTC().Format(time.RFC3339Nano)
+		completed.CompletedAt = &now
+	}
+
+	payload, err := json.Marshal(&completed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prediction: %w", err)
+	}
+
+	return sendWebhook(ctx, target, secret, payload)
+}
+
+// sendWebhook delivers a signed webhook with payload to target.
+func sendWebhook(ctx context.Context, target string, secret replicate.WebhookSigningSecret, payload []byte) error {
+	req, err := newWebhookRequest(ctx, target, secret, payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook receiver responded with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func newWebhookRequest(ctx context.Context, target string, secret replicate.WebhookSigningSecret, payload []byte) (*http.Request, error) {
+	header, err := SignWebhook(secret, payload, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	return req, nil
+}
+
+// SignWebhook returns the headers Replicate sends with a webhook delivery of
+// payload, signed with secret at time t. A request with these headers and
+// payload as its body passes replicate.ValidateWebhookRequest.
+//
+// Each call uses a new random webhook ID, as separate deliveries do.
+func SignWebhook(secret replicate.WebhookSigningSecret, payload []byte, t time.Time) (http.Header, error) {
+	id, err := newMessageID()
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+
+	signature, err := sign(secret, id, timestamp, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set("Webhook-ID", id)
+	header.Set("Webhook-Timestamp", timestamp)
+	header.Set("Webhook-Signature", signature)
+	return header, nil
+}
+
+// sign computes the webhook-signature header value for a delivery.
+func sign(secret replicate.WebhookSigningSecret, id, timestamp string, payload []byte) (string, error) {
+	keyParts := strings.Split(secret.Key, "_")
+	if len(keyParts) != 2 {
+		return "", fmt.Errorf("invalid secret key format: %s", secret.Key)
+	}
+	key, err := base64.StdEncoding.DecodeString(keyParts[1])
+	if err != nil {
+		return "", fmt.Errorf("failed to base64 decode secret key: %w", err)
+	}
+
+	h := hmac.New(sha256.New, key)
+	fmt.Fprintf(h, "%s.%s.%s", id, timestamp, payload)
+
+	return "v1," + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+func newMessageID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate message ID: %w", err)
+	}
+	return "msg_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,97 @@
+package replicatetest_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+// This is a test secret and should not be used in production
+var testSecret = replicate.WebhookSigningSecret{
+	Key: "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw", // nolint:gosec
+}
+
+func TestNewWebhookRequest(t *testing.T) {
+	payload := []byte(`{"id": "abc", "status": "succeeded"}`)
+
+	req, err := replicatetest.NewWebhookRequest("http://test.host/webhook", testSecret, payload)
+	require.NoError(t, err)
+
+	valid, err := replicate.ValidateWebhookRequest(req, testSecret)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, payload, body)
+}
+
+func TestSignWebhook(t *testing.T) {
+	payload := []byte(`{"id": "abc", "status": "succeeded"}`)
+	signedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	header, err := replicatetest.SignWebhook(testSecret, payload, signedAt)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(signedAt.Unix(), 10), header.Get("Webhook-Timestamp"))
+	assert.NotEmpty(t, header.Get("Webhook-ID"))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	req.Header = header
+	valid, err := replicate.ValidateWebhookRequest(req, testSecret)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	tampered := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader([]byte(`{"id": "abc", "status": "failed"}`)))
+	tampered.Header = header
+	valid, err = replicate.ValidateWebhookRequest(tampered, testSecret)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	bridge := replicate.NewWebhookBridge(testSecret)
+	defer bridge.Close()
+	req = httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	req.Header = header
+	rec := httptest.NewRecorder()
+	bridge.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	_, err = replicatetest.SignWebhook(replicate.WebhookSigningSecret{Key: "invalid"}, payload, signedAt)
+	assert.ErrorContains(t, err, "invalid secret key format")
+}
+
+func TestSendCompletedWebhook(t *testing.T) {
+	bridge := replicate.NewWebhookBridge(testSecret)
+	defer bridge.Close()
+
+	ts := httptest.NewServer(bridge)
+	defer ts.Close()
+
+	ch := bridge.Await("abc")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction := &replicate.Prediction{ID: "abc", Status: replicate.Processing}
+	err := replicatetest.SendCompletedWebhook(ctx, ts.URL, testSecret, prediction)
+	require.NoError(t, err)
+
+	event := <-ch
+	assert.Equal(t, replicate.Succeeded, event.Prediction.Status)
+	assert.NotNil(t, event.Prediction.CompletedAt)
+	assert.Equal(t, replicate.Processing, prediction.Status)
+
+	err = replicatetest.SendCompletedWebhook(ctx, ts.URL, replicate.WebhookSigningSecret{Key: "whsec_d3Jvbmc="}, prediction)
+	assert.ErrorContains(t, err, "401")
+
+}
@@ -0,0 +1,211 @@
+package replicate
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// defaultResultCacheSize is the number of outputs a MemoryResultCache keeps
+// by default.
+const defaultResultCacheSize = 1000
+
+// maxResultCacheTTL caps how long outputs are cached. The files of
+// predictions created with the API, which outputs link to with
+// replicate.delivery URLs, are deleted after an hour.
+const maxResultCacheTTL = time.Hour
+
+// ResultCache stores the outputs of runs, encoded as JSON, so that repeating
+// a run returns the stored output instead of running the model again.
+// Implementations must be safe for concurrent use. Back it with a shared
+// store like Redis to share results between processes.
+type ResultCache interface {
+	// Get returns the value stored for key, and whether there was one.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value for key. The value should expire after ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// WithResultCache makes Run and RunWithOptions look up their output in
+// cache before running a model, and store the outputs of successful runs
+// for ttl.
+//
+// ttl is capped at one hour. Outputs link to their files with
+// replicate.delivery URLs, which stop working when the files are deleted an
+// hour after the prediction, so a longer TTL would return dead links.
+//
+// Only runs whose input sets a "seed" are cached by default, since other
+// runs of most models give a different output each time. Use
+// WithResultCaching to cache, or not cache, the runs made with a context.
+// Runs with a webhook are never cached.
+//
+// Runs are keyed by the identifier passed to Run, so runs of an
+// "owner/name" identifier keep returning outputs cached from the model's
+// previous version after a new one is pushed, until they expire. Pass an
+// "owner/name:version" identifier to tie outputs to a version.
+func WithResultCache(cache ResultCache, ttl time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if cache == nil {
+			return errors.New("result cache must not be nil")
+		}
+		if ttl <= 0 {
+			return fmt.Errorf("result cache TTL must be positive, got %s", ttl)
+		}
+		o.resultCache = cache
+		o.resultCacheTTL = min(ttl, maxResultCacheTTL)
+		return nil
+	}
+}
+
+type resultCachingContextKey struct{}
+
+// WithResultCaching returns a context that decides whether the runs made
+// with it use the client's result cache, regardless of their input. Enable
+// it for deterministic models without a seed, or disable it to force a
+// fresh output.
+func WithResultCaching(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, resultCachingContextKey{}, enabled)
+}
+
+// resultCacheKey returns the cache key of a run, or "" if it shouldn't be
+// cached.
+func (r *Client) resultCacheKey(ctx context.Context, identifier string, input PredictionInput, webhook *Webhook) string {
+	if r.options.resultCache == nil || webhook != nil {
+		return ""
+	}
+	enabled, ok := ctx.Value(resultCachingContextKey{}).(bool)
+	if !ok {
+		_, enabled = input["seed"]
+	}
+	if !enabled {
+		return ""
+	}
+
+	// Maps are marshaled with sorted keys, so equal inputs give equal keys.
+	data, err := json.Marshal(input)
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	h.Write([]byte(identifier))
+	h.Write([]byte{0})
+	h.Write(data)
+	return "replicate:run:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedOutput returns the cached output for key, if any. Cache errors are
+// logged and treated as misses.
+func (r *Client) cachedOutput(ctx context.Context, key string) (PredictionOutput, bool) {
+	data, ok, err := r.options.resultCache.Get(ctx, key)
+	if err != nil {
+		r.log(ctx, slog.LevelWarn, "failed to read replicate result cache", r.errorAttr(err))
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	var output PredictionOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		r.log(ctx, slog.LevelWarn, "failed to decode cached replicate result", r.errorAttr(err))
+		return nil, false
+	}
+	return output, true
+}
+
+func (r *Client) cacheOutput(ctx context.Context, key string, output PredictionOutput) {
+	data, err := json.Marshal(output)
+	if err == nil {
+		err = r.options.resultCache.Set(ctx, key, data, r.options.resultCacheTTL)
+	}
+	if err != nil {
+		r.log(ctx, slog.LevelWarn, "failed to write replicate result cache", r.errorAttr(err))
+	}
+}
+
+// MemoryResultCache is a ResultCache that keeps outputs in memory, evicting
+// the least recently used output when it's full.
+type MemoryResultCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+var _ ResultCache = (*MemoryResultCache)(nil)
+
+type memoryResultCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryResultCache returns a cache that keeps at most maxEntries
+// outputs. If maxEntries is zero or less, it keeps 1000.
+func NewMemoryResultCache(maxEntries int) *MemoryResultCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultResultCacheSize
+	}
+	return &MemoryResultCache{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored for key, unless it has expired.
+func (c *MemoryResultCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*memoryResultCacheEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.lru.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set stores value for key until ttl has passed.
+func (c *MemoryResultCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryResultCacheEntry{key: key, value: value, expiresAt: time.Now().Add(ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryResultCacheEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of outputs in the cache, including expired ones
+// that haven't been evicted yet.
+func (c *MemoryResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
@@ -0,0 +1,133 @@
+package replicate_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+type failingResultCache struct{}
+
+func (failingResultCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (failingResultCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+// ttlResultCache records the TTL outputs are stored with.
+type ttlResultCache struct {
+	*replicate.MemoryResultCache
+	ttl time.Duration
+}
+
+func (c *ttlResultCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.ttl = ttl
+	return c.MemoryResultCache.Set(ctx, key, value, ttl)
+}
+
+func TestResultCacheTTLCapped(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"id": "p1", "status": "succeeded", "output": "https://replicate.delivery/out.png"}`))
+	}))
+	defer mockServer.Close()
+
+	cache := &ttlResultCache{MemoryResultCache: replicate.NewMemoryResultCache(1)}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithResultCache(cache, 24*time.Hour),
+	)
+	require.NoError(t, err)
+
+	_, err = client.RunWithOptions(context.Background(), "owner/model:abc123", replicate.PredictionInput{"seed": 1}, nil, replicate.WithBlockUntilDone())
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, cache.ttl)
+}
+
+func TestResultCache(t *testing.T) {
+	var created atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		id := fmt.Sprintf("p%d", created.Add(1))
+		fmt.Fprintf(w, `{"id": %q, "status": "succeeded", "output": {"image": "out-%s"}}`, id, id)
+	}))
+	defer mockServer.Close()
+
+	cache := replicate.NewMemoryResultCache(2)
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithResultCache(cache, time.Hour),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	run := func(ctx context.Context, input replicate.PredictionInput) replicate.PredictionOutput {
+		output, err := client.RunWithOptions(ctx, "owner/model:abc123", input, nil, replicate.WithBlockUntilDone())
+		require.NoError(t, err)
+		return output
+	}
+
+	seeded := replicate.PredictionInput{"prompt": "a cat", "seed": 42}
+	first := run(ctx, seeded)
+	assert.Equal(t, map[string]any{"image": "out-p1"}, first)
+	assert.Equal(t, first, run(ctx, replicate.PredictionInput{"seed": 42, "prompt": "a cat"}))
+	assert.Equal(t, int32(1), created.Load())
+
+	// Runs without a seed aren't cached, unless the context says so.
+	run(ctx, replicate.PredictionInput{"prompt": "a cat"})
+	run(ctx, replicate.PredictionInput{"prompt": "a cat"})
+	assert.Equal(t, int32(3), created.Load())
+	forced := replicate.WithResultCaching(ctx, true)
+	run(forced, replicate.PredictionInput{"prompt": "a dog"})
+	run(forced, replicate.PredictionInput{"prompt": "a dog"})
+	assert.Equal(t, int32(4), created.Load())
+	run(replicate.WithResultCaching(ctx, false), seeded)
+	assert.Equal(t, int32(5), created.Load())
+
+	// The cache holds two outputs, so the first is evicted by the third.
+	run(ctx, replicate.PredictionInput{"prompt": "a bird", "seed": 1})
+	assert.Equal(t, 2, cache.Len())
+	run(ctx, seeded)
+	assert.Equal(t, int32(7), created.Load())
+
+	t.Run("cache errors", func(t *testing.T) {
+		client, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(mockServer.URL),
+			replicate.WithResultCache(failingResultCache{}, time.Hour),
+		)
+		require.NoError(t, err)
+		_, err = client.RunWithOptions(ctx, "owner/model:abc123", seeded, nil, replicate.WithBlockUntilDone())
+		assert.NoError(t, err)
+	})
+}
+
+func TestMemoryResultCacheTTL(t *testing.T) {
+	cache := replicate.NewMemoryResultCache(0)
+	ctx := context.Background()
+	require.NoError(t, cache.Set(ctx, "key", []byte(`"value"`), 10*time.Millisecond))
+
+	value, ok, err := cache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, `"value"`, string(value))
+
+	time.Sleep(20 * time.Millisecond)
+	_, ok, err = cache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
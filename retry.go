@@ -0,0 +1,177 @@
+package replicate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// isRetrySafe returns true if the request can be sent again without risking
+// duplicate side effects.
+func isRetrySafe(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, "QUERY":
+		return true
+	}
+
+	return request.Header.Get(idempotencyKeyHeader) != ""
+}
+
+// canRetry returns false if the outcome of a request rules out retrying it,
+// whatever the retry policy says: the caller gave up on it, or sending it
+// again could create duplicate side effects.
+//
+// Requests that were rate limited weren't processed, so they can always be
+// retried.
+func canRetry(request *http.Request, response *http.Response, err error) bool {
+	if request.Context().Err() != nil {
+		return false
+	}
+
+	if err == nil && response != nil && response.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return isRetrySafe(request)
+}
+
+// RetryPolicy decides whether and when a failed request is retried.
+//
+// ShouldRetry is called after each failed attempt with either the error
+// response, or the error if no response was received, and the zero-based
+// attempt number. It returns how long to wait before the next attempt, and
+// whether to make one.
+//
+// Requests that aren't safe to retry, like POST requests without an
+// idempotency key, are only offered to the policy after a 429 response.
+type RetryPolicy interface {
+	ShouldRetry(resp *http.Response, err error, attempt int) (time.Duration, bool)
+}
+
+// BackoffRetryPolicy retries rate limited requests, server errors, and
+// transient network errors up to MaxRetries times, waiting for the delay
+// given by Backoff or by the response's Retry-After header. Retry-After
+// delays are capped at one minute.
+type BackoffRetryPolicy struct {
+	MaxRetries int
+	Backoff    Backoff
+
+	// now returns the current time, to compute Retry-After dates against.
+	// It's set to the client's clock.
+	now func() time.Time
+}
+
+// maxRetryAfter caps the delay a response's Retry-After header can ask for,
+// so a single response can't block a call indefinitely.
+const maxRetryAfter = time.Minute
+
+var _ RetryPolicy = (*BackoffRetryPolicy)(nil)
+
+// ShouldRetry implements RetryPolicy.
+func (p *BackoffRetryPolicy) ShouldRetry(resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= p.MaxRetries {
+		return 0, false
+	}
+
+	if resp == nil {
+		return p.Backoff.NextDelay(attempt), isTransientError(err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode >= 500 && resp.StatusCode < 600) {
+		now := p.now
+		if now == nil {
+			now = time.Now
+		}
+		return retryDelay(resp, p.Backoff, attempt, now()), true
+	}
+
+	return 0, false
+}
+
+// NewExponentialRetryPolicy returns a policy that retries up to maxRetries
+// times with exponentially increasing delays.
+func NewExponentialRetryPolicy(maxRetries int, base time.Duration, multiplier float64, jitter time.Duration) *BackoffRetryPolicy {
+	return &BackoffRetryPolicy{
+		MaxRetries: maxRetries,
+		Backoff: &ExponentialBackoff{
+			Base:       base,
+			Multiplier: multiplier,
+			Jitter:     jitter,
+		},
+	}
+}
+
+// NewConstantRetryPolicy returns a policy that retries up to maxRetries
+// times with a constant delay.
+func NewConstantRetryPolicy(maxRetries int, delay time.Duration, jitter time.Duration) *BackoffRetryPolicy {
+	return &BackoffRetryPolicy{
+		MaxRetries: maxRetries,
+		Backoff: &ConstantBackoff{
+			Base:   delay,
+			Jitter: jitter,
+		},
+	}
+}
+
+// NoRetryPolicy never retries requests.
+var NoRetryPolicy RetryPolicy = noRetryPolicy{}
+
+type noRetryPolicy struct{}
+
+func (noRetryPolicy) ShouldRetry(_ *http.Response, _ error, _ int) (time.Duration, bool) {
+	return 0, false
+}
+
+func isTransientError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryDelay returns how long to wait before retrying, honoring the
+// response's Retry-After header when present, up to maxRetryAfter.
+func retryDelay(response *http.Response, backoff Backoff, attempt int, now time.Time) time.Duration {
+	retryAfter := response.Header.Get("Retry-After")
+	if retryAfter == "" {
+		return backoff.NextDelay(attempt)
+	}
+
+	var delay time.Duration
+	if parsedDelay, parseErr := time.Parse(time.RFC1123, retryAfter); parseErr == nil {
+		delay = parsedDelay.Sub(now)
+	} else if seconds, convErr := strconv.Atoi(retryAfter); convErr == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else {
+		return backoff.NextDelay(attempt)
+	}
+
+	return max(0, min(delay, maxRetryAfter))
+}
+
+// rewindBody resets the request body so the request can be sent again.
+func rewindBody(request *http.Request) error {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil
+	}
+	if request.GetBody == nil {
+		return errors.New("request body cannot be rewound for retry")
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	request.Body = body
+
+	return nil
+}
@@ -0,0 +1,288 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestRetryTransientNetworkError(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// Drop the connection without responding.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(2, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRetryPostWithIdempotencyKey(t *testing.T) {
+	var bodies []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key-123", r.Header.Get("Idempotency-Key"))
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(2, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ctx = replicate.WithIdempotencyKey(ctx, "key-123")
+	input := replicate.PredictionInput{"text": "Alice"}
+	_, err = client.CreatePredictionWithModel(ctx, "owner", "model", input, nil, false)
+	require.NoError(t, err)
+
+	require.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.Contains(t, bodies[1], "Alice")
+}
+
+func TestRetryMaxDuration(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithMaxRetryDuration(time.Second),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	assert.ErrorIs(t, err, replicate.ErrRateLimited)
+	assert.Equal(t, 1, attempts)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryAfterDateUsesClock(t *testing.T) {
+	clock := replicatetest.NewFakeClock(time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC))
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", clock.Now().Add(30*time.Second).Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	var delays []time.Duration
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithClock(clock),
+		replicate.WithOnRetry(func(_ int, delay time.Duration, _ error) {
+			delays = append(delays, delay)
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+		done <- err
+	}()
+	require.NoError(t, clock.BlockUntil(ctx, 1))
+	clock.Advance(30 * time.Second)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, []time.Duration{30 * time.Second}, delays)
+	assert.Equal(t, 2, attempts)
+}
+
+type recordingRetryPolicy struct {
+	statuses []int
+}
+
+func (p *recordingRetryPolicy) ShouldRetry(resp *http.Response, _ error, attempt int) (time.Duration, bool) {
+	p.statuses = append(p.statuses, resp.StatusCode)
+	return 0, resp.StatusCode == http.StatusConflict && attempt < 3
+}
+
+func TestOnRetryHookAndStats(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"detail": "slow down"}`))
+			return
+		}
+
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	type retry struct {
+		attempt int
+		delay   time.Duration
+		err     error
+	}
+	var retries []retry
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(5, &replicate.ConstantBackoff{Base: time.Millisecond}),
+		replicate.WithOnRetry(func(attempt int, delay time.Duration, err error) {
+			retries = append(retries, retry{attempt, delay, err})
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetPrediction(context.Background(), "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+
+	require.Len(t, retries, 2)
+	for i, r := range retries {
+		assert.Equal(t, i+1, r.attempt)
+		assert.Equal(t, time.Millisecond, r.delay)
+		assert.ErrorIs(t, r.err, replicate.ErrRateLimited)
+	}
+
+	assert.Equal(t, replicate.ClientStats{
+		Requests:    3,
+		Retries:     2,
+		RateLimited: 2,
+	}, client.Stats())
+}
+
+func TestCustomRetryPolicy(t *testing.T) {
+	statuses := []int{http.StatusConflict, http.StatusConflict, http.StatusOK}
+	i := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(statuses[i])
+		i++
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	policy := &recordingRetryPolicy{}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithCustomRetryPolicy(policy),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	assert.Equal(t, []int{http.StatusConflict, http.StatusConflict}, policy.statuses)
+}
+
+func TestNoRetryPolicy(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithCustomRetryPolicy(replicate.NoRetryPolicy),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestBackoffRetryPolicy(t *testing.T) {
+	policy := replicate.NewConstantRetryPolicy(2, time.Second, 0)
+
+	resp := &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}
+	delay, ok := policy.ShouldRetry(resp, nil, 0)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, delay)
+
+	resp.Header.Set("Retry-After", "3")
+	delay, ok = policy.ShouldRetry(resp, nil, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, delay)
+
+	_, ok = policy.ShouldRetry(resp, nil, 2)
+	assert.False(t, ok)
+
+	// Retry-After delays are capped.
+	resp.Header.Set("Retry-After", "86400")
+	delay, ok = policy.ShouldRetry(resp, nil, 1)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, delay)
+
+	_, ok = policy.ShouldRetry(&http.Response{StatusCode: http.StatusBadRequest}, nil, 0)
+	assert.False(t, ok)
+
+	_, ok = policy.ShouldRetry(nil, io.ErrUnexpectedEOF, 0)
+	assert.True(t, ok)
+}
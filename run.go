@@ -55,6 +55,14 @@ func (r *Client) RunWithOptions(ctx context.Context, identifier string, input Pr
 		return nil, err
 	}
 
+	// Return the cached output of an identical run, if any
+	cacheKey := r.resultCacheKey(ctx, identifier, input, webhook)
+	if cacheKey != "" {
+		if output, ok := r.cachedOutput(ctx, cacheKey); ok {
+			return r.runOutput(ctx, output, options)
+		}
+	}
+
 	// Prepare the data for the prediction request
 	data := map[string]interface{}{}
 	path := "/predictions"
@@ -79,7 +87,7 @@ func (r *Client) RunWithOptions(ctx context.Context, identifier string, input Pr
 
 	// Execute the request and obtain the prediction
 	prediction := &Prediction{}
-	if err := r.do(req, prediction); err != nil {
+	if err := r.createPrediction(req, prediction); err != nil {
 		return nil, err
 	}
 
@@ -98,12 +106,19 @@ func (r *Client) RunWithOptions(ctx context.Context, identifier string, input Pr
 		return nil, &ModelError{Prediction: prediction}
 	}
 
-	// Transform the output based on the options
-	if options.useFileOutput {
-		return transformOutput(ctx, prediction.Output, r)
+	if cacheKey != "" && prediction.Status == Succeeded {
+		r.cacheOutput(ctx, cacheKey, prediction.Output)
 	}
 
-	return prediction.Output, nil
+	return r.runOutput(ctx, prediction.Output, options)
+}
+
+// runOutput transforms the output of a run based on the options.
+func (r *Client) runOutput(ctx context.Context, output PredictionOutput, options runOptions) (PredictionOutput, error) {
+	if options.useFileOutput {
+		return transformOutput(ctx, output, r)
+	}
+	return output, nil
 }
 
 // Run runs a model and returns the output
@@ -0,0 +1,145 @@
+package replicate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// maxSampledBodyLength limits how much of each body is kept in a sample.
+const maxSampledBodyLength = 1 << 20
+
+// PayloadSample is a sanitized copy of a call's last request and response.
+type PayloadSample struct {
+	// Time is when the call started.
+	Time time.Time
+
+	// Operation is the name of the client method, such as "GetPrediction".
+	Operation string
+
+	Method        string
+	URL           string
+	RequestHeader http.Header
+	RequestBody   []byte
+
+	// StatusCode, ResponseHeader, and ResponseBody describe the response,
+	// if one was received.
+	StatusCode     int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+
+	// Truncated reports whether either body was longer than 1 MiB and was
+	// cut short.
+	Truncated bool
+
+	// Err is the error returned by the call, if any.
+	Err error
+}
+
+// PayloadSink receives sampled payloads. It's called synchronously, after
+// the call finishes, and must be safe for concurrent use.
+type PayloadSink func(ctx context.Context, sample PayloadSample)
+
+type payloadSampler struct {
+	rate float64
+	sink PayloadSink
+}
+
+// WithPayloadSampler records the full request and response of a fraction of
+// calls, between 0 and 1, and passes them to sink. Samples include failed
+// calls, which makes them useful for reproducing rare decoding errors.
+//
+// The API token, webhook signing secrets, cookies, and data URL payloads
+// are redacted, as they are by WithDebug. Bodies are kept up to 1 MiB.
+func WithPayloadSampler(rate float64, sink PayloadSink) ClientOption {
+	return func(o *clientOptions) error {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("payload sample rate must be between 0 and 1, got %v", rate)
+		}
+		if sink == nil {
+			return fmt.Errorf("payload sink must not be nil")
+		}
+		o.sampler = &payloadSampler{rate: rate, sink: sink}
+		return nil
+	}
+}
+
+// startSample returns a sample to fill in if the call is sampled, or nil.
+func (r *Client) startSample(op operation, start time.Time) *PayloadSample {
+	s := r.options.sampler
+	if s == nil || s.rate == 0 || (s.rate < 1 && rand.Float64() >= s.rate) { //nolint:gosec
+		return nil
+	}
+	return &PayloadSample{Time: start, Operation: op.name}
+}
+
+// captureRequest records the request of an attempt, replacing the request
+// captured for earlier attempts.
+func (r *Client) captureRequest(sample *PayloadSample, request *http.Request) {
+	if sample == nil {
+		return
+	}
+
+	*sample = PayloadSample{
+		Time:          sample.Time,
+		Operation:     sample.Operation,
+		Method:        request.Method,
+		URL:           r.redact(request.URL.String()),
+		RequestHeader: r.redactHeader(request.Header),
+	}
+
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			data, truncated := readSampledBody(body)
+			body.Close()
+			if truncated {
+				data = data[:maxSampledBodyLength]
+			}
+			sample.RequestBody = []byte(r.redact(string(data)))
+			sample.Truncated = truncated
+		}
+	}
+}
+
+// captureResponse records the response of an attempt. The body is put back
+// so it can still be read in full.
+func (r *Client) captureResponse(sample *PayloadSample, response *http.Response) {
+	if sample == nil || response == nil {
+		return
+	}
+
+	sample.StatusCode = response.StatusCode
+	sample.ResponseHeader = r.redactHeader(response.Header)
+
+	if response.Body != nil && response.Body != http.NoBody {
+		data, truncated := readSampledBody(response.Body)
+		response.Body = &replayedBody{
+			Reader: io.MultiReader(bytes.NewReader(data), response.Body),
+			Closer: response.Body,
+		}
+		if truncated {
+			data = data[:maxSampledBodyLength]
+			sample.Truncated = true
+		}
+		sample.ResponseBody = []byte(r.redact(string(data)))
+	}
+}
+
+func (r *Client) finishSample(ctx context.Context, sample *PayloadSample, err error) {
+	if sample == nil || sample.Method == "" {
+		return
+	}
+	sample.Err = err
+	r.options.sampler.sink(ctx, *sample)
+}
+
+// readSampledBody reads up to one byte more than the sample limit, so that
+// truncation can be detected.
+func readSampledBody(body io.Reader) ([]byte, bool) {
+	data, _ := io.ReadAll(io.LimitReader(body, maxSampledBodyLength+1))
+	return data, len(data) > maxSampledBodyLength
+}
@@ -0,0 +1,99 @@
+package replicate_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestPayloadSampler(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"id": "ufawqhfynnddngldkgtslldrkq", "status": 42}`))
+	}))
+	defer mockServer.Close()
+
+	var samples []replicate.PayloadSample
+	client, err := replicate.NewClient(
+		replicate.WithToken("secret-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithPayloadSampler(1, func(_ context.Context, sample replicate.PayloadSample) {
+			samples = append(samples, sample)
+		}),
+	)
+	require.NoError(t, err)
+
+	input := replicate.PredictionInput{"image": "data:image/png;base64,iVBORw0KGgo="}
+	_, err = client.CreatePrediction(context.Background(), "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil, false)
+	var decodeErr *replicate.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+
+	require.Len(t, samples, 1)
+	sample := samples[0]
+	assert.Equal(t, "CreatePrediction", sample.Operation)
+	assert.Equal(t, http.MethodPost, sample.Method)
+	assert.Equal(t, "Bearer [REDACTED]", sample.RequestHeader.Get("Authorization"))
+	assert.Contains(t, string(sample.RequestBody), `"image":"data:image/png;base64,[REDACTED]"`)
+	assert.Equal(t, http.StatusOK, sample.StatusCode)
+	assert.JSONEq(t, `{"id": "ufawqhfynnddngldkgtslldrkq", "status": 42}`, string(sample.ResponseBody))
+	assert.False(t, sample.Truncated)
+	assert.ErrorAs(t, sample.Err, &decodeErr)
+}
+
+func TestPayloadSamplerRate(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"id": "ufawqhfynnddngldkgtslldrkq"}`))
+	}))
+	defer mockServer.Close()
+
+	sampled := 0
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithPayloadSampler(0, func(context.Context, replicate.PayloadSample) { sampled++ }),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		_, err := client.GetPrediction(context.Background(), "ufawqhfynnddngldkgtslldrkq")
+		require.NoError(t, err)
+	}
+	assert.Zero(t, sampled)
+
+	_, err = replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithPayloadSampler(1.5, func(context.Context, replicate.PayloadSample) {}),
+	)
+	assert.Error(t, err)
+}
+
+func TestPayloadSamplerRedactsWebhookSecret(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Set-Cookie", "session=abc123")
+		w.Write([]byte(`{"key": "whsec_5WbX5kEWLlfzsGNjH64I8lOOqUB6e8FH"}`))
+	}))
+	defer mockServer.Close()
+
+	var samples []replicate.PayloadSample
+	client, err := replicate.NewClient(
+		replicate.WithToken("secret-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithPayloadSampler(1, func(_ context.Context, sample replicate.PayloadSample) {
+			samples = append(samples, sample)
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetDefaultWebhookSecret(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, samples, 1)
+	assert.JSONEq(t, `{"key": "whsec_[REDACTED]"}`, string(samples[0].ResponseBody))
+	assert.Equal(t, "[REDACTED]", samples[0].ResponseHeader.Get("Set-Cookie"))
+}
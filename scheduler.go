@@ -0,0 +1,330 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CostEstimator estimates the cost in dollars of a prediction before it
+// runs, for example from the model's hardware and expected run time.
+type CostEstimator func(identifier string, input PredictionInput) float64
+
+// SchedulerOptions configure a Scheduler. Zero values mean no limit.
+type SchedulerOptions struct {
+	// MaxConcurrent is the number of predictions run at once.
+	MaxConcurrent int
+
+	// MaxPerMinute is the number of predictions started in any minute.
+	MaxPerMinute int
+
+	// MaxSpendPerHour is the estimated spend, in dollars, of the
+	// predictions started in any hour. It requires EstimateCost.
+	MaxSpendPerHour float64
+
+	// EstimateCost estimates the cost of each prediction, for
+	// MaxSpendPerHour.
+	EstimateCost CostEstimator
+
+	// PollingInterval is how often running predictions are polled. The
+	// default is the same as for Wait.
+	PollingInterval time.Duration
+
+	// PriorityAging protects low-priority work from starvation: every
+	// PriorityAging a prediction spends queued, its priority rises by one.
+	// The default is one minute; a negative value disables aging.
+	PriorityAging time.Duration
+}
+
+// defaultPriorityAging is how long queued work waits for each step up in
+// priority.
+const defaultPriorityAging = time.Minute
+
+// Priority orders the work queued by a Scheduler. Work with a higher
+// priority starts first; work with the same priority starts in the order it
+// was queued.
+type Priority int
+
+const (
+	// PriorityLow is for background work, like batch jobs.
+	PriorityLow Priority = -1
+
+	// PriorityNormal is the default priority.
+	PriorityNormal Priority = 0
+
+	// PriorityHigh is for interactive work that a user is waiting on.
+	PriorityHigh Priority = 1
+)
+
+// SchedulerStats is a snapshot of a scheduler's state.
+type SchedulerStats struct {
+	// Queued is the number of predictions waiting for a budget.
+	Queued int
+
+	// Running is the number of predictions running.
+	Running int
+
+	// StartedLastMinute is the number of predictions started in the last
+	// minute.
+	StartedLastMinute int
+
+	// SpendLastHour is the estimated spend of the predictions started in
+	// the last hour.
+	SpendLastHour float64
+}
+
+// Scheduler runs predictions within global budgets shared by all its
+// callers: how many run at once, how many start per minute, and how much
+// they're estimated to cost per hour. Work over a budget is queued and
+// started by priority, set with WithPriority, then in the order it arrived.
+//
+// A Scheduler is safe for concurrent use. Share one across the callers that
+// should share the budgets.
+type Scheduler struct {
+	client  *Client
+	options SchedulerOptions
+
+	mu      sync.Mutex
+	queue   []*scheduledRun
+	running int
+	starts  []time.Time
+	spends  []scheduledSpend
+	timer   Timer
+	timerAt time.Time
+}
+
+type scheduledRun struct {
+	cost     float64
+	priority Priority
+	queuedAt time.Time
+	ready    chan struct{}
+}
+
+type scheduledSpend struct {
+	at   time.Time
+	cost float64
+}
+
+// NewScheduler returns a scheduler that runs predictions with client.
+func NewScheduler(client *Client, opts SchedulerOptions) (*Scheduler, error) {
+	if opts.MaxConcurrent < 0 || opts.MaxPerMinute < 0 || opts.MaxSpendPerHour < 0 {
+		return nil, errors.New("scheduler limits must not be negative")
+	}
+	if opts.MaxSpendPerHour > 0 && opts.EstimateCost == nil {
+		return nil, errors.New("a spend limit requires a cost estimator")
+	}
+	if opts.PollingInterval <= 0 {
+		opts.PollingInterval = defaultPollingInterval
+	}
+	if opts.PriorityAging == 0 {
+		opts.PriorityAging = defaultPriorityAging
+	}
+	return &Scheduler{client: client, options: opts}, nil
+}
+
+// Run waits until the budgets allow, then runs a prediction and waits for
+// it to finish. If the prediction didn't succeed, the error is a
+// *ModelError. If ctx is done while the prediction is queued, Run returns
+// without running it.
+//
+// The prediction is queued with the priority set on ctx with WithPriority.
+func (s *Scheduler) Run(ctx context.Context, identifier string, input PredictionInput) (*Prediction, error) {
+	var cost float64
+	if s.options.EstimateCost != nil {
+		cost = s.options.EstimateCost(identifier, input)
+	}
+	if s.options.MaxSpendPerHour > 0 && cost > s.options.MaxSpendPerHour {
+		return nil, fmt.Errorf("estimated cost of $%.2f exceeds the hourly spend limit of $%.2f", cost, s.options.MaxSpendPerHour)
+	}
+
+	if err := s.acquire(ctx, cost, requestPriority(ctx)); err != nil {
+		return nil, err
+	}
+	defer s.release()
+
+	return s.client.runToCompletion(ctx, identifier, input, WithPollingInterval(s.options.PollingInterval))
+}
+
+// Submit queues a prediction like Run, without blocking, and returns a
+// future for its result.
+func (s *Scheduler) Submit(ctx context.Context, identifier string, input PredictionInput) *PredictionFuture {
+	f := &PredictionFuture{done: make(chan struct{})}
+	ctx, done := s.client.background(ctx)
+	go func() {
+		defer done()
+		defer close(f.done)
+		f.prediction, f.err = s.Run(ctx, identifier, input)
+	}()
+	return f
+}
+
+// Stats returns a snapshot of the scheduler's state.
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(s.client.clock().Now())
+	stats := SchedulerStats{
+		Queued:            len(s.queue),
+		Running:           s.running,
+		StartedLastMinute: len(s.starts),
+	}
+	for _, spend := range s.spends {
+		stats.SpendLastHour += spend.cost
+	}
+	return stats
+}
+
+// acquire queues a run and waits until it may start.
+func (s *Scheduler) acquire(ctx context.Context, cost float64, priority Priority) error {
+	run := &scheduledRun{cost: cost, priority: priority, ready: make(chan struct{})}
+
+	s.mu.Lock()
+	run.queuedAt = s.client.clock().Now()
+	s.queue = append(s.queue, run)
+	s.dispatchLocked()
+	s.mu.Unlock()
+
+	select {
+	case <-run.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, queued := range s.queue {
+		if queued == run {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			// The runs behind this one may fit now.
+			s.dispatchLocked()
+			return classifyError(ctx.Err())
+		}
+	}
+
+	// The run started just as ctx was done. Give its slot back.
+	s.running--
+	s.dispatchLocked()
+	return classifyError(ctx.Err())
+}
+
+func (s *Scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	s.dispatchLocked()
+}
+
+// dispatchLocked starts queued runs, by priority, while the budgets allow. If
+// the next run is waiting for a time window to pass, it sets a timer to try
+// again.
+func (s *Scheduler) dispatchLocked() {
+	now := s.client.clock().Now()
+	s.pruneLocked(now)
+
+	for len(s.queue) > 0 {
+		i := s.nextLocked(now)
+		run := s.queue[i]
+		if s.options.MaxConcurrent > 0 && s.running >= s.options.MaxConcurrent {
+			return
+		}
+		if wake, ok := s.nextStartLocked(run); !ok {
+			s.wakeAtLocked(wake)
+			return
+		}
+
+		s.queue = append(s.queue[:i], s.queue[i+1:]...)
+		s.running++
+		s.starts = append(s.starts, now)
+		if run.cost > 0 {
+			s.spends = append(s.spends, scheduledSpend{at: now, cost: run.cost})
+		}
+		close(run.ready)
+	}
+}
+
+// nextLocked returns the index of the queued run to start next: the one
+// with the highest priority after aging, or the oldest among equals.
+func (s *Scheduler) nextLocked(now time.Time) int {
+	next, best := 0, s.effectivePriority(s.queue[0], now)
+	for i, run := range s.queue[1:] {
+		if p := s.effectivePriority(run, now); p > best {
+			next, best = i+1, p
+		}
+	}
+	return next
+}
+
+func (s *Scheduler) effectivePriority(run *scheduledRun, now time.Time) int64 {
+	p := int64(run.priority)
+	if s.options.PriorityAging > 0 {
+		p += int64(now.Sub(run.queuedAt) / s.options.PriorityAging)
+	}
+	return p
+}
+
+// nextStartLocked reports whether run fits the time-based budgets now, and
+// if not, when it might.
+func (s *Scheduler) nextStartLocked(run *scheduledRun) (time.Time, bool) {
+	if s.options.MaxPerMinute > 0 && len(s.starts) >= s.options.MaxPerMinute {
+		return s.starts[len(s.starts)-s.options.MaxPerMinute].Add(time.Minute), false
+	}
+
+	if s.options.MaxSpendPerHour > 0 {
+		var spent float64
+		for _, spend := range s.spends {
+			spent += spend.cost
+		}
+		// Wait until enough of the oldest spends leave the window for the
+		// run to fit.
+		if spent+run.cost > s.options.MaxSpendPerHour {
+			for _, spend := range s.spends {
+				spent -= spend.cost
+				if spent+run.cost <= s.options.MaxSpendPerHour {
+					return spend.at.Add(time.Hour), false
+				}
+			}
+		}
+	}
+
+	return time.Time{}, true
+}
+
+// pruneLocked forgets starts and spends that are outside their windows.
+func (s *Scheduler) pruneLocked(now time.Time) {
+	i := 0
+	for i < len(s.starts) && !s.starts[i].Add(time.Minute).After(now) {
+		i++
+	}
+	s.starts = s.starts[i:]
+
+	j := 0
+	for j < len(s.spends) && !s.spends[j].at.Add(time.Hour).After(now) {
+		j++
+	}
+	s.spends = s.spends[j:]
+}
+
+// wakeAtLocked makes sure queued runs are dispatched again at t.
+func (s *Scheduler) wakeAtLocked(t time.Time) {
+	if s.timer != nil && !s.timerAt.After(t) {
+		return
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+
+	var timer Timer
+	timer = s.client.clock().AfterFunc(t.Sub(s.client.clock().Now()), func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.timer == timer {
+			s.timer = nil
+		}
+		s.dispatchLocked()
+	})
+	s.timer = timer
+	s.timerAt = t
+}
@@ -0,0 +1,240 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+func TestScheduler(t *testing.T) {
+	release := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: body.Input["id"].(string), Status: replicate.Starting})
+		case http.MethodGet:
+			<-release
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Succeeded, Output: id})
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	scheduler, err := replicate.NewScheduler(client, replicate.SchedulerOptions{
+		MaxConcurrent:   2,
+		MaxSpendPerHour: 1,
+		EstimateCost: func(_ string, input replicate.PredictionInput) float64 {
+			if input["id"] == "huge" {
+				return 5
+			}
+			return 0.3
+		},
+		PollingInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancelAll := context.WithCancel(context.Background())
+	defer cancelAll()
+	_, err = scheduler.Run(ctx, "owner/model", replicate.PredictionInput{"id": "huge"})
+	assert.ErrorContains(t, err, "exceeds the hourly spend limit")
+
+	var futures []*replicate.PredictionFuture
+	for _, id := range []string{"a", "b", "c", "d"} {
+		futures = append(futures, scheduler.Submit(ctx, "owner/model", replicate.PredictionInput{"id": id}))
+		// Submit in order, so the queue order is predictable.
+		require.Eventually(t, func() bool {
+			stats := scheduler.Stats()
+			return stats.Running+stats.Queued == len(futures)
+		}, time.Second, time.Millisecond)
+	}
+	assert.Equal(t, replicate.SchedulerStats{Queued: 2, Running: 2, StartedLastMinute: 2, SpendLastHour: 0.6}, scheduler.Stats())
+
+	// c fits the concurrency limit once a or b finishes, and the spend
+	// limit; d has to wait an hour for the spend limit.
+	close(release)
+	for _, future := range futures[:3] {
+		prediction, err := future.Result(ctx)
+		require.NoError(t, err)
+		assert.NotEmpty(t, prediction.Output)
+	}
+	stats := scheduler.Stats()
+	assert.Equal(t, 1, stats.Queued)
+	assert.Equal(t, 0, stats.Running)
+	assert.InDelta(t, 0.9, stats.SpendLastHour, 1e-9)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = futures[3].Result(timeout)
+	assert.ErrorIs(t, err, replicate.ErrDeadlineExceeded)
+
+	_, err = replicate.NewScheduler(client, replicate.SchedulerOptions{MaxSpendPerHour: 1})
+	assert.ErrorContains(t, err, "requires a cost estimator")
+}
+
+func TestSchedulerRateLimit(t *testing.T) {
+	clock := replicatetest.NewFakeClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	var mu sync.Mutex
+	created := map[string]time.Time{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			id := body.Input["id"].(string)
+			mu.Lock()
+			created[id] = clock.Now()
+			mu.Unlock()
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Starting})
+		case http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Succeeded})
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithClock(clock),
+	)
+	require.NoError(t, err)
+	scheduler, err := replicate.NewScheduler(client, replicate.SchedulerOptions{MaxPerMinute: 1})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	first := scheduler.Submit(ctx, "owner/model", replicate.PredictionInput{"id": "first"})
+	require.Eventually(t, func() bool { return scheduler.Stats().Running == 1 }, time.Second, time.Millisecond)
+	second := scheduler.Submit(ctx, "owner/model", replicate.PredictionInput{"id": "second"})
+
+	// The first prediction's poll and the scheduler's wake-up timer.
+	require.NoError(t, clock.BlockUntil(ctx, 2))
+	assert.Equal(t, 1, scheduler.Stats().Queued)
+	clock.AutoAdvance(true)
+	clock.Advance(time.Minute)
+
+	_, err = first.Result(ctx)
+	require.NoError(t, err)
+	_, err = second.Result(ctx)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.GreaterOrEqual(t, created["second"].Sub(created["first"]), time.Minute)
+}
+
+func TestSchedulerPriority(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	block := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			id := body.Input["id"].(string)
+			mu.Lock()
+			order = append(order, id)
+			mu.Unlock()
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Starting})
+		case http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/predictions/")
+			if id == "blocker" {
+				mu.Lock()
+				wait := block
+				mu.Unlock()
+				<-wait
+			}
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: id, Status: replicate.Succeeded})
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	run := func(t *testing.T, aging time.Duration, queue func(submit func(ctx context.Context, id string))) []string {
+		mu.Lock()
+		order = nil
+		block = make(chan struct{})
+		mu.Unlock()
+
+		scheduler, err := replicate.NewScheduler(client, replicate.SchedulerOptions{
+			MaxConcurrent:   1,
+			PollingInterval: time.Millisecond,
+			PriorityAging:   aging,
+		})
+		require.NoError(t, err)
+
+		var futures []*replicate.PredictionFuture
+		submit := func(ctx context.Context, id string) {
+			futures = append(futures, scheduler.Submit(ctx, "owner/model", replicate.PredictionInput{"id": id}))
+			require.Eventually(t, func() bool {
+				stats := scheduler.Stats()
+				return stats.Running+stats.Queued == len(futures)
+			}, time.Second, time.Millisecond)
+		}
+
+		submit(context.Background(), "blocker")
+		queue(submit)
+		close(block)
+		for _, future := range futures {
+			_, err := future.Result(context.Background())
+			require.NoError(t, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		return order
+	}
+
+	ctx := context.Background()
+	low := replicate.WithPriority(ctx, replicate.PriorityLow)
+	high := replicate.WithPriority(ctx, replicate.PriorityHigh)
+
+	t.Run("high priority first", func(t *testing.T) {
+		order := run(t, -1, func(submit func(context.Context, string)) {
+			submit(low, "batch-1")
+			submit(low, "batch-2")
+			submit(ctx, "normal")
+			submit(high, "interactive")
+		})
+		assert.Equal(t, []string{"blocker", "interactive", "normal", "batch-1", "batch-2"}, order)
+	})
+
+	t.Run("aging", func(t *testing.T) {
+		order := run(t, time.Millisecond, func(submit func(context.Context, string)) {
+			submit(low, "batch")
+			time.Sleep(20 * time.Millisecond)
+			submit(high, "interactive")
+		})
+		assert.Equal(t, []string{"blocker", "batch", "interactive"}, order)
+	})
+}
@@ -0,0 +1,90 @@
+package replicate
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// RetryHook is called before the client waits to retry a request.
+//
+// attempt is the number of the attempt that failed, starting at 1. err is the
+// error the request would have returned had it not been retried.
+type RetryHook func(attempt int, delay time.Duration, err error)
+
+// WithOnRetry sets a function that is called each time a request is retried.
+// The hook is called synchronously and should return quickly.
+func WithOnRetry(hook RetryHook) ClientOption {
+	return func(o *clientOptions) error {
+		o.onRetry = hook
+		return nil
+	}
+}
+
+// ClientStats holds counters for requests made by a client.
+type ClientStats struct {
+	// Requests is the number of HTTP attempts, including retries.
+	Requests uint64
+
+	// Retries is the number of attempts that were retried.
+	Retries uint64
+
+	// RateLimited is the number of responses with status 429.
+	RateLimited uint64
+
+	// CircuitOpened is the number of times an endpoint was marked unhealthy
+	// and requests failed over to another base URL.
+	CircuitOpened uint64
+
+	// CircuitClosed is the number of times an unhealthy endpoint passed a
+	// health check and was used again.
+	CircuitClosed uint64
+}
+
+type clientCounters struct {
+	requests    atomic.Uint64
+	retries     atomic.Uint64
+	rateLimited atomic.Uint64
+
+	circuitOpened atomic.Uint64
+	circuitClosed atomic.Uint64
+}
+
+// Stats returns a snapshot of the client's request counters.
+//
+// Counters only increase over the lifetime of a client, so callers can
+// compute rates by comparing successive snapshots.
+func (r *Client) Stats() ClientStats {
+	c := &r.state.counters
+	return ClientStats{
+		Requests:    c.requests.Load(),
+		Retries:     c.retries.Load(),
+		RateLimited: c.rateLimited.Load(),
+
+		CircuitOpened: c.circuitOpened.Load(),
+		CircuitClosed: c.circuitClosed.Load(),
+	}
+}
+
+func (r *Client) recordAttempt(op operation, response *http.Response, err error, duration time.Duration) {
+	r.state.counters.requests.Add(1)
+
+	statusCode := 0
+	if response != nil {
+		statusCode = response.StatusCode
+	}
+	r.metrics().ObserveRequest(op.name, statusCode, duration, err)
+
+	if statusCode == http.StatusTooManyRequests {
+		r.state.counters.rateLimited.Add(1)
+		r.metrics().ObserveRateLimited(op.name)
+	}
+}
+
+func (r *Client) recordRetry(op operation, attempt int, delay time.Duration, err error) {
+	r.state.counters.retries.Add(1)
+	r.metrics().ObserveRetry(op.name, attempt, delay)
+	if r.options.onRetry != nil {
+		r.options.onRetry(attempt, delay, err)
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 
 	"github.com/vincent-petithory/dataurl"
@@ -104,7 +107,7 @@ func (e *SSEEvent) String() string {
 
 func (r *Client) sendError(err error, errChan chan error) {
 	select {
-	case errChan <- err:
+	case errChan <- classifyError(err):
 	default:
 	}
 }
@@ -131,28 +134,55 @@ func (r *Client) Stream(ctx context.Context, identifier string, input Prediction
 		return sseChan, errChan
 	}
 
-	r.streamPrediction(ctx, prediction, nil, sseChan, errChan)
+	r.streamPrediction(ctx, prediction, nil, true, 0, sseChan, errChan)
 
 	return sseChan, errChan
 }
 
+// StreamPrediction streams the output of a prediction created with streaming
+// enabled.
+//
+// If the prediction can't be streamed, the client waits for it to finish and
+// sends its whole output as a single event, unless the client was created
+// with WithoutStreamFallback.
 func (r *Client) StreamPrediction(ctx context.Context, prediction *Prediction) (<-chan SSEEvent, <-chan error) {
 	sseChan := make(chan SSEEvent, 64)
 	errChan := make(chan error, 64)
 
-	r.streamPrediction(ctx, prediction, nil, sseChan, errChan)
+	r.streamPrediction(ctx, prediction, nil, true, 0, sseChan, errChan)
 
 	return sseChan, errChan
 }
 
+// streamRetryPolicy returns the reconnection settings for SSE streams, which
+// follow the client's retry policy when it's a BackoffRetryPolicy.
+func (r *Client) streamRetryPolicy() (int, Backoff) {
+	if p, ok := r.options.retryPolicy.(*BackoffRetryPolicy); ok {
+		return p.MaxRetries, p.Backoff
+	}
+	return defaultMaxRetries, defaultBackoff
+}
+
 type textStreamer struct {
 	s            *sse.Streamer
 	ctx          context.Context
 	currentEvent io.Reader
 	done         bool
+
+	// fallback returns a reader to use instead of the stream if the first
+	// connection fails. It is nil once an event has been received.
+	fallback func(err error) io.Reader
+	polled   io.Reader
+
+	onEvent   func(eventType string)
+	closed    func()
+	closeOnce sync.Once
 }
 
 func (t *textStreamer) Read(buf []byte) (int, error) {
+	if t.polled != nil {
+		return t.polled.Read(buf)
+	}
 	if t.done {
 		return 0, io.EOF
 	}
@@ -160,8 +190,19 @@ func (t *textStreamer) Read(buf []byte) (int, error) {
 		if t.currentEvent == nil {
 			e, err := t.s.NextEvent(t.ctx)
 			if err != nil {
+				if t.fallback != nil && t.ctx.Err() == nil {
+					if reader := t.fallback(err); reader != nil {
+						t.fallback = nil
+						t.polled = reader
+						return t.polled.Read(buf)
+					}
+				}
 				return 0, err
 			}
+			t.fallback = nil
+			if e.Type != "" && t.onEvent != nil {
+				t.onEvent(e.Type)
+			}
 			switch e.Type {
 			case "":
 				// empty message, ignore
@@ -199,6 +240,7 @@ func (t *textStreamer) Read(buf []byte) (int, error) {
 }
 
 func (t *textStreamer) Close() error {
+	t.closeOnce.Do(t.closed)
 	return t.s.Close()
 }
 
@@ -206,14 +248,33 @@ func (t *textStreamer) Close() error {
 // streaming api.  It is the caller's responsibility to close the returned
 // io.ReadCloser to ensure connections and associated resources are cleaned up
 // appropriately.
+//
+// If the prediction can't be streamed, the returned reader waits for the
+// prediction to finish and yields its whole output, unless the client was
+// created with WithoutStreamFallback.
 func (r *Client) StreamPredictionText(ctx context.Context, prediction *Prediction) (io.ReadCloser, error) {
 	url := prediction.URLs["stream"]
 	if url == "" {
-		return nil, errors.New("streaming not supported or not enabled for this prediction")
+		if !r.streamFallback(prediction, errStreamingNotSupported) {
+			return nil, errStreamingNotSupported
+		}
+		return &polledText{ctx: ctx, client: r, prediction: prediction}, nil
+	}
+	maxRetries, backoff := r.streamRetryPolicy()
+	s := sse.NewStreamer(r.c, url, maxRetries, backoff)
+	s.OnReconnect = r.recordStreamReconnect
+	s.Sleep = r.sleep
+
+	r.streamStarted()
+	t := &textStreamer{s: s, ctx: ctx, onEvent: r.recordStreamEvent, closed: r.streamEnded}
+	t.fallback = func(err error) io.Reader {
+		if !r.streamFallback(prediction, err) {
+			return nil
+		}
+		return &polledText{ctx: ctx, client: r, prediction: prediction}
 	}
-	s := sse.NewStreamer(r.c, url, r.options.retryPolicy.maxRetries, r.options.retryPolicy.backoff)
 
-	return &textStreamer{s: s, ctx: ctx}, nil
+	return t, nil
 }
 
 type dataURL struct {
@@ -305,17 +366,43 @@ func (f *fileStreamer) Close() error {
 func (r *Client) StreamPredictionFiles(prediction *Prediction) (streaming.FileStreamer, error) {
 	url := prediction.URLs["stream"]
 	if url == "" {
-		return nil, errors.New("streaming not supported or not enabled for this prediction")
+		return nil, errStreamingNotSupported
 	}
 
-	s := sse.NewStreamer(r.c, url, r.options.retryPolicy.maxRetries, r.options.retryPolicy.backoff)
+	maxRetries, backoff := r.streamRetryPolicy()
+	s := sse.NewStreamer(r.c, url, maxRetries, backoff)
+	s.Sleep = r.sleep
 	return &fileStreamer{s: s, c: r.c}, nil
 }
 
-func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, lastEvent *SSEEvent, sseChan chan SSEEvent, errChan chan error) {
+// streamPrediction sends the events of a prediction's stream to sseChan.
+// When allowFallback is true and the stream can't be opened, it polls for the
+// prediction's output instead. reconnects is the number of times the stream
+// has already been reopened; it's reopened with backoff, following
+// streamRetryPolicy, until the policy's retries run out.
+func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, lastEvent *SSEEvent, allowFallback bool, reconnects int, sseChan chan SSEEvent, errChan chan error) {
+	// Reconnections and the polling fallback register their own background
+	// work, so they're started with the caller's context.
+	parent := ctx
+	ctx, release := r.background(ctx)
+	detached := false
+	defer func() {
+		if !detached {
+			release()
+		}
+	}()
+
+	fail := func(err error) {
+		if allowFallback && ctx.Err() == nil && r.streamFallback(prediction, err) {
+			r.pollPrediction(parent, prediction, sseChan, errChan)
+			return
+		}
+		r.sendError(err, errChan)
+	}
+
 	url := prediction.URLs["stream"]
 	if url == "" {
-		r.sendError(errors.New("streaming not supported or not enabled for this prediction"), errChan)
+		fail(errStreamingNotSupported)
 		return
 	}
 
@@ -327,6 +414,7 @@ func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, l
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
+	setRequestHeaders(ctx, req)
 
 	if lastEvent != nil {
 		req.Header.Set("Last-Event-ID", lastEvent.ID)
@@ -334,56 +422,91 @@ func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, l
 
 	resp, err := r.c.Do(req)
 	if err != nil {
-		r.sendError(fmt.Errorf("failed to send request: %w", err), errChan)
+		fail(fmt.Errorf("failed to send request: %w", err))
 		return
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		r.sendError(fmt.Errorf("received invalid status code: %d", resp.StatusCode), errChan)
+		resp.Body.Close()
+		fail(fmt.Errorf("received invalid status code: %d", resp.StatusCode))
 		return
 	}
 
+	r.streamStarted()
+
 	reader := bufio.NewReader(resp.Body)
 	var buf bytes.Buffer
 	lineChan := make(chan []byte)
 
-	g, ctx := errgroup.WithContext(ctx)
+	var last atomic.Pointer[SSEEvent]
+	last.Store(lastEvent)
+
+	// stalled is set when the stall timeout closes the connection.
+	var stalled atomic.Bool
+	var stallTimer Timer
+	if d := r.options.streamStallTimeout; d > 0 {
+		stallTimer = r.clock().AfterFunc(d, func() {
+			stalled.Store(true)
+			r.recordStreamStall()
+			resp.Body.Close()
+		})
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
 	done := make(chan struct{})
 
 	g.Go(func() error {
 		defer close(lineChan)
 		defer resp.Body.Close()
+		if stallTimer != nil {
+			defer stallTimer.Stop()
+		}
 
 		for {
 			select {
-			case <-ctx.Done():
-				return ctx.Err()
+			case <-gctx.Done():
+				return gctx.Err()
 			case <-done:
 				return nil
 			default:
 				line, err := reader.ReadBytes('\n')
 				if err != nil {
+					if stalled.Load() {
+						return errStreamStalled
+					}
 					return err
 				}
+				// The consumer may be slower than the stall timeout, so
+				// only time the reads, not handing the lines over.
+				if stallTimer != nil {
+					stallTimer.Stop()
+				}
 				select {
 				case lineChan <- line:
-				case <-ctx.Done():
-					return ctx.Err()
+				case <-done:
+					return nil
+				case <-gctx.Done():
+					return gctx.Err()
+				}
+				if stallTimer != nil {
+					stallTimer.Reset(r.options.streamStallTimeout)
 				}
 			}
 		}
 	})
 
-	go func() {
+	// The event loop watches the caller's context rather than the group's,
+	// so that lines read before the connection closed are still delivered.
+	g.Go(func() error {
 		for {
 			select {
 			case <-ctx.Done():
-				return
+				return nil
 			case <-done:
-				return
+				return nil
 			case b, ok := <-lineChan:
 				if !ok {
-					return
+					return nil
 				}
 
 				buf.Write(b)
@@ -403,28 +526,37 @@ func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, l
 						continue
 					}
 
+					r.recordStreamEvent(event.Type)
+					if event.ID != "" {
+						last.Store(event)
+					}
+
 					select {
 					case sseChan <- *event:
 					case <-done:
-						return
+						return nil
 					case <-ctx.Done():
-						return
+						return nil
 					}
 
 					if event.Type == SSETypeDone {
 						close(done)
-						return
+						return nil
 					}
 				}
 			}
 		}
-	}()
+	})
 
+	detached = true
 	go func() {
+		defer release()
+
 		err := g.Wait()
+		r.streamEnded()
 
 		if err != nil {
-			if errors.Is(err, io.EOF) {
+			if errors.Is(err, io.EOF) || errors.Is(err, errStreamStalled) {
 				select {
 				case <-done:
 					// if we get EOF after receiving "done", we're done
@@ -432,12 +564,34 @@ func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, l
 				default:
 				}
 				// Attempt to reconnect if the connection was closed before the stream was done
-				r.streamPrediction(ctx, prediction, lastEvent, sseChan, errChan)
-				return
+				maxRetries, backoff := r.streamRetryPolicy()
+				if reconnects >= maxRetries {
+					err = fmt.Errorf("failed to reconnect to prediction stream after %d attempts: %w", reconnects, err)
+				} else {
+					lastEvent := last.Load()
+					delay := backoff.NextDelay(reconnects)
+					attrs := []slog.Attr{
+						slog.String("prediction_id", prediction.ID),
+						slog.Int("attempt", reconnects+1),
+						slog.Duration("delay", delay),
+					}
+					if lastEvent != nil {
+						attrs = append(attrs, slog.String("last_event_id", lastEvent.ID))
+					}
+					r.log(ctx, slog.LevelInfo, "reconnecting replicate prediction stream", attrs...)
+					r.recordStreamReconnect()
+					if err = r.sleep(ctx, delay); err == nil {
+						r.streamPrediction(parent, prediction, lastEvent, false, reconnects+1, sseChan, errChan)
+						return
+					}
+				}
 			}
 
 			if !errors.Is(err, context.Canceled) {
 				r.sendError(err, errChan)
+			} else if ctx.Err() != nil {
+				r.cancelOnClose(ctx, prediction.ID)
+				r.sendError(contextError(ctx), errChan)
 			}
 		}
 
@@ -0,0 +1,150 @@
+package replicate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// StreamFallbackHook is called when a prediction can't be streamed and the
+// client falls back to polling for its output. err describes why streaming
+// failed.
+type StreamFallbackHook func(prediction *Prediction, err error)
+
+var errStreamingNotSupported = errors.New("streaming not supported or not enabled for this prediction")
+
+// WithStreamFallbackHook sets a function that is called whenever a stream
+// falls back to polling, for example to log a warning.
+func WithStreamFallbackHook(hook StreamFallbackHook) ClientOption {
+	return func(o *clientOptions) error {
+		o.streamFallbackHook = hook
+		return nil
+	}
+}
+
+// WithoutStreamFallback disables falling back to polling when a prediction
+// can't be streamed. Streaming methods return an error instead.
+func WithoutStreamFallback() ClientOption {
+	return func(o *clientOptions) error {
+		o.disableStreamFallback = true
+		return nil
+	}
+}
+
+// streamFallback reports whether to fall back to polling, calling the
+// fallback hook if so.
+func (r *Client) streamFallback(prediction *Prediction, cause error) bool {
+	if r.options.disableStreamFallback {
+		return false
+	}
+
+	if r.options.streamFallbackHook != nil {
+		r.options.streamFallbackHook(prediction, cause)
+	}
+	return true
+}
+
+// pollPrediction waits for the prediction to finish and sends its output as
+// a single output event followed by a done event, mimicking a stream.
+func (r *Client) pollPrediction(ctx context.Context, prediction *Prediction, sseChan chan SSEEvent, errChan chan error) {
+	ctx, done := r.background(ctx)
+	go func() {
+		defer done()
+		defer close(sseChan)
+		defer close(errChan)
+
+		text, err := r.waitForOutputText(ctx, prediction)
+		if err != nil {
+			var modelErr *ModelError
+			if !errors.As(err, &modelErr) {
+				r.sendError(err, errChan)
+				return
+			}
+
+			data, _ := json.Marshal(map[string]interface{}{"detail": prediction.Error})
+			sseChan <- SSEEvent{Type: SSETypeError, ID: prediction.ID, Data: string(data)}
+			return
+		}
+
+		if text != "" {
+			sseChan <- SSEEvent{Type: SSETypeOutput, ID: prediction.ID, Data: text}
+		}
+		sseChan <- SSEEvent{Type: SSETypeDone, ID: prediction.ID, Data: "{}"}
+	}()
+}
+
+// waitForOutputText waits for the prediction to finish and returns its
+// output as text.
+func (r *Client) waitForOutputText(ctx context.Context, prediction *Prediction) (string, error) {
+	if !prediction.Status.Terminated() {
+		if err := r.Wait(ctx, prediction); err != nil {
+			return "", err
+		}
+	}
+
+	if prediction.Status != Succeeded {
+		return "", &ModelError{Prediction: prediction}
+	}
+
+	return outputText(prediction.Output)
+}
+
+// outputText converts prediction output into the text a stream would have
+// produced. Language models return a list of tokens, which are joined.
+func outputText(output PredictionOutput) (string, error) {
+	switch v := output.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []interface{}:
+		var sb strings.Builder
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return marshalOutput(output)
+			}
+			sb.WriteString(s)
+		}
+		return sb.String(), nil
+	default:
+		return marshalOutput(output)
+	}
+}
+
+func marshalOutput(output PredictionOutput) (string, error) {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return string(data), nil
+}
+
+// polledText is an io.ReadCloser over the output of a prediction that
+// couldn't be streamed. It waits for the prediction on the first read.
+type polledText struct {
+	ctx        context.Context
+	client     *Client
+	prediction *Prediction
+
+	reader io.Reader
+}
+
+func (p *polledText) Read(buf []byte) (int, error) {
+	if p.reader == nil {
+		text, err := p.client.waitForOutputText(p.ctx, p.prediction)
+		if err != nil {
+			return 0, err
+		}
+		p.reader = strings.NewReader(text)
+	}
+
+	return p.reader.Read(buf)
+}
+
+func (p *polledText) Close() error {
+	return nil
+}
@@ -0,0 +1,131 @@
+package replicate
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errStreamStalled is returned internally when a stream receives no data for
+// longer than the stall timeout.
+var errStreamStalled = errors.New("stream stalled")
+
+// StreamMetrics is implemented by Metrics that also observe the health of
+// prediction output streams.
+type StreamMetrics interface {
+	// ObserveStreamEvent is called for each event received, with its type,
+	// such as "output" or "done".
+	ObserveStreamEvent(eventType string)
+
+	// ObserveStreamReconnect is called when a stream reconnects after its
+	// connection was lost.
+	ObserveStreamReconnect()
+
+	// ObserveStreamStall is called when a stream receives no data for longer
+	// than the stall timeout.
+	ObserveStreamStall()
+}
+
+// StreamStats holds counters for the prediction output streams of a client.
+type StreamStats struct {
+	// Active is the number of streams currently connected.
+	Active int64
+
+	// Started is the number of stream connections opened, including
+	// reconnections.
+	Started uint64
+
+	// Reconnects is the number of times a stream reconnected after its
+	// connection was lost.
+	Reconnects uint64
+
+	// Stalls is the number of times a stream received no data for longer
+	// than the stall timeout.
+	Stalls uint64
+
+	// Events counts the events received by type.
+	Events map[string]uint64
+}
+
+type streamCounters struct {
+	active     atomic.Int64
+	started    atomic.Uint64
+	reconnects atomic.Uint64
+	stalls     atomic.Uint64
+
+	mu     sync.Mutex
+	events map[string]uint64
+}
+
+// WithStreamStallTimeout makes Stream and StreamPrediction reconnect when a
+// stream receives no data, including keep-alive comments, for longer than d.
+// Stalls are counted in StreamStats. By default, streams wait indefinitely.
+func WithStreamStallTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if d <= 0 {
+			return fmt.Errorf("stream stall timeout must be positive, got %s", d)
+		}
+		o.streamStallTimeout = d
+		return nil
+	}
+}
+
+// StreamStats returns a snapshot of the client's stream counters.
+func (r *Client) StreamStats() StreamStats {
+	c := &r.state.streams
+	c.mu.Lock()
+	events := make(map[string]uint64, len(c.events))
+	for eventType, n := range c.events {
+		events[eventType] = n
+	}
+	c.mu.Unlock()
+
+	return StreamStats{
+		Active:     c.active.Load(),
+		Started:    c.started.Load(),
+		Reconnects: c.reconnects.Load(),
+		Stalls:     c.stalls.Load(),
+		Events:     events,
+	}
+}
+
+func (r *Client) streamStarted() {
+	r.state.streams.active.Add(1)
+	r.state.streams.started.Add(1)
+	r.metrics().StreamStarted()
+}
+
+func (r *Client) streamEnded() {
+	r.state.streams.active.Add(-1)
+	r.metrics().StreamEnded()
+}
+
+func (r *Client) recordStreamEvent(eventType string) {
+	c := &r.state.streams
+	c.mu.Lock()
+	if c.events == nil {
+		c.events = make(map[string]uint64)
+	}
+	c.events[eventType]++
+	c.mu.Unlock()
+
+	if m, ok := r.metrics().(StreamMetrics); ok {
+		m.ObserveStreamEvent(eventType)
+	}
+}
+
+func (r *Client) recordStreamReconnect() {
+	r.state.streams.reconnects.Add(1)
+	if m, ok := r.metrics().(StreamMetrics); ok {
+		m.ObserveStreamReconnect()
+	}
+}
+
+func (r *Client) recordStreamStall() {
+	r.state.streams.stalls.Add(1)
+	if m, ok := r.metrics().(StreamMetrics); ok {
+		m.ObserveStreamStall()
+	}
+}
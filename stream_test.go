@@ -2,10 +2,12 @@ package replicate_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -150,3 +152,231 @@ event: done
 	require.NoError(t, err)
 	assert.Equal(t, "mango\n", string(content3))
 }
+
+func TestStreamFallbackToPolling(t *testing.T) {
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/predictions/ufawqhfynnddngldkgtslldrkq":
+			polls++
+			status := replicate.Processing
+			var output interface{}
+			if polls > 1 {
+				status = replicate.Succeeded
+				output = []string{"Hello", ", ", "Alice"}
+			}
+			json.NewEncoder(w).Encode(&replicate.Prediction{
+				ID:     "ufawqhfynnddngldkgtslldrkq",
+				Status: status,
+				Output: output,
+			})
+		case "/stream":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	var fallbacks []error
+	c, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(ts.URL),
+		replicate.WithStreamFallbackHook(func(_ *replicate.Prediction, err error) {
+			fallbacks = append(fallbacks, err)
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	t.Run("without stream URL", func(t *testing.T) {
+		polls = 0
+		p := &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting}
+
+		sseChan, errChan := c.StreamPrediction(ctx, p)
+
+		var events []replicate.SSEEvent
+		for event := range sseChan {
+			events = append(events, event)
+		}
+		for err := range errChan {
+			require.NoError(t, err)
+		}
+
+		require.Len(t, events, 2)
+		assert.Equal(t, replicate.SSETypeOutput, events[0].Type)
+		assert.Equal(t, "Hello, Alice", events[0].Data)
+		assert.Equal(t, replicate.SSETypeDone, events[1].Type)
+	})
+
+	t.Run("when connecting fails", func(t *testing.T) {
+		polls = 0
+		p := &replicate.Prediction{
+			ID:     "ufawqhfynnddngldkgtslldrkq",
+			Status: replicate.Starting,
+			URLs:   map[string]string{"stream": ts.URL + "/stream"},
+		}
+
+		r, err := c.StreamPredictionText(ctx, p)
+		require.NoError(t, err)
+		t.Cleanup(func() { r.Close() })
+
+		text, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, Alice", string(text))
+	})
+
+	require.Len(t, fallbacks, 2)
+}
+
+func TestWithoutStreamFallback(t *testing.T) {
+	c, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithoutStreamFallback(),
+	)
+	require.NoError(t, err)
+
+	_, err = c.StreamPredictionText(context.Background(), &replicate.Prediction{})
+	assert.ErrorContains(t, err, "streaming not supported")
+}
+
+func TestStreamStats(t *testing.T) {
+	connections := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections++
+		switch connections {
+		case 1:
+			// Drop the connection before the stream is done.
+			fmt.Fprint(w, "id: 1\nevent: output\ndata: foo\n\n")
+		case 2:
+			// Stall until the client gives up on the connection.
+			assert.Equal(t, "1", r.Header.Get("Last-Event-ID"))
+			fmt.Fprint(w, "id: 2\nevent: output\ndata: bar\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			assert.Equal(t, "2", r.Header.Get("Last-Event-ID"))
+			fmt.Fprint(w, "id: 3\nevent: done\ndata: {}\n\n")
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithStreamStallTimeout(50*time.Millisecond),
+		replicate.WithRetryPolicy(2, &replicate.ConstantBackoff{Base: 10 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+
+	p := &replicate.Prediction{URLs: map[string]string{"stream": ts.URL}}
+	sseChan, errChan := c.StreamPrediction(ctx, p)
+
+	var output []string
+	for event := range sseChan {
+		if event.Type == replicate.SSETypeDone {
+			break
+		}
+		output = append(output, event.Data)
+	}
+	select {
+	case err := <-errChan:
+		require.NoError(t, err)
+	default:
+	}
+	assert.Equal(t, []string{"foo", "bar"}, output)
+
+	require.Eventually(t, func() bool { return c.StreamStats().Active == 0 }, time.Second, 10*time.Millisecond)
+	stats := c.StreamStats()
+	assert.EqualValues(t, 3, stats.Started)
+	assert.EqualValues(t, 2, stats.Reconnects)
+	assert.EqualValues(t, 1, stats.Stalls)
+	assert.Equal(t, map[string]uint64{"output": 2, "done": 1}, stats.Events)
+}
+
+func TestStreamStallTimeoutSlowConsumer(t *testing.T) {
+	const events = 100
+	var connections atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections.Add(1)
+		for i := 1; i <= events; i++ {
+			fmt.Fprintf(w, "id: %d\nevent: output\ndata: %d\n\n", i, i)
+		}
+		w.(http.Flusher).Flush()
+		// Keep the connection open and healthy while the consumer catches
+		// up.
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprintf(w, "id: %d\nevent: done\ndata: {}\n\n", events+1)
+	}))
+	t.Cleanup(ts.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithStreamStallTimeout(200*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	p := &replicate.Prediction{URLs: map[string]string{"stream": ts.URL}}
+	sseChan, _ := c.StreamPrediction(ctx, p)
+
+	// The consumer falls behind for longer than the stall timeout, filling
+	// the event buffer, while the server is healthy.
+	var output []string
+	for event := range sseChan {
+		if event.Type == replicate.SSETypeDone {
+			break
+		}
+		if len(output) == 0 {
+			time.Sleep(400 * time.Millisecond)
+		}
+		output = append(output, event.Data)
+	}
+	assert.Len(t, output, events)
+
+	require.Eventually(t, func() bool { return c.StreamStats().Active == 0 }, time.Second, 10*time.Millisecond)
+	stats := c.StreamStats()
+	assert.EqualValues(t, 0, stats.Stalls)
+	assert.EqualValues(t, 0, stats.Reconnects)
+	assert.EqualValues(t, 1, connections.Load())
+}
+
+func TestStreamReconnectLimit(t *testing.T) {
+	var connections atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Close every connection before the stream is done.
+		n := connections.Add(1)
+		fmt.Fprintf(w, "id: %d\nevent: output\ndata: %d\n\n", n, n)
+	}))
+	t.Cleanup(ts.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithRetryPolicy(2, &replicate.ConstantBackoff{Base: 10 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+
+	p := &replicate.Prediction{URLs: map[string]string{"stream": ts.URL}}
+	sseChan, errChan := c.StreamPrediction(ctx, p)
+
+	var output []string
+	for event := range sseChan {
+		output = append(output, event.Data)
+	}
+	err = <-errChan
+	require.Error(t, err)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Contains(t, err.Error(), "after 2 attempts")
+	assert.Equal(t, []string{"1", "2", "3"}, output)
+	assert.EqualValues(t, 3, connections.Load())
+	assert.EqualValues(t, 2, c.StreamStats().Reconnects)
+}
@@ -0,0 +1,52 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrPredictionStuck is matched by errors for predictions that stayed in the
+// starting status for longer than BatchOptions.StartTimeout allows.
+var ErrPredictionStuck = errors.New("prediction stuck starting")
+
+// waitForRun waits for a prediction created by runModelRef. With a start
+// timeout, a prediction that's still starting when it expires is canceled,
+// and waitForRun returns an error matching ErrPredictionStuck.
+func (r *Client) waitForRun(ctx context.Context, prediction *Prediction, options *fallbackOptions) error {
+	if options.startTimeout <= 0 || prediction.Status != Starting {
+		return r.Wait(ctx, prediction, WithPollingInterval(options.interval))
+	}
+
+	id := prediction.ID
+	stuck := fmt.Errorf("%w: prediction %s didn't start within %s", ErrPredictionStuck, id, options.startTimeout)
+	waitCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var started atomic.Bool
+	timer := r.clock().AfterFunc(options.startTimeout, func() {
+		if !started.Load() {
+			cancel(stuck)
+		}
+	})
+	defer timer.Stop()
+
+	predChan, errChan := r.WaitAsync(waitCtx, prediction, WithPollingInterval(options.interval))
+	for p := range predChan {
+		if p.Status != Starting {
+			started.Store(true)
+		}
+	}
+
+	err := <-errChan
+	if err != nil && ctx.Err() == nil && errors.Is(context.Cause(waitCtx), ErrPredictionStuck) {
+		// Don't leave the stuck prediction to start later and bill.
+		cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		_, _ = r.CancelPrediction(cancelCtx, id)
+		return stuck
+	}
+	return err
+}
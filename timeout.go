@@ -0,0 +1,159 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// timeouts holds the per-attempt timeouts for each operation class.
+// A zero value means no timeout beyond the request's context.
+type timeouts struct {
+	fallback time.Duration
+	create   time.Duration
+	poll     time.Duration
+	upload   time.Duration
+}
+
+type callTimeoutContextKey struct{}
+
+func positiveTimeout(name string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("%s timeout must be positive, got %s", name, d)
+	}
+	return nil
+}
+
+// WithTimeout sets the timeout for each request attempt made by the client,
+// for operations without a more specific timeout. Retried requests get a
+// fresh timeout for every attempt.
+//
+// Unlike http.Client.Timeout, this doesn't apply to streams or to downloads
+// of prediction output files.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if err := positiveTimeout("request", d); err != nil {
+			return err
+		}
+		o.timeouts.fallback = d
+		return nil
+	}
+}
+
+// WithCreateTimeout sets the timeout for each attempt to create a prediction,
+// training, model, or deployment.
+func WithCreateTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if err := positiveTimeout("create", d); err != nil {
+			return err
+		}
+		o.timeouts.create = d
+		return nil
+	}
+}
+
+// WithPollTimeout sets the timeout for each attempt to get a prediction or
+// training, as done repeatedly while waiting for one to finish.
+func WithPollTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if err := positiveTimeout("poll", d); err != nil {
+			return err
+		}
+		o.timeouts.poll = d
+		return nil
+	}
+}
+
+// WithUploadTimeout sets the timeout for each attempt to upload a file.
+func WithUploadTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if err := positiveTimeout("upload", d); err != nil {
+			return err
+		}
+		o.timeouts.upload = d
+		return nil
+	}
+}
+
+// WithCallTimeout returns a context that sets the timeout for each request
+// attempt of the calls made with it, overriding the client's timeouts.
+//
+// To bound the total time of a call, including retries, use a context
+// deadline instead.
+func WithCallTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, callTimeoutContextKey{}, d)
+}
+
+// timeoutFor returns the per-attempt timeout for an operation.
+func (r *Client) timeoutFor(ctx context.Context, op operation) time.Duration {
+	if d, ok := ctx.Value(callTimeoutContextKey{}).(time.Duration); ok && d > 0 {
+		return d
+	}
+
+	t := r.options.timeouts
+	var d time.Duration
+	switch op.class {
+	case operationClassCreate:
+		d = t.create
+	case operationClassPoll:
+		d = t.poll
+	case operationClassUpload:
+		d = t.upload
+	case operationClassDefault:
+	}
+	if d == 0 {
+		d = t.fallback
+	}
+	return d
+}
+
+// withAttemptTimeout returns a copy of request whose context times out after
+// d, or the request itself if d is zero. The returned function must be
+// called once the response body has been read.
+func withAttemptTimeout(request *http.Request, d time.Duration) (*http.Request, context.CancelFunc) {
+	if d <= 0 {
+		return request, func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), d)
+	return request.WithContext(ctx), cancel
+}
+
+// classifiedError adds ErrCanceled or ErrDeadlineExceeded to the chain of an
+// error without changing its message.
+type classifiedError struct {
+	class error
+	err   error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.err, e.class}
+}
+
+// classifyError marks errors caused by cancellation or a timeout so callers
+// can tell them apart with errors.Is. Other errors are returned unchanged.
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, ErrCanceled) || errors.Is(err, ErrDeadlineExceeded) {
+		return err
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return &classifiedError{class: ErrCanceled, err: err}
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return &classifiedError{class: ErrDeadlineExceeded, err: err}
+	}
+
+	return err
+}
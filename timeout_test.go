@@ -0,0 +1,121 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestOperationTimeouts(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(1, &replicate.ConstantBackoff{}),
+		replicate.WithPollTimeout(50*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The poll timeout doesn't apply to other operations.
+	_, err = client.CreatePredictionWithModel(ctx, "owner", "model", replicate.PredictionInput{}, nil, false)
+	assert.NoError(t, err)
+
+	// A per-call timeout overrides the client's timeouts.
+	_, err = client.GetPrediction(replicate.WithCallTimeout(ctx, time.Second), "ufawqhfynnddngldkgtslldrkq")
+	assert.NoError(t, err)
+}
+
+func TestTimeoutMustBePositive(t *testing.T) {
+	_, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithUploadTimeout(0),
+	)
+	assert.Error(t, err)
+}
+
+func TestTimeoutErrorClassification(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/predictions/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		case "/predictions/gateway":
+			w.WriteHeader(http.StatusGatewayTimeout)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := client.GetPrediction(ctx, "slow")
+		assert.ErrorIs(t, err, replicate.ErrCanceled)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, replicate.ErrDeadlineExceeded)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := client.GetPrediction(ctx, "slow")
+		assert.ErrorIs(t, err, replicate.ErrDeadlineExceeded)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, replicate.ErrCanceled)
+	})
+
+	t.Run("call timeout", func(t *testing.T) {
+		ctx := replicate.WithCallTimeout(context.Background(), 10*time.Millisecond)
+
+		_, err := client.GetPrediction(ctx, "slow")
+		assert.ErrorIs(t, err, replicate.ErrDeadlineExceeded)
+	})
+
+	t.Run("server timeout", func(t *testing.T) {
+		_, err := client.GetPrediction(context.Background(), "gateway")
+		assert.ErrorIs(t, err, replicate.ErrServerTimeout)
+		assert.NotErrorIs(t, err, replicate.ErrDeadlineExceeded)
+	})
+
+	t.Run("waiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := client.Wait(ctx, &replicate.Prediction{ID: "slow"})
+		assert.ErrorIs(t, err, replicate.ErrCanceled)
+	})
+}
@@ -0,0 +1,164 @@
+package replicate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxRecentTokens is how many of the tokens a client has used are kept, so
+// they're redacted from logs after they rotate.
+const maxRecentTokens = 4
+
+// TokenProvider supplies the API token for each request. Use one instead of
+// WithToken when tokens rotate, such as tokens read from a secret manager,
+// so the client doesn't have to be recreated.
+type TokenProvider interface {
+	// Token returns the token to use for a request made with ctx.
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenProviderFunc is a function that implements TokenProvider.
+type TokenProviderFunc func(ctx context.Context) (string, error)
+
+// Token calls f(ctx).
+func (f TokenProviderFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// tokenInvalidator is implemented by providers that cache tokens. The client
+// invalidates the cache when the API rejects a token.
+type tokenInvalidator interface {
+	Invalidate()
+}
+
+// WithTokenProvider sets a provider that's consulted for the auth token on
+// every request, instead of a fixed token.
+//
+// If the API responds with 401 Unauthorized and the provider caches tokens,
+// like RotatingToken, the cached token is discarded so the next request
+// fetches a new one.
+func WithTokenProvider(provider TokenProvider) ClientOption {
+	return func(o *clientOptions) error {
+		o.tokenProvider = provider
+		return nil
+	}
+}
+
+// StaticToken returns a provider that always returns token.
+func StaticToken(token string) TokenProvider {
+	return staticToken(token)
+}
+
+type staticToken string
+
+func (t staticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// EnvToken returns a provider that reads the token from the environment
+// variable name on every request, or from REPLICATE_API_TOKEN if name is
+// empty.
+func EnvToken(name string) TokenProvider {
+	if name == "" {
+		name = envAuthToken
+	}
+	return TokenProviderFunc(func(context.Context) (string, error) {
+		token, ok := os.LookupEnv(name)
+		switch {
+		case !ok && name == envAuthToken:
+			return "", ErrEnvVarNotSet
+		case token == "" && name == envAuthToken:
+			return "", ErrEnvVarEmpty
+		case !ok:
+			return "", fmt.Errorf("%s environment variable not set", name)
+		case token == "":
+			return "", fmt.Errorf("%s environment variable is empty", name)
+		}
+		return token, nil
+	})
+}
+
+// RotatingToken is a TokenProvider that caches the token from a slower
+// source, such as Vault or AWS Secrets Manager, and fetches it again once
+// it's older than the TTL.
+type RotatingToken struct {
+	source TokenProvider
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	token     string
+	fetchedAt time.Time
+}
+
+// NewRotatingToken returns a provider that caches the token from source for
+// ttl. A ttl of zero caches the token until it's invalidated.
+func NewRotatingToken(source TokenProvider, ttl time.Duration) *RotatingToken {
+	return &RotatingToken{source: source, ttl: ttl}
+}
+
+// Token returns the cached token, fetching it from the source if it has
+// expired. Concurrent calls share a single fetch.
+func (t *RotatingToken) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && (t.ttl == 0 || time.Since(t.fetchedAt) < t.ttl) {
+		return t.token, nil
+	}
+
+	token, err := t.source.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	t.token = token
+	t.fetchedAt = time.Now()
+	return token, nil
+}
+
+// Invalidate discards the cached token, so the next call fetches it again.
+func (t *RotatingToken) Invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = ""
+}
+
+// token returns the token for a request made with ctx.
+func (r *Client) token(ctx context.Context) (string, error) {
+	token, err := r.options.tokenProvider.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get auth token: %w", err)
+	}
+	if token == "" {
+		return "", ErrNoAuth
+	}
+
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	for _, t := range r.state.tokens {
+		if t == token {
+			return token, nil
+		}
+	}
+	r.state.tokens = append(r.state.tokens, token)
+	if len(r.state.tokens) > maxRecentTokens {
+		r.state.tokens = r.state.tokens[1:]
+	}
+	return token, nil
+}
+
+// recentTokens returns the tokens the client has used recently.
+func (r *Client) recentTokens() []string {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	return append([]string(nil), r.state.tokens...)
+}
+
+// invalidateToken discards a cached token after the API rejected it.
+func (r *Client) invalidateToken() {
+	if invalidator, ok := r.options.tokenProvider.(tokenInvalidator); ok {
+		invalidator.Invalidate()
+	}
+}
@@ -0,0 +1,111 @@
+package replicate_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestTokenProvider(t *testing.T) {
+	var mu sync.Mutex
+	valid := "r8_token-1"
+	var seen []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		auth := r.Header.Get("Authorization")
+		seen = append(seen, auth)
+		if auth != "Bearer "+valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]any{"detail": "Invalid token.", "status": 401})
+			return
+		}
+		json.NewEncoder(w).Encode(&replicate.Account{Username: "alice"})
+	}))
+	defer mockServer.Close()
+
+	fetches := 0
+	source := replicate.TokenProviderFunc(func(context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		return fmt.Sprintf("r8_token-%d", fetches), nil
+	})
+
+	var debug bytes.Buffer
+	client, err := replicate.NewClient(
+		replicate.WithTokenProvider(replicate.NewRotatingToken(source, time.Hour)),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithDebug(&debug),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = client.GetCurrentAccount(ctx)
+	require.NoError(t, err)
+	_, err = client.GetCurrentAccount(ctx)
+	require.NoError(t, err)
+
+	// The secret rotates, and the cached token is rejected once.
+	mu.Lock()
+	valid = "r8_token-2"
+	mu.Unlock()
+	_, err = client.GetCurrentAccount(ctx)
+	assert.ErrorContains(t, err, "Invalid token.")
+	_, err = client.GetCurrentAccount(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Bearer r8_token-1", "Bearer r8_token-1", "Bearer r8_token-1", "Bearer r8_token-2"}, seen)
+	assert.NotContains(t, debug.String(), "r8_token-1")
+	assert.NotContains(t, debug.String(), "r8_token-2")
+}
+
+func TestTokenProviderError(t *testing.T) {
+	errVault := errors.New("vault is sealed")
+	client, err := replicate.NewClient(
+		replicate.WithTokenProvider(replicate.TokenProviderFunc(func(context.Context) (string, error) {
+			return "", errVault
+		})),
+		replicate.WithBaseURL("http://replicate.invalid"),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetCurrentAccount(context.Background())
+	assert.ErrorIs(t, err, errVault)
+
+	_, err = replicate.NewClient(replicate.WithToken(""))
+	assert.ErrorIs(t, err, replicate.ErrNoAuth)
+}
+
+func TestEnvToken(t *testing.T) {
+	provider := replicate.EnvToken("TEST_REPLICATE_TOKEN")
+
+	_, err := provider.Token(context.Background())
+	assert.ErrorContains(t, err, "TEST_REPLICATE_TOKEN environment variable not set")
+
+	t.Setenv("TEST_REPLICATE_TOKEN", "r8_first")
+	token, err := provider.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "r8_first", token)
+
+	t.Setenv("TEST_REPLICATE_TOKEN", "r8_second")
+	token, err = provider.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "r8_second", token)
+
+	token, err = replicate.StaticToken("r8_static").Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "r8_static", token)
+}
@@ -11,17 +11,18 @@ type TrainingInput PredictionInput
 
 // CreateTraining sends a request to the Replicate API to create a new training.
 func (r *Client) CreateTraining(ctx context.Context, modelOwner string, modelName string, version string, destination string, input TrainingInput, webhook *Webhook) (*Training, error) {
+	if err := PredictionInput(input).Validate(); err != nil {
+		return nil, fmt.Errorf("failed to create training: %w", err)
+	}
+
 	data := map[string]interface{}{
 		"version":     version,
 		"destination": destination,
 		"input":       input,
 	}
 
-	if webhook != nil {
-		data["webhook"] = webhook.URL
-		if len(webhook.Events) > 0 {
-			data["webhook_events_filter"] = webhook.Events
-		}
+	if err := r.setWebhook(data, webhook); err != nil {
+		return nil, fmt.Errorf("failed to create training: %w", err)
 	}
 
 	training := &Training{}
@@ -35,9 +36,8 @@ func (r *Client) CreateTraining(ctx context.Context, modelOwner string, modelNam
 }
 
 // ListTrainings returns a list of trainings.
-func (r *Client) ListTrainings(ctx context.Context) (*Page[Training], error) {
-	response := &Page[Training]{}
-	err := r.fetch(ctx, http.MethodGet, "/trainings", nil, response)
+func (r *Client) ListTrainings(ctx context.Context, opts ...ListOption) (*Page[Training], error) {
+	response, err := listPage[Training](ctx, r, "/trainings", opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list trainings: %w", err)
 	}
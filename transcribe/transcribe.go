@@ -0,0 +1,258 @@
+// Package transcribe runs Whisper-style speech-to-text models on Replicate
+// and returns typed transcripts, which can be exported as SRT or WebVTT
+// subtitles.
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/replicate/replicate-go"
+)
+
+// Options configure a transcription. Zero values leave the model's
+// defaults.
+type Options struct {
+	// AudioKey is the input the audio file is passed as. The default is
+	// "audio", as openai/whisper and vaibhavs10/incredibly-fast-whisper
+	// expect; victor-upmeet/whisperx expects "audio_file".
+	AudioKey string
+
+	// Language is the language spoken, for models that take a "language"
+	// input. The default is to detect it.
+	Language string
+
+	// Filename is the name the audio is uploaded with by Reader, whose
+	// extension tells the model its format.
+	Filename string
+
+	// Input holds extra model inputs, which take precedence over those
+	// set from the other options.
+	Input replicate.PredictionInput
+
+	// PollingInterval is how often the prediction is polled until it
+	// finishes. The default is the same as for Client.Wait.
+	PollingInterval time.Duration
+}
+
+// Segment is a span of speech.
+type Segment struct {
+	Text  string
+	Start time.Duration
+	End   time.Duration
+
+	// Confidence is the model's confidence in the text, between 0 and 1,
+	// or 0 if the model doesn't report it.
+	Confidence float64
+}
+
+// Transcript is the result of a transcription.
+type Transcript struct {
+	// Text is the full text.
+	Text string
+
+	// Language is the language the model detected or was given, if it
+	// reports it.
+	Language string
+
+	// Segments are the timed spans of the text, if the model reports
+	// them.
+	Segments []Segment
+
+	// Prediction is the finished prediction.
+	Prediction *replicate.Prediction
+}
+
+// File uploads the audio file at path and transcribes it with model. The
+// upload is deleted once the prediction finishes.
+func File(ctx context.Context, client *replicate.Client, model replicate.ModelRef, path string, opts Options) (*Transcript, error) {
+	file, err := client.CreateFileFromPath(ctx, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload audio: %w", err)
+	}
+	defer deleteAudio(ctx, client, file)
+	return run(ctx, client, model, file, opts)
+}
+
+// Reader uploads the audio read from r and transcribes it with model. The
+// upload is deleted once the prediction finishes.
+func Reader(ctx context.Context, client *replicate.Client, model replicate.ModelRef, r io.Reader, opts Options) (*Transcript, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+	file, err := client.CreateFileFromBytes(ctx, data, &replicate.CreateFileOptions{Filename: opts.Filename})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload audio: %w", err)
+	}
+	defer deleteAudio(ctx, client, file)
+	return run(ctx, client, model, file, opts)
+}
+
+// deleteAudio deletes uploaded audio, even if ctx is done. The transcript
+// doesn't depend on it, so failing to delete it isn't an error.
+func deleteAudio(ctx context.Context, client *replicate.Client, file *replicate.File) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	_ = client.DeleteFile(ctx, file.ID)
+}
+
+func run(ctx context.Context, client *replicate.Client, model replicate.ModelRef, audio *replicate.File, opts Options) (*Transcript, error) {
+	key := opts.AudioKey
+	if key == "" {
+		key = "audio"
+	}
+	input := replicate.PredictionInput{key: audio}
+	if opts.Language != "" {
+		input["language"] = opts.Language
+	}
+	maps.Copy(input, opts.Input)
+
+	var waitOpts []replicate.WaitOption
+	if opts.PollingInterval > 0 {
+		waitOpts = append(waitOpts, replicate.WithPollingInterval(opts.PollingInterval))
+	}
+	prediction, err := client.RunModelRef(ctx, model, input, nil, waitOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	transcript, err := parseOutput(prediction.Output)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected output of prediction %s: %w", prediction.ID, err)
+	}
+	if transcript.Language == "" {
+		transcript.Language = opts.Language
+	}
+	transcript.Prediction = prediction
+	return transcript, nil
+}
+
+// whisperOutput is the union of the output fields of popular Whisper
+// models.
+type whisperOutput struct {
+	// openai/whisper and whisperx
+	Transcription    string `json:"transcription"`
+	DetectedLanguage string `json:"detected_language"`
+	Segments         []struct {
+		Text       string   `json:"text"`
+		Start      float64  `json:"start"`
+		End        float64  `json:"end"`
+		AvgLogprob *float64 `json:"avg_logprob"`
+		Words      []struct {
+			Score *float64 `json:"score"`
+		} `json:"words"`
+	} `json:"segments"`
+
+	// incredibly-fast-whisper
+	Text   string `json:"text"`
+	Chunks []struct {
+		Text      string     `json:"text"`
+		Timestamp []*float64 `json:"timestamp"`
+	} `json:"chunks"`
+}
+
+func parseOutput(output replicate.PredictionOutput) (*Transcript, error) {
+	if text, ok := output.(string); ok {
+		return &Transcript{Text: strings.TrimSpace(text)}, nil
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+	var out whisperOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+
+	t := &Transcript{Language: out.DetectedLanguage}
+	for _, s := range out.Segments {
+		segment := Segment{
+			Text:  strings.TrimSpace(s.Text),
+			Start: seconds(s.Start),
+			End:   seconds(s.End),
+		}
+		if s.AvgLogprob != nil {
+			segment.Confidence = math.Exp(*s.AvgLogprob)
+		} else {
+			var sum float64
+			var n int
+			for _, w := range s.Words {
+				if w.Score != nil {
+					sum += *w.Score
+					n++
+				}
+			}
+			if n > 0 {
+				segment.Confidence = sum / float64(n)
+			}
+		}
+		t.Segments = append(t.Segments, segment)
+	}
+	for _, c := range out.Chunks {
+		segment := Segment{Text: strings.TrimSpace(c.Text)}
+		if len(c.Timestamp) > 0 && c.Timestamp[0] != nil {
+			segment.Start = seconds(*c.Timestamp[0])
+		}
+		// The last chunk may have no end time.
+		if len(c.Timestamp) > 1 && c.Timestamp[1] != nil {
+			segment.End = seconds(*c.Timestamp[1])
+		} else {
+			segment.End = segment.Start
+		}
+		t.Segments = append(t.Segments, segment)
+	}
+
+	switch {
+	case out.Transcription != "":
+		t.Text = strings.TrimSpace(out.Transcription)
+	case out.Text != "":
+		t.Text = strings.TrimSpace(out.Text)
+	default:
+		texts := make([]string, len(t.Segments))
+		for i, s := range t.Segments {
+			texts[i] = s.Text
+		}
+		t.Text = strings.Join(texts, " ")
+	}
+	if t.Text == "" && len(t.Segments) == 0 {
+		return nil, fmt.Errorf("no transcript in output of type %T", output)
+	}
+	return t, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
+
+// SRT returns the segments as SubRip subtitles.
+func (t *Transcript) SRT() string {
+	var b strings.Builder
+	for i, s := range t.Segments {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, timestamp(s.Start, ","), timestamp(s.End, ","), s.Text)
+	}
+	return b.String()
+}
+
+// VTT returns the segments as WebVTT subtitles.
+func (t *Transcript) VTT() string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, s := range t.Segments {
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", timestamp(s.Start, "."), timestamp(s.End, "."), s.Text)
+	}
+	return b.String()
+}
+
+// timestamp formats d as hh:mm:ss followed by sep and milliseconds.
+func timestamp(d time.Duration, sep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
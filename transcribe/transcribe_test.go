@@ -0,0 +1,130 @@
+package transcribe_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/transcribe"
+)
+
+func newServer(t *testing.T, path string, output any, input *replicate.PredictionInput, deleted *[]string) *httptest.Server {
+	mockServer := httptest.NewUnstartedServer(nil)
+	mockServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			file, header, err := r.FormFile("content")
+			require.NoError(t, err)
+			file.Close()
+			json.NewEncoder(w).Encode(&replicate.File{
+				ID:   "file-1",
+				Name: header.Filename,
+				URLs: map[string]string{"get": mockServer.URL + "/files/file-1"},
+			})
+		case r.Method == http.MethodPost && r.URL.Path == path:
+			var body struct {
+				Input replicate.PredictionInput `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			*input = body.Input
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "prediction-1", Status: replicate.Starting})
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/files/"):
+			*deleted = append(*deleted, strings.TrimPrefix(r.URL.Path, "/files/"))
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/predictions/prediction-1":
+			json.NewEncoder(w).Encode(&replicate.Prediction{ID: "prediction-1", Status: replicate.Succeeded, Output: output})
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	mockServer.Start()
+	return mockServer
+}
+
+func TestFile(t *testing.T) {
+	var input replicate.PredictionInput
+	var deleted []string
+	mockServer := newServer(t, "/models/openai/whisper/predictions", map[string]any{
+		"detected_language": "english",
+		"transcription":     " Hello there. General Kenobi.",
+		"segments": []map[string]any{
+			{"text": " Hello there.", "start": 0.0, "end": 1.5, "avg_logprob": math.Log(0.9)},
+			{"text": " General Kenobi.", "start": 1.5, "end": 3723.25, "avg_logprob": math.Log(0.5)},
+		},
+	}, &input, &deleted)
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "speech.mp3")
+	require.NoError(t, os.WriteFile(path, []byte("ID3 audio"), 0o644))
+
+	transcript, err := transcribe.File(context.Background(), client, replicate.ModelRef{Identifier: "openai/whisper"}, path, transcribe.Options{
+		PollingInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, replicate.PredictionInput{"audio": mockServer.URL + "/files/file-1"}, input)
+	assert.Equal(t, []string{"file-1"}, deleted)
+	assert.Equal(t, "Hello there. General Kenobi.", transcript.Text)
+	assert.Equal(t, "english", transcript.Language)
+	require.Len(t, transcript.Segments, 2)
+	assert.Equal(t, "Hello there.", transcript.Segments[0].Text)
+	assert.Equal(t, 1500*time.Millisecond, transcript.Segments[0].End)
+	assert.InDelta(t, 0.9, transcript.Segments[0].Confidence, 1e-9)
+
+	assert.Equal(t, "1\n00:00:00,000 --> 00:00:01,500\nHello there.\n\n"+
+		"2\n00:00:01,500 --> 01:02:03,250\nGeneral Kenobi.\n\n", transcript.SRT())
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:01.500\nHello there.\n\n"+
+		"00:00:01.500 --> 01:02:03.250\nGeneral Kenobi.\n\n", transcript.VTT())
+}
+
+func TestReaderWithChunks(t *testing.T) {
+	var input replicate.PredictionInput
+	var deleted []string
+	mockServer := newServer(t, "/models/vaibhavs10/incredibly-fast-whisper/predictions", map[string]any{
+		"text": " One. Two.",
+		"chunks": []map[string]any{
+			{"text": " One.", "timestamp": []any{0.0, 0.8}},
+			{"text": " Two.", "timestamp": []any{0.8, nil}},
+		},
+	}, &input, &deleted)
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	transcript, err := transcribe.Reader(context.Background(), client, replicate.ModelRef{Identifier: "vaibhavs10/incredibly-fast-whisper"}, strings.NewReader("RIFF audio"), transcribe.Options{
+		Filename:        "speech.wav",
+		Language:        "english",
+		PollingInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "english", input["language"])
+	assert.Equal(t, []string{"file-1"}, deleted)
+	assert.Equal(t, "One. Two.", transcript.Text)
+	assert.Equal(t, "english", transcript.Language)
+	assert.Equal(t, []transcribe.Segment{
+		{Text: "One.", Start: 0, End: 800 * time.Millisecond},
+		{Text: "Two.", Start: 800 * time.Millisecond, End: 800 * time.Millisecond},
+	}, transcript.Segments)
+}
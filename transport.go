@@ -0,0 +1,224 @@
+package replicate
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// The default transport keeps more idle connections to the API than
+// http.DefaultTransport, which keeps two per host, so that polling many
+// predictions at once doesn't keep opening new connections.
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 32
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 30 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+)
+
+// sharedTransport is used by clients created without WithHTTPClient, so
+// they share a connection pool.
+var sharedTransport = sync.OnceValue(func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = defaultMaxIdleConns
+	t.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	t.IdleConnTimeout = defaultIdleConnTimeout
+	t.ForceAttemptHTTP2 = true
+	return t
+})
+
+// transportOptions configure the client's HTTP transport.
+type transportOptions struct {
+	proxy       func(*http.Request) (*url.URL, error)
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	tlsConfig   *tls.Config
+
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+	keepAlive           *time.Duration
+	http2               *bool
+}
+
+func (t transportOptions) isSet() bool {
+	return t.proxy != nil || t.dialContext != nil || t.tlsConfig != nil ||
+		t.maxIdleConnsPerHost > 0 || t.idleConnTimeout > 0 || t.keepAlive != nil || t.http2 != nil
+}
+
+// WithProxy sends requests through the proxy at proxyURL, which can be an
+// http, https, socks5, or socks5h URL. Credentials can be given in the URL's
+// user info. By default, the client uses the proxy set by the HTTP_PROXY,
+// HTTPS_PROXY, and NO_PROXY environment variables.
+func WithProxy(proxyURL string) ClientOption {
+	return func(o *clientOptions) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("invalid proxy URL %q: scheme must be http, https, socks5, or socks5h", u.Redacted())
+		}
+		if u.Hostname() == "" {
+			return fmt.Errorf("invalid proxy URL %q: missing host", u.Redacted())
+		}
+		o.transport.proxy = http.ProxyURL(u)
+		return nil
+	}
+}
+
+// WithDialContext sets the function used to open network connections, for
+// example to dial through a custom network stack or to pin addresses.
+func WithDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) ClientOption {
+	return func(o *clientOptions) error {
+		if dial == nil {
+			return errors.New("dial function must not be nil")
+		}
+		o.transport.dialContext = dial
+		return nil
+	}
+}
+
+// WithUnixSocket sends every request over the unix domain socket at path,
+// such as a local sidecar that forwards egress through a policy proxy. The
+// host in the base URL is still sent in the Host header, so set one the
+// sidecar expects with WithBaseURL. Proxies set with WithProxy or the
+// environment are not used.
+//
+// Connections to other hosts, like streaming and file URLs, also go over the
+// socket.
+func WithUnixSocket(path string) ClientOption {
+	return func(o *clientOptions) error {
+		if path == "" {
+			return errors.New("unix socket path must not be empty")
+		}
+		var dialer net.Dialer
+		o.transport.dialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		}
+		o.transport.proxy = noProxy
+		return nil
+	}
+}
+
+// noProxy is a proxy function that connects directly.
+func noProxy(*http.Request) (*url.URL, error) {
+	return nil, nil
+}
+
+// WithTLSConfig sets the TLS configuration used for connections to the API,
+// such as a pool with a corporate CA, or client certificates for mutual TLS.
+// The config is cloned, so later changes to it have no effect.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(o *clientOptions) error {
+		if config == nil {
+			return errors.New("TLS config must not be nil")
+		}
+		o.transport.tlsConfig = config.Clone()
+		return nil
+	}
+}
+
+// WithMaxIdleConnsPerHost sets how many idle connections to the API are kept
+// for reuse. The default is 32. Raise it if the client polls or creates many
+// predictions concurrently.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+	return func(o *clientOptions) error {
+		if n <= 0 {
+			return fmt.Errorf("max idle connections per host must be positive, got %d", n)
+		}
+		o.transport.maxIdleConnsPerHost = n
+		return nil
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept before it's
+// closed. The default is 90 seconds.
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if d <= 0 {
+			return fmt.Errorf("idle connection timeout must be positive, got %s", d)
+		}
+		o.transport.idleConnTimeout = d
+		return nil
+	}
+}
+
+// WithKeepAlive sets the interval between TCP keep-alive probes on
+// connections to the API. The default is 30 seconds; a negative interval
+// disables keep-alive probes. It has no effect with WithDialContext.
+func WithKeepAlive(d time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		o.transport.keepAlive = &d
+		return nil
+	}
+}
+
+// WithHTTP2 sets whether the client uses HTTP/2 when the server supports it.
+// It's enabled by default. Disabling it can help with proxies that handle
+// HTTP/2 poorly.
+func WithHTTP2(enabled bool) ClientOption {
+	return func(o *clientOptions) error {
+		o.transport.http2 = &enabled
+		return nil
+	}
+}
+
+// configureTransport returns a copy of httpClient whose transport is a clone
+// of its own, with the transport options applied. It returns httpClient
+// unchanged if no transport options are set.
+func configureTransport(httpClient *http.Client, opts transportOptions) (*http.Client, error) {
+	if !opts.isSet() {
+		return httpClient, nil
+	}
+
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("proxy, dialer, and TLS options require the HTTP client's transport to be an *http.Transport, got %T", base)
+	}
+
+	transport := t.Clone()
+	if opts.proxy != nil {
+		transport.Proxy = opts.proxy
+	}
+	if opts.dialContext != nil {
+		transport.DialContext = opts.dialContext
+	} else if opts.keepAlive != nil {
+		dialer := &net.Dialer{Timeout: defaultDialTimeout, KeepAlive: *opts.keepAlive}
+		transport.DialContext = dialer.DialContext
+	}
+	if opts.tlsConfig != nil {
+		transport.TLSClientConfig = opts.tlsConfig
+	}
+	if opts.maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = opts.maxIdleConnsPerHost
+		if transport.MaxIdleConns != 0 && transport.MaxIdleConns < opts.maxIdleConnsPerHost {
+			transport.MaxIdleConns = opts.maxIdleConnsPerHost
+		}
+	}
+	if opts.idleConnTimeout > 0 {
+		transport.IdleConnTimeout = opts.idleConnTimeout
+	}
+	if opts.http2 != nil {
+		transport.ForceAttemptHTTP2 = *opts.http2
+		if !*opts.http2 {
+			// A non-nil, empty map disables HTTP/2.
+			transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		}
+	}
+
+	configured := *httpClient
+	configured.Transport = transport
+	return &configured, nil
+}
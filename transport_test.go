@@ -0,0 +1,186 @@
+package replicate_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func accountHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/account", r.URL.Path)
+		json.NewEncoder(w).Encode(&replicate.Account{Type: "user", Username: "alice"})
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.Host)
+		accountHandler(t)(w, r)
+	}))
+	defer proxy.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL("http://replicate.invalid/v1"),
+		replicate.WithProxy(proxy.URL),
+	)
+	require.NoError(t, err)
+
+	account, err := client.GetCurrentAccount(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "alice", account.Username)
+	assert.Equal(t, "replicate.invalid", proxied.Load())
+
+	for _, proxyURL := range []string{"ftp://proxy.local", "http://", "socks5://user:secret@:1080"} {
+		_, err := replicate.NewClient(replicate.WithToken("test-token"), replicate.WithProxy(proxyURL))
+		assert.ErrorContains(t, err, "invalid proxy URL", proxyURL)
+		assert.NotContains(t, err.Error(), "secret")
+	}
+}
+
+func TestWithDialContext(t *testing.T) {
+	mockServer := httptest.NewServer(accountHandler(t))
+	defer mockServer.Close()
+
+	var dials atomic.Int32
+	var dialer net.Dialer
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL("http://api.replicate.internal/v1"),
+		replicate.WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials.Add(1)
+			assert.Equal(t, "api.replicate.internal:80", addr)
+			return dialer.DialContext(ctx, network, mockServer.Listener.Addr().String())
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetCurrentAccount(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), dials.Load())
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	mockServer := httptest.NewTLSServer(accountHandler(t))
+	defer mockServer.Close()
+
+	// The server's certificate isn't trusted by default.
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL+"/v1"),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+	)
+	require.NoError(t, err)
+	_, err = client.GetCurrentAccount(context.Background())
+	require.Error(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(mockServer.Certificate())
+	client, err = replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL+"/v1"),
+		replicate.WithTLSConfig(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}),
+	)
+	require.NoError(t, err)
+	_, err = client.GetCurrentAccount(context.Background())
+	require.NoError(t, err)
+}
+
+func TestTransportOptionsRequireTransport(t *testing.T) {
+	httpClient := &http.Client{Transport: replicate.RoundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, nil
+	})}
+	_, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithHTTPClient(httpClient),
+		replicate.WithProxy("http://proxy.local:3128"),
+	)
+	assert.ErrorContains(t, err, "require the HTTP client's transport to be an *http.Transport")
+}
+
+func TestWithHTTP2(t *testing.T) {
+	mockServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&replicate.Account{Type: "user", Username: r.Proto})
+	}))
+	mockServer.EnableHTTP2 = true
+	mockServer.StartTLS()
+	defer mockServer.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(mockServer.Certificate())
+	tlsConfig := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	for _, enabled := range []bool{true, false} {
+		client, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(mockServer.URL+"/v1"),
+			replicate.WithTLSConfig(tlsConfig),
+			replicate.WithHTTP2(enabled),
+			replicate.WithMaxIdleConnsPerHost(64),
+			replicate.WithIdleConnTimeout(time.Minute),
+			replicate.WithKeepAlive(15*time.Second),
+		)
+		require.NoError(t, err)
+
+		account, err := client.GetCurrentAccount(context.Background())
+		require.NoError(t, err)
+		if enabled {
+			assert.Equal(t, "HTTP/2.0", account.Username)
+		} else {
+			assert.Equal(t, "HTTP/1.1", account.Username)
+		}
+	}
+
+	_, err := replicate.NewClient(replicate.WithToken("test-token"), replicate.WithMaxIdleConnsPerHost(0))
+	assert.ErrorContains(t, err, "max idle connections per host must be positive")
+	_, err = replicate.NewClient(replicate.WithToken("test-token"), replicate.WithIdleConnTimeout(-time.Second))
+	assert.ErrorContains(t, err, "idle connection timeout must be positive")
+}
+
+func TestWithUnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "replicate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "sidecar.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	var host atomic.Value
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host.Store(r.Host)
+		accountHandler(t)(w, r)
+	}), ReadHeaderTimeout: time.Second}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL("http://sidecar/v1"),
+		replicate.WithUnixSocket(socket),
+	)
+	require.NoError(t, err)
+
+	account, err := client.GetCurrentAccount(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "alice", account.Username)
+	assert.Equal(t, "sidecar", host.Load())
+
+	_, err = replicate.NewClient(replicate.WithToken("test-token"), replicate.WithUnixSocket(""))
+	assert.ErrorContains(t, err, "unix socket path must not be empty")
+}
@@ -0,0 +1,394 @@
+package replicate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxRecordedUsageIDs limits how many prediction IDs the usage ledger keeps
+// to avoid counting a prediction twice.
+const maxRecordedUsageIDs = 10000
+
+// Usage totals the metrics of completed predictions.
+type Usage struct {
+	// Predictions is the number of completed predictions.
+	Predictions uint64
+
+	// InputTokens and OutputTokens are the token counts reported by
+	// language models.
+	InputTokens  int64
+	OutputTokens int64
+
+	// PredictTime is the time spent running the model.
+	PredictTime time.Duration
+}
+
+func (u *Usage) add(record UsageRecord) {
+	u.Predictions++
+	u.InputTokens += int64(record.InputTokens)
+	u.OutputTokens += int64(record.OutputTokens)
+	u.PredictTime += record.PredictTime
+}
+
+// UsageReport is a snapshot of a client's usage ledger.
+type UsageReport struct {
+	Total Usage
+
+	// ByModel breaks down usage by the model that ran each prediction, as
+	// "owner/name", or by version ID for predictions without a model name.
+	ByModel map[string]Usage
+
+	// ByTenant breaks down the usage of predictions attributed to a tenant
+	// with WithTenant, by tenant.
+	ByTenant map[string]Usage
+}
+
+// AccountUsage totals the usage of an account's completed predictions or
+// trainings, with their estimated cost.
+type AccountUsage struct {
+	Usage
+
+	// Cost is the estimated cost in dollars, and Unpriced the number of
+	// predictions whose cost couldn't be estimated.
+	Cost     float64
+	Unpriced uint64
+}
+
+// AccountUsageReport is a report of an account's usage from
+// Client.UsageReport.
+type AccountUsageReport struct {
+	Total AccountUsage
+
+	// ByModel breaks down usage by the model that ran each prediction, as
+	// "owner/name", or by version ID for predictions without a model name.
+	ByModel map[string]AccountUsage
+
+	// ByDeployment breaks down the usage of predictions created through
+	// deployments by deployment, as "owner/name".
+	ByDeployment map[string]AccountUsage
+
+	// Trainings totals the usage of trainings. It isn't included in Total.
+	Trainings AccountUsage
+
+	// Since and Until are the bounds of the report.
+	Since time.Time
+	Until time.Time
+}
+
+// UsageRecord describes the usage of a single completed prediction.
+type UsageRecord struct {
+	PredictionID string
+	Model        string
+	Version      string
+	Status       Status
+	InputTokens  int
+	OutputTokens int
+	PredictTime  time.Duration
+
+	// Tenant is the tenant the prediction is attributed to with
+	// WithTenant, if any.
+	Tenant string
+}
+
+// UsageExporter is called with the usage of each prediction the client sees
+// complete. It's called synchronously and should return quickly.
+type UsageExporter func(record UsageRecord)
+
+// WithUsageExporter sets a function that receives the usage of each
+// completed prediction, for export to an accounting system.
+func WithUsageExporter(exporter UsageExporter) ClientOption {
+	return func(o *clientOptions) error {
+		o.usageExporter = exporter
+		return nil
+	}
+}
+
+// usageLedger accumulates the usage of completed predictions.
+type usageLedger struct {
+	mu       sync.Mutex
+	total    Usage
+	byModel  map[string]*Usage
+	byTenant map[string]*Usage
+
+	// recorded holds the IDs of counted predictions, oldest first.
+	recorded   map[string]struct{}
+	recordedAt []string
+
+	// tenants holds the tenants of running predictions created with
+	// WithTenant, and taggedAt their IDs, oldest first.
+	tenants  map[string]string
+	taggedAt []string
+}
+
+// tag attributes a running prediction to tenant, for when it completes.
+func (l *usageLedger) tag(id, tenant string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tenants == nil {
+		l.tenants = make(map[string]string)
+	}
+	if _, ok := l.tenants[id]; ok {
+		return
+	}
+	l.tenants[id] = tenant
+	l.taggedAt = append(l.taggedAt, id)
+	if len(l.taggedAt) > maxRecordedUsageIDs {
+		delete(l.tenants, l.taggedAt[0])
+		l.taggedAt = l.taggedAt[1:]
+	}
+}
+
+// add records the usage of a prediction, reporting false if it was already
+// recorded. If record has no tenant, it's given the tenant the prediction
+// was tagged with.
+func (l *usageLedger) add(record *UsageRecord) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.recorded == nil {
+		l.recorded = make(map[string]struct{})
+		l.byModel = make(map[string]*Usage)
+		l.byTenant = make(map[string]*Usage)
+	}
+	if _, ok := l.recorded[record.PredictionID]; ok {
+		return false
+	}
+	if tenant, ok := l.tenants[record.PredictionID]; ok {
+		if record.Tenant == "" {
+			record.Tenant = tenant
+		}
+		delete(l.tenants, record.PredictionID)
+	}
+	l.recorded[record.PredictionID] = struct{}{}
+	l.recordedAt = append(l.recordedAt, record.PredictionID)
+	if len(l.recordedAt) > maxRecordedUsageIDs {
+		delete(l.recorded, l.recordedAt[0])
+		l.recordedAt = l.recordedAt[1:]
+	}
+
+	key := record.Model
+	if key == "" {
+		key = record.Version
+	}
+	u, ok := l.byModel[key]
+	if !ok {
+		u = &Usage{}
+		l.byModel[key] = u
+	}
+	u.add(*record)
+	if record.Tenant != "" {
+		t, ok := l.byTenant[record.Tenant]
+		if !ok {
+			t = &Usage{}
+			l.byTenant[record.Tenant] = t
+		}
+		t.add(*record)
+	}
+	l.total.add(*record)
+	return true
+}
+
+// Usage returns the total usage of the predictions the client has seen
+// complete, whether by waiting for them or by getting them.
+//
+// Predictions are only counted once, and only when the client receives them
+// in a terminal state. Predictions returned by list calls aren't counted.
+func (r *Client) Usage() UsageReport {
+	l := &r.state.usage
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	report := UsageReport{
+		Total:    l.total,
+		ByModel:  make(map[string]Usage, len(l.byModel)),
+		ByTenant: make(map[string]Usage, len(l.byTenant)),
+	}
+	for key, u := range l.byModel {
+		report.ByModel[key] = *u
+	}
+	for tenant, u := range l.byTenant {
+		report.ByTenant[tenant] = *u
+	}
+	return report
+}
+
+// recordUsage adds a prediction to the usage ledger if it has completed, or
+// remembers its tenant for when it does.
+func (r *Client) recordUsage(ctx context.Context, prediction *Prediction) {
+	if prediction == nil || prediction.ID == "" {
+		return
+	}
+	tenant := requestTenant(ctx)
+	if !prediction.Status.Terminated() {
+		if tenant != "" {
+			r.state.usage.tag(prediction.ID, tenant)
+		}
+		return
+	}
+
+	record := UsageRecord{
+		PredictionID: prediction.ID,
+		Model:        prediction.Model,
+		Version:      prediction.Version,
+		Status:       prediction.Status,
+		Tenant:       tenant,
+	}
+	if m := prediction.Metrics; m != nil {
+		if m.InputTokenCount != nil {
+			record.InputTokens = *m.InputTokenCount
+		}
+		if m.OutputTokenCount != nil {
+			record.OutputTokens = *m.OutputTokenCount
+		}
+		if m.PredictTime != nil {
+			record.PredictTime = time.Duration(*m.PredictTime * float64(time.Second))
+		}
+	}
+
+	if !r.state.usage.add(&record) {
+		return
+	}
+	r.recordSpend(prediction)
+	if r.options.usageExporter != nil {
+		r.options.usageExporter(record)
+	}
+	if m, ok := r.options.metrics.(UsageMetrics); ok {
+		m.ObserveUsage(record)
+	}
+}
+
+// UsageReport totals the usage of the account's predictions and trainings
+// created from since until until, by listing them. Only completed
+// predictions and trainings are counted. Costs are estimated with
+// EstimateCost and the rates set with WithRateTable, or DefaultRateTable.
+//
+// Listing takes one request per page of 100 items, so reports over long
+// periods of heavy use take a while.
+func (r *Client) UsageReport(ctx context.Context, since, until time.Time) (*AccountUsageReport, error) {
+	if !until.After(since) {
+		return nil, errors.New("until must be after since")
+	}
+	rates := r.rateTable()
+	report := &AccountUsageReport{
+		ByModel:      make(map[string]AccountUsage),
+		ByDeployment: make(map[string]AccountUsage),
+		Since:        since,
+		Until:        until,
+	}
+
+	err := listCreatedBetween(ctx, r, "/predictions", since, until, func(p *Prediction) {
+		usage := predictionUsage(p, rates)
+		report.Total.merge(usage)
+		addUsage(report.ByModel, usageModelKey(p), usage)
+		if deployment := predictionDeployment(p); deployment != "" {
+			addUsage(report.ByDeployment, deployment, usage)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list predictions: %w", err)
+	}
+
+	err = listCreatedBetween(ctx, r, "/trainings", since, until, func(t *Training) {
+		report.Trainings.merge(predictionUsage((*Prediction)(t), rates))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trainings: %w", err)
+	}
+	return report, nil
+}
+
+// listCreatedBetween calls fn with the completed items of a list endpoint
+// created from since until until. Items are listed newest first, so the
+// listing stops at the first item created before since.
+func listCreatedBetween[T Prediction | Training](ctx context.Context, r *Client, path string, since, until time.Time, fn func(*T)) error {
+	createdAt := func(item T) time.Time {
+		p := Prediction(item)
+		t, _ := time.Parse(time.RFC3339Nano, p.CreatedAt)
+		return t
+	}
+	options, err := newListOptions([]ListOption{
+		Until(func(item T) bool { return createdAt(item).Before(since) }),
+	})
+	if err != nil {
+		return err
+	}
+
+	s := newSweep[T](r, options, nil, options.path(path))
+	defer s.stop()
+	for {
+		item, ok, err := s.next(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if createdAt(item).Before(until) && Prediction(item).Status.Terminated() {
+			fn(&item)
+		}
+	}
+}
+
+// predictionUsage returns the usage of a single prediction.
+func predictionUsage(p *Prediction, rates RateTable) AccountUsage {
+	usage := AccountUsage{Usage: Usage{Predictions: 1}}
+	if m := p.Metrics; m != nil {
+		if m.InputTokenCount != nil {
+			usage.InputTokens = int64(*m.InputTokenCount)
+		}
+		if m.OutputTokenCount != nil {
+			usage.OutputTokens = int64(*m.OutputTokenCount)
+		}
+		if m.PredictTime != nil {
+			usage.PredictTime = time.Duration(*m.PredictTime * float64(time.Second))
+		}
+	}
+	if cost, err := EstimateCost(p, rates); err == nil {
+		usage.Cost = cost
+	} else {
+		usage.Unpriced = 1
+	}
+	return usage
+}
+
+func (u *AccountUsage) merge(other AccountUsage) {
+	u.Predictions += other.Predictions
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.PredictTime += other.PredictTime
+	u.Cost += other.Cost
+	u.Unpriced += other.Unpriced
+}
+
+func addUsage(byKey map[string]AccountUsage, key string, usage AccountUsage) {
+	u := byKey[key]
+	u.merge(usage)
+	byKey[key] = u
+}
+
+func usageModelKey(p *Prediction) string {
+	if p.Model != "" {
+		return p.Model
+	}
+	return p.Version
+}
+
+// predictionDeployment returns the deployment a prediction was created
+// through, from the "deployment" field of its JSON, if the API set it.
+func predictionDeployment(p *Prediction) string {
+	if len(p.rawJSON) == 0 {
+		return ""
+	}
+	var fields struct {
+		Deployment string `json:"deployment"`
+	}
+	if err := json.Unmarshal(p.rawJSON, &fields); err != nil {
+		return ""
+	}
+	return fields.Deployment
+}
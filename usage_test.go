@@ -0,0 +1,198 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestUsageLedger(t *testing.T) {
+	predictions := map[string]string{
+		"llm1":    `{"id": "llm1", "model": "meta/llama", "status": "succeeded", "metrics": {"input_token_count": 10, "output_token_count": 20, "predict_time": 1.5}}`,
+		"llm2":    `{"id": "llm2", "model": "meta/llama", "status": "succeeded", "metrics": {"input_token_count": 5, "output_token_count": 7, "predict_time": 0.5}}`,
+		"image":   `{"id": "image", "version": "v1", "status": "failed", "metrics": {"predict_time": 2}}`,
+		"running": `{"id": "running", "model": "meta/llama", "status": "processing"}`,
+	}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/predictions/"):]
+		w.Write([]byte(predictions[id]))
+	}))
+	defer mockServer.Close()
+
+	var exported []replicate.UsageRecord
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithUsageExporter(func(record replicate.UsageRecord) {
+			exported = append(exported, record)
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, id := range []string{"llm1", "llm1", "llm2", "image", "running"} {
+		_, err := client.GetPrediction(ctx, id)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, exported, 3, "predictions are exported once, after they complete")
+	assert.Equal(t, replicate.UsageRecord{
+		PredictionID: "llm1",
+		Model:        "meta/llama",
+		Status:       replicate.Succeeded,
+		InputTokens:  10,
+		OutputTokens: 20,
+		PredictTime:  1500 * time.Millisecond,
+	}, exported[0])
+
+	usage := client.Usage()
+	assert.Equal(t, replicate.Usage{
+		Predictions:  3,
+		InputTokens:  15,
+		OutputTokens: 27,
+		PredictTime:  4 * time.Second,
+	}, usage.Total)
+	assert.EqualValues(t, 2, usage.ByModel["meta/llama"].Predictions)
+	assert.Equal(t, 2*time.Second, usage.ByModel["v1"].PredictTime)
+}
+
+func TestClientUsageReport(t *testing.T) {
+	mockServer := httptest.NewUnstartedServer(nil)
+	mockServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/predictions" && r.URL.Query().Get("cursor") == "":
+			fmt.Fprintf(w, `{"next": %q, "results": [
+				{"id": "new", "model": "meta/meta-llama-3-8b-instruct", "status": "succeeded", "created_at": "2024-07-01T00:00:00Z", "metrics": {"input_token_count": 100, "output_token_count": 100}},
+				{"id": "running", "model": "meta/meta-llama-3-8b-instruct", "status": "processing", "created_at": "2024-06-20T00:00:00Z"},
+				{"id": "llm", "model": "meta/meta-llama-3-8b-instruct", "status": "succeeded", "created_at": "2024-06-15T00:00:00Z", "metrics": {"input_token_count": 1000000, "output_token_count": 1000000, "predict_time": 3}}
+			]}`, mockServer.URL+"/predictions?cursor=2")
+		case r.URL.Path == "/predictions":
+			fmt.Fprint(w, `{"next": null, "results": [
+				{"id": "sdxl", "model": "stability-ai/sdxl", "deployment": "acme/sdxl", "status": "failed", "created_at": "2024-06-10T00:00:00Z", "metrics": {"predict_time": 10}},
+				{"id": "custom", "model": "acme/custom", "status": "succeeded", "created_at": "2024-06-05T00:00:00Z", "metrics": {"predict_time": 1}},
+				{"id": "old", "model": "acme/custom", "status": "succeeded", "created_at": "2024-05-31T23:59:59Z", "metrics": {"predict_time": 1}}
+			]}`)
+		case r.URL.Path == "/trainings":
+			fmt.Fprint(w, `{"next": null, "results": [
+				{"id": "training", "model": "acme/custom", "status": "succeeded", "created_at": "2024-06-02T00:00:00Z", "metrics": {"predict_time": 100}}
+			]}`)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	mockServer.Start()
+	defer mockServer.Close()
+
+	rates := replicate.DefaultRateTable()
+	rates.ModelHardware["stability-ai/sdxl"] = "gpu-a40-large"
+	rates.ModelHardware["acme/custom"] = "gpu-t4"
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRateTable(rates),
+	)
+	require.NoError(t, err)
+
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	report, err := client.UsageReport(context.Background(), since, until)
+	require.NoError(t, err)
+
+	assert.Equal(t, since, report.Since)
+	assert.Equal(t, until, report.Until)
+	assert.Equal(t, uint64(3), report.Total.Predictions)
+	assert.Equal(t, int64(1000000), report.Total.InputTokens)
+	assert.Equal(t, 14*time.Second, report.Total.PredictTime)
+	assert.InDelta(t, 0.05+0.25+0.00725+0.000225, report.Total.Cost, 1e-9)
+
+	assert.Equal(t, []string{"acme/custom", "meta/meta-llama-3-8b-instruct", "stability-ai/sdxl"}, sortedKeys(report.ByModel))
+	assert.InDelta(t, 0.3, report.ByModel["meta/meta-llama-3-8b-instruct"].Cost, 1e-9)
+	assert.Equal(t, []string{"acme/sdxl"}, sortedKeys(report.ByDeployment))
+	assert.InDelta(t, 0.00725, report.ByDeployment["acme/sdxl"].Cost, 1e-9)
+
+	assert.Equal(t, uint64(1), report.Trainings.Predictions)
+	assert.InDelta(t, 0.0225, report.Trainings.Cost, 1e-9)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type usageMetrics struct {
+	replicate.NopMetrics
+	records []replicate.UsageRecord
+}
+
+func (m *usageMetrics) ObserveUsage(record replicate.UsageRecord) {
+	m.records = append(m.records, record)
+}
+
+func TestUsageByTenant(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body struct {
+				Input map[string]string `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			fmt.Fprintf(w, `{"id": %q, "model": "meta/llama", "status": "starting"}`, body.Input["id"])
+			return
+		}
+		id := r.URL.Path[len("/predictions/"):]
+		fmt.Fprintf(w, `{"id": %q, "model": "meta/llama", "status": "succeeded", "metrics": {"input_token_count": 10, "output_token_count": 5, "predict_time": 1}}`, id)
+	}))
+	defer mockServer.Close()
+
+	metrics := &usageMetrics{}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithMetrics(metrics),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, run := range []struct{ id, tenant string }{{"a1", "acme"}, {"a2", "acme"}, {"g1", "globex"}, {"u1", ""}} {
+		createCtx := ctx
+		if run.tenant != "" {
+			createCtx = replicate.WithTenant(ctx, run.tenant)
+		}
+		_, err := client.CreatePrediction(createCtx, "meta/llama", replicate.PredictionInput{"id": run.id}, nil, false)
+		require.NoError(t, err)
+	}
+	// Predictions keep the tenant they were created with, and can be
+	// attributed when they complete.
+	for _, id := range []string{"a1", "a2", "g1", "u1"} {
+		_, err := client.GetPrediction(ctx, id)
+		require.NoError(t, err)
+	}
+	_, err = client.GetPrediction(replicate.WithTenant(ctx, "initech"), "i1")
+	require.NoError(t, err)
+
+	usage := client.Usage()
+	assert.EqualValues(t, 5, usage.Total.Predictions)
+	assert.Equal(t, []string{"acme", "globex", "initech"}, sortedKeys(usage.ByTenant))
+	assert.Equal(t, replicate.Usage{Predictions: 2, InputTokens: 20, OutputTokens: 10, PredictTime: 2 * time.Second}, usage.ByTenant["acme"])
+	assert.EqualValues(t, 1, usage.ByTenant["globex"].Predictions)
+
+	require.Len(t, metrics.records, 5)
+	tenants := make([]string, len(metrics.records))
+	for i, record := range metrics.records {
+		tenants[i] = record.Tenant
+	}
+	assert.Equal(t, []string{"acme", "acme", "globex", "", "initech"}, tenants)
+}
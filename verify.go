@@ -0,0 +1,95 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// VerifyFailure is the reason Verify failed.
+type VerifyFailure int
+
+const (
+	// VerifyUnexpected means the check failed for another reason, such as
+	// a server error.
+	VerifyUnexpected VerifyFailure = iota
+
+	// VerifyInvalidToken means the API rejected the token, or the token
+	// provider returned an empty token.
+	VerifyInvalidToken
+
+	// VerifyForbidden means the token is valid but lacks permission to
+	// read the account.
+	VerifyForbidden
+
+	// VerifyNetwork means the API couldn't be reached.
+	VerifyNetwork
+)
+
+func (f VerifyFailure) String() string {
+	switch f {
+	case VerifyInvalidToken:
+		return "invalid token"
+	case VerifyForbidden:
+		return "insufficient permissions"
+	case VerifyNetwork:
+		return "network failure"
+	default:
+		return "unexpected error"
+	}
+}
+
+// VerifyError is returned by Verify when the client's credentials couldn't
+// be confirmed.
+type VerifyError struct {
+	// Failure is the reason the check failed.
+	Failure VerifyFailure
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("failed to verify credentials: %s: %v", e.Failure, e.Err)
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// Verify checks that the client can reach the API and that its token is
+// accepted, by fetching the current account. Call it at startup to fail fast
+// on bad configuration, rather than on the first real request.
+//
+// Any failure is returned as a *VerifyError, whose Failure tells an invalid
+// token apart from missing permissions and an unreachable API. Cancellation
+// of ctx is returned as is.
+func (r *Client) Verify(ctx context.Context) error {
+	_, err := r.GetCurrentAccount(ctx)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrCanceled) {
+		return err
+	}
+	return &VerifyError{Failure: verifyFailure(err), Err: err}
+}
+
+func verifyFailure(err error) VerifyFailure {
+	var apiErr *APIError
+	var urlErr *url.Error
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrUnauthorized), errors.Is(err, ErrNoAuth):
+		return VerifyInvalidToken
+	case errors.Is(err, ErrForbidden):
+		return VerifyForbidden
+	case errors.As(err, &apiErr):
+		return VerifyUnexpected
+	case errors.As(err, &urlErr), errors.As(err, &netErr), errors.Is(err, ErrDeadlineExceeded):
+		return VerifyNetwork
+	}
+	return VerifyUnexpected
+}
@@ -0,0 +1,76 @@
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestVerify(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/account", r.URL.Path)
+		switch r.Header.Get("Authorization") {
+		case "Bearer valid":
+			json.NewEncoder(w).Encode(&replicate.Account{Type: "user", Username: "alice"})
+		case "Bearer limited":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"title": "Forbidden", "status": 403}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"title": "Unauthenticated", "status": 401}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	newClient := func(t *testing.T, token string, opts ...replicate.ClientOption) *replicate.Client {
+		opts = append([]replicate.ClientOption{
+			replicate.WithToken(token),
+			replicate.WithBaseURL(mockServer.URL),
+			replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+		}, opts...)
+		client, err := replicate.NewClient(opts...)
+		require.NoError(t, err)
+		return client
+	}
+
+	ctx := context.Background()
+	require.NoError(t, newClient(t, "valid").Verify(ctx))
+
+	tests := []struct {
+		name    string
+		client  *replicate.Client
+		failure replicate.VerifyFailure
+		is      error
+	}{
+		{"invalid token", newClient(t, "invalid"), replicate.VerifyInvalidToken, replicate.ErrUnauthorized},
+		{"forbidden", newClient(t, "limited"), replicate.VerifyForbidden, replicate.ErrForbidden},
+		{"network", newClient(t, "valid", replicate.WithBaseURL("http://127.0.0.1:1")), replicate.VerifyNetwork, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.client.Verify(ctx)
+			var verifyErr *replicate.VerifyError
+			require.ErrorAs(t, err, &verifyErr)
+			assert.Equal(t, tt.failure, verifyErr.Failure)
+			assert.Contains(t, err.Error(), tt.failure.String())
+			if tt.is != nil {
+				assert.ErrorIs(t, err, tt.is)
+			}
+		})
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	err := newClient(t, "valid").Verify(canceled)
+	assert.ErrorIs(t, err, replicate.ErrCanceled)
+	var verifyErr *replicate.VerifyError
+	assert.False(t, errors.As(err, &verifyErr))
+}
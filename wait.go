@@ -50,7 +50,7 @@ func (r *Client) Wait(ctx context.Context, prediction *Prediction, opts ...WaitO
 // an error is sent to the error channel.
 func (r *Client) WaitAsync(ctx context.Context, prediction *Prediction, opts ...WaitOption) (<-chan *Prediction, <-chan error) {
 	predChan := make(chan *Prediction)
-	errChan := make(chan error)
+	errChan := make(chan error, 1)
 
 	options := &waitOptions{
 		interval: defaultPollingInterval,
@@ -66,26 +66,43 @@ func (r *Client) WaitAsync(ctx context.Context, prediction *Prediction, opts ...
 		}
 	}
 
+	ctx, done := r.background(ctx)
 	go func() {
+		defer done()
 		defer close(predChan)
 		defer close(errChan)
 
-		ticker := time.NewTicker(options.interval)
+		start := time.Now()
+		defer func() {
+			r.metrics().ObserveWait(prediction.Status, time.Since(start))
+		}()
+
+		ticker := r.clock().NewTicker(options.interval)
 		defer ticker.Stop()
 
 		id := prediction.ID
 		attempts := 0
 		for {
 			select {
-			case <-ticker.C:
+			case <-ticker.C():
 				updatedPrediction, err := r.GetPrediction(ctx, id)
 				if err != nil {
+					if ctx.Err() != nil {
+						r.cancelOnClose(ctx, id)
+						err = contextError(ctx)
+					}
 					errChan <- err
 					return
 				}
 
 				*prediction = *updatedPrediction
-				predChan <- updatedPrediction
+				select {
+				case predChan <- updatedPrediction:
+				case <-ctx.Done():
+					r.cancelOnClose(ctx, id)
+					errChan <- contextError(ctx)
+					return
+				}
 
 				if prediction.Status.Terminated() {
 					errChan <- nil
@@ -94,7 +111,8 @@ func (r *Client) WaitAsync(ctx context.Context, prediction *Prediction, opts ...
 
 				attempts++
 			case <-ctx.Done():
-				errChan <- ctx.Err()
+				r.cancelOnClose(ctx, id)
+				errChan <- contextError(ctx)
 				return
 			}
 		}
@@ -7,12 +7,24 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
+var (
+	ErrInvalidWebhookEvent        = errors.New("invalid webhook event type")
+	ErrMissingWebhookURLSignature = errors.New("webhook URL is missing a signature")
+	ErrInvalidWebhookURLSignature = errors.New("webhook URL signature is invalid")
+)
+
+// webhookURLSignatureParam is the query parameter that holds the signature
+// added by SignWebhookURL.
+const webhookURLSignatureParam = "signature"
+
 type Webhook struct {
 	URL    string
 	Events []WebhookEventType
@@ -34,10 +46,75 @@ var WebhookEventAll = []WebhookEventType{
 	WebhookEventCompleted,
 }
 
+// AllWebhookEvents returns all webhook event types accepted by the API.
+func AllWebhookEvents() []WebhookEventType {
+	return append([]WebhookEventType(nil), WebhookEventAll...)
+}
+
 func (w WebhookEventType) String() string {
 	return string(w)
 }
 
+// IsValid reports whether w is one of the event types accepted by the API.
+func (w WebhookEventType) IsValid() bool {
+	for _, event := range WebhookEventAll {
+		if w == event {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the webhook's events filter, so that typos are reported
+// before a request is sent rather than as an API error.
+func (w *Webhook) validate() error {
+	for _, event := range w.Events {
+		if event.IsValid() {
+			continue
+		}
+
+		valid := make([]string, len(WebhookEventAll))
+		for i, e := range WebhookEventAll {
+			valid[i] = e.String()
+		}
+
+		hint := ""
+		lower := WebhookEventType(strings.ToLower(strings.TrimSpace(string(event))))
+		for _, e := range WebhookEventAll {
+			if lower != "" && (strings.HasPrefix(string(e), string(lower)) || strings.HasPrefix(string(lower), string(e))) {
+				hint = fmt.Sprintf(" (did you mean %q?)", e)
+				break
+			}
+		}
+
+		return fmt.Errorf("%w %q%s; valid events are %s", ErrInvalidWebhookEvent, event, hint, strings.Join(valid, ", "))
+	}
+
+	return nil
+}
+
+// setWebhook adds the webhook fields to a prediction or training request body,
+// falling back to the client's default webhook when webhook is nil.
+func (r *Client) setWebhook(data map[string]interface{}, webhook *Webhook) error {
+	if webhook == nil {
+		webhook = r.options.webhook
+	}
+	if webhook == nil {
+		return nil
+	}
+
+	if err := webhook.validate(); err != nil {
+		return err
+	}
+
+	data["webhook"] = webhook.URL
+	if len(webhook.Events) > 0 {
+		data["webhook_events_filter"] = webhook.Events
+	}
+
+	return nil
+}
+
 type WebhookSigningSecret struct {
 	Key string `json:"key"`
 
@@ -62,7 +139,7 @@ func (r *Client) GetDefaultWebhookSecret(ctx context.Context) (*WebhookSigningSe
 	secret := &WebhookSigningSecret{}
 	err := r.fetch(ctx, http.MethodGet, "/webhooks/default/secret", nil, secret)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get default webhook signing secret")
+		return nil, fmt.Errorf("failed to get default webhook signing secret: %w", err)
 	}
 
 	return secret, nil
@@ -119,3 +196,81 @@ func ValidateWebhookRequest(req *http.Request, secret WebhookSigningSecret) (boo
 
 	return false, nil
 }
+
+// SignWebhookURL adds params to the query string of rawURL, along with an
+// HMAC-SHA256 signature over the resulting query, computed with key.
+//
+// This lets a receiver correlate an incoming webhook with an internal job
+// (for example by job or tenant ID) without storing the prediction ID first.
+// Use VerifyWebhookURL on the receiving side to check the signature and
+// recover the params.
+func SignWebhookURL(rawURL string, params map[string]string, key []byte) (string, error) {
+	if len(key) == 0 {
+		return "", errors.New("webhook URL signing key is empty")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse webhook URL: %w", err)
+	}
+
+	query := u.Query()
+	if query.Has(webhookURLSignatureParam) {
+		return "", fmt.Errorf("webhook URL already has a %q parameter", webhookURLSignatureParam)
+	}
+	for k, v := range params {
+		if k == webhookURLSignatureParam {
+			return "", fmt.Errorf("webhook URL parameter %q is reserved", webhookURLSignatureParam)
+		}
+		query.Set(k, v)
+	}
+
+	query.Set(webhookURLSignatureParam, signWebhookQuery(query, key))
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// VerifyWebhookURL checks the signature added to u by SignWebhookURL and
+// returns the signed query parameters. It returns an error if key is empty,
+// since anyone can sign a URL with an empty key.
+func VerifyWebhookURL(u *url.URL, key []byte) (map[string]string, error) {
+	if len(key) == 0 {
+		return nil, errors.New("webhook URL signing key is empty")
+	}
+
+	query := u.Query()
+	signature := query.Get(webhookURLSignatureParam)
+	if signature == "" {
+		return nil, ErrMissingWebhookURLSignature
+	}
+	query.Del(webhookURLSignatureParam)
+
+	expected := signWebhookQuery(query, key)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrInvalidWebhookURLSignature
+	}
+
+	params := make(map[string]string, len(query))
+	for k := range query {
+		params[k] = query.Get(k)
+	}
+
+	return params, nil
+}
+
+// signWebhookQuery computes the signature for query, excluding any existing
+// signature parameter. Values.Encode sorts by key, so the result doesn't
+// depend on parameter order.
+func signWebhookQuery(query url.Values, key []byte) string {
+	signed := url.Values{}
+	for k, v := range query {
+		if k != webhookURLSignatureParam {
+			signed[k] = v
+		}
+	}
+
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(signed.Encode()))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
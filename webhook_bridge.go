@@ -0,0 +1,232 @@
+package replicate
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	webhookBridgeBufferSize       = 16
+	defaultWebhookBridgeRetention = 10 * time.Minute
+)
+
+// WebhookEvent is a verified webhook delivery for a prediction.
+type WebhookEvent struct {
+	Prediction *Prediction
+	ReceivedAt time.Time
+}
+
+// WebhookBridge is an http.Handler that verifies incoming webhooks and
+// publishes them to in-process waiters keyed by prediction ID.
+//
+// A single receiver endpoint can wake up any number of goroutines waiting on
+// predictions with Await. Completed events are retained for a while, so a
+// waiter that registers after the prediction finished still receives it.
+type WebhookBridge struct {
+	secret    WebhookSigningSecret
+	retention time.Duration
+	logger    *slog.Logger
+
+	mu        sync.Mutex
+	waiters   map[string][]chan WebhookEvent
+	completed map[string]WebhookEvent
+	closed    bool
+}
+
+// WebhookBridgeOption is a function that modifies a WebhookBridge.
+type WebhookBridgeOption func(*WebhookBridge)
+
+// WithWebhookBridgeRetention sets how long completed events are kept for
+// waiters that register late. The default is 10 minutes.
+func WithWebhookBridgeRetention(d time.Duration) WebhookBridgeOption {
+	return func(b *WebhookBridge) {
+		b.retention = d
+	}
+}
+
+// WithWebhookBridgeLogger sets a logger that receives a warning for each
+// webhook that fails verification or can't be decoded.
+func WithWebhookBridgeLogger(logger *slog.Logger) WebhookBridgeOption {
+	return func(b *WebhookBridge) {
+		b.logger = logger
+	}
+}
+
+// NewWebhookBridge creates a bridge that verifies webhooks with secret.
+func NewWebhookBridge(secret WebhookSigningSecret, opts ...WebhookBridgeOption) *WebhookBridge {
+	b := &WebhookBridge{
+		secret:    secret,
+		retention: defaultWebhookBridgeRetention,
+		waiters:   make(map[string][]chan WebhookEvent),
+		completed: make(map[string]WebhookEvent),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Await returns a channel that receives webhook events for the prediction.
+//
+// The channel is closed after the event for a terminal status is delivered,
+// or when the bridge is closed. If the consumer falls behind, older
+// non-terminal events are dropped in favor of newer ones.
+func (b *WebhookBridge) Await(predictionID string) <-chan WebhookEvent {
+	ch := make(chan WebhookEvent, webhookBridgeBufferSize)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch
+	}
+
+	b.expireLocked(time.Now())
+	if event, ok := b.completed[predictionID]; ok {
+		ch <- event
+		close(ch)
+		return ch
+	}
+
+	b.waiters[predictionID] = append(b.waiters[predictionID], ch)
+	return ch
+}
+
+// Forget unregisters a channel returned by Await and closes it.
+func (b *WebhookBridge) Forget(predictionID string, ch <-chan WebhookEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	waiters := b.waiters[predictionID]
+	for i, w := range waiters {
+		if w == ch {
+			close(w)
+			b.waiters[predictionID] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(b.waiters[predictionID]) == 0 {
+		delete(b.waiters, predictionID)
+	}
+}
+
+// Publish delivers an event to the waiters for its prediction.
+//
+// ServeHTTP calls Publish for each verified webhook. It can also be called
+// directly, for example when replaying events from an outbox table.
+func (b *WebhookBridge) Publish(event WebhookEvent) {
+	if event.Prediction == nil {
+		return
+	}
+	if event.ReceivedAt.IsZero() {
+		event.ReceivedAt = time.Now()
+	}
+
+	id := event.Prediction.ID
+	terminated := event.Prediction.Status.Terminated()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, ch := range b.waiters[id] {
+		select {
+		case ch <- event:
+		default:
+			// Drop the oldest event to make room; only this goroutine sends.
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- event
+		}
+		if terminated {
+			close(ch)
+		}
+	}
+
+	if terminated {
+		delete(b.waiters, id)
+		b.expireLocked(event.ReceivedAt)
+		if b.retention > 0 {
+			b.completed[id] = event
+		}
+	}
+}
+
+// ServeHTTP verifies the webhook signature, decodes the prediction, and
+// publishes it. Requests with an invalid signature are rejected with 401.
+func (b *WebhookBridge) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	valid, err := ValidateWebhookRequest(req, b.secret)
+	if err != nil || !valid {
+		attrs := []slog.Attr{slog.String("webhook_id", req.Header.Get("webhook-id"))}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", redactSecrets(err.Error())))
+		}
+		b.warn(req, "replicate webhook failed verification", attrs...)
+		http.Error(w, "invalid webhook signature", http.StatusUnauthorized)
+		return
+	}
+
+	prediction := &Prediction{}
+	if err := json.NewDecoder(req.Body).Decode(prediction); err != nil {
+		b.warn(req, "replicate webhook payload is invalid", slog.String("error", redactSecrets(err.Error())))
+		http.Error(w, "invalid webhook payload", http.StatusBadRequest)
+		return
+	}
+	if prediction.ID == "" {
+		http.Error(w, "webhook payload is missing a prediction ID", http.StatusBadRequest)
+		return
+	}
+
+	b.Publish(WebhookEvent{Prediction: prediction, ReceivedAt: time.Now()})
+	w.WriteHeader(http.StatusOK)
+}
+
+func (b *WebhookBridge) warn(req *http.Request, msg string, attrs ...slog.Attr) {
+	if b.logger == nil {
+		return
+	}
+	b.logger.LogAttrs(req.Context(), slog.LevelWarn, msg, attrs...)
+}
+
+// Close closes all waiting channels. Events published after Close are
+// discarded.
+func (b *WebhookBridge) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	for id, waiters := range b.waiters {
+		for _, ch := range waiters {
+			close(ch)
+		}
+		delete(b.waiters, id)
+	}
+
+	return nil
+}
+
+func (b *WebhookBridge) expireLocked(now time.Time) {
+	for id, event := range b.completed {
+		if now.Sub(event.ReceivedAt) > b.retention {
+			delete(b.completed, id)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package replicate_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/replicatetest"
+)
+
+// This is a test secret and should not be used in production
+var bridgeTestSecret = replicate.WebhookSigningSecret{
+	Key: "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw", // nolint:gosec
+}
+
+func newSignedWebhookRequest(t *testing.T, prediction *replicate.Prediction) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(prediction)
+	require.NoError(t, err)
+
+	req, err := replicatetest.NewWebhookRequest("http://test.host/webhook", bridgeTestSecret, body)
+	require.NoError(t, err)
+	return req
+}
+
+func TestWebhookBridge(t *testing.T) {
+	bridge := replicate.NewWebhookBridge(bridgeTestSecret)
+	defer bridge.Close()
+
+	first := bridge.Await("abc")
+	second := bridge.Await("abc")
+	other := bridge.Await("def")
+
+	for _, status := range []replicate.Status{replicate.Processing, replicate.Succeeded} {
+		rec := httptest.NewRecorder()
+		bridge.ServeHTTP(rec, newSignedWebhookRequest(t, &replicate.Prediction{ID: "abc", Status: status}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	for _, ch := range []<-chan replicate.WebhookEvent{first, second} {
+		var statuses []replicate.Status
+		for event := range ch {
+			statuses = append(statuses, event.Prediction.Status)
+		}
+		assert.Equal(t, []replicate.Status{replicate.Processing, replicate.Succeeded}, statuses)
+	}
+
+	select {
+	case <-other:
+		t.Fatal("unexpected event for other prediction")
+	default:
+	}
+
+	// Late waiters receive the retained completed event.
+	late := bridge.Await("abc")
+	event, ok := <-late
+	require.True(t, ok)
+	assert.Equal(t, replicate.Succeeded, event.Prediction.Status)
+	_, ok = <-late
+	assert.False(t, ok)
+}
+
+func TestWebhookBridgeRejectsInvalidSignature(t *testing.T) {
+	bridge := replicate.NewWebhookBridge(bridgeTestSecret)
+	defer bridge.Close()
+
+	ch := bridge.Await("abc")
+
+	req := newSignedWebhookRequest(t, &replicate.Prediction{ID: "abc", Status: replicate.Succeeded})
+	req.Header.Set("Webhook-Signature", "v1,"+base64.StdEncoding.EncodeToString([]byte("forged")))
+	rec := httptest.NewRecorder()
+	bridge.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	bridge.Forget("abc", ch)
+	_, ok := <-ch
+	assert.False(t, ok)
+}
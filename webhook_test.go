@@ -0,0 +1,181 @@
+package replicate_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestSignWebhookURL(t *testing.T) {
+	key := []byte("test-key")
+
+	signed, err := replicate.SignWebhookURL("https://example.com/webhook?source=replicate", map[string]string{
+		"job":    "job-123",
+		"tenant": "acme",
+	}, key)
+	require.NoError(t, err)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "job-123", u.Query().Get("job"))
+	assert.NotEmpty(t, u.Query().Get("signature"))
+
+	params, err := replicate.VerifyWebhookURL(u, key)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"job":    "job-123",
+		"tenant": "acme",
+		"source": "replicate",
+	}, params)
+
+	_, err = replicate.VerifyWebhookURL(u, []byte("other-key"))
+	assert.ErrorIs(t, err, replicate.ErrInvalidWebhookURLSignature)
+
+	query := u.Query()
+	query.Set("tenant", "evil")
+	u.RawQuery = query.Encode()
+	_, err = replicate.VerifyWebhookURL(u, key)
+	assert.ErrorIs(t, err, replicate.ErrInvalidWebhookURLSignature)
+
+	query.Del("signature")
+	u.RawQuery = query.Encode()
+	_, err = replicate.VerifyWebhookURL(u, key)
+	assert.ErrorIs(t, err, replicate.ErrMissingWebhookURLSignature)
+}
+
+func TestVerifyWebhookURLEmptyKey(t *testing.T) {
+	query := url.Values{"job": {"job-123"}}
+	h := hmac.New(sha256.New, nil)
+	h.Write([]byte(query.Encode()))
+	query.Set("signature", base64.RawURLEncoding.EncodeToString(h.Sum(nil)))
+	u := &url.URL{Scheme: "https", Host: "example.com", Path: "/webhook", RawQuery: query.Encode()}
+
+	_, err := replicate.VerifyWebhookURL(u, nil)
+	assert.Error(t, err)
+	_, err = replicate.VerifyWebhookURL(u, []byte{})
+	assert.Error(t, err)
+
+	_, err = replicate.SignWebhookURL("https://example.com/webhook", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestSignWebhookURLReservedParam(t *testing.T) {
+	_, err := replicate.SignWebhookURL("https://example.com/webhook", map[string]string{"signature": "x"}, []byte("key"))
+	assert.Error(t, err)
+}
+
+func TestDefaultWebhook(t *testing.T) {
+	var bodies []map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithDefaultWebhook(replicate.Webhook{
+			URL:    "https://example.com/default",
+			Events: []replicate.WebhookEventType{replicate.WebhookEventCompleted},
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	input := replicate.PredictionInput{"text": "Alice"}
+	_, err = client.CreatePredictionWithModel(ctx, "owner", "model", input, nil, false)
+	require.NoError(t, err)
+	_, err = client.CreatePredictionWithModel(ctx, "owner", "model", input, &replicate.Webhook{URL: "https://example.com/override"}, false)
+	require.NoError(t, err)
+	_, err = client.CreateTraining(ctx, "owner", "model", "version", "owner/destination", replicate.TrainingInput{}, nil)
+	require.NoError(t, err)
+
+	require.Len(t, bodies, 3)
+	assert.Equal(t, "https://example.com/default", bodies[0]["webhook"])
+	assert.Equal(t, []interface{}{"completed"}, bodies[0]["webhook_events_filter"])
+	assert.Equal(t, "https://example.com/override", bodies[1]["webhook"])
+	assert.NotContains(t, bodies[1], "webhook_events_filter")
+	assert.Equal(t, "https://example.com/default", bodies[2]["webhook"])
+}
+
+func TestDefaultWebhookRequiresURL(t *testing.T) {
+	_, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithDefaultWebhook(replicate.Webhook{}),
+	)
+	assert.Error(t, err)
+}
+
+func TestWebhookEventValidation(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(&replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	webhook := &replicate.Webhook{
+		URL:    "https://example.com/webhook",
+		Events: []replicate.WebhookEventType{"start", "complete"},
+	}
+	_, err = client.CreatePredictionWithModel(ctx, "owner", "model", replicate.PredictionInput{}, webhook, false)
+	assert.ErrorIs(t, err, replicate.ErrInvalidWebhookEvent)
+	assert.ErrorContains(t, err, `did you mean "completed"?`)
+	assert.Equal(t, 0, requests)
+
+	webhook.Events = replicate.AllWebhookEvents()
+	_, err = client.CreatePredictionWithModel(ctx, "owner", "model", replicate.PredictionInput{}, webhook, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	assert.True(t, replicate.WebhookEventLogs.IsValid())
+	assert.False(t, replicate.WebhookEventType("done").IsValid())
+}
+
+func TestGetDefaultWebhookSecretError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"detail": "Invalid token."}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetDefaultWebhookSecret(context.Background())
+	assert.ErrorIs(t, err, replicate.ErrUnauthorized)
+	var apiErr *replicate.APIError
+	assert.ErrorAs(t, err, &apiErr)
+}